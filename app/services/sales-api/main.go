@@ -5,6 +5,7 @@ import (
 	"errors"
 	"expvar"
 	"fmt"
+	"log/slog"
 	"net/http"
 	"os"
 	"os/signal"
@@ -14,23 +15,108 @@ import (
 
 	"github.com/1core-dev/go-service/app/services/sales-api/v1/handlers"
 	db "github.com/1core-dev/go-service/business/data/dbsql/pgx"
+	"github.com/1core-dev/go-service/business/data/page"
 	v1 "github.com/1core-dev/go-service/business/web/v1"
 	"github.com/1core-dev/go-service/business/web/v1/auth"
 	"github.com/1core-dev/go-service/business/web/v1/debug"
+	"github.com/1core-dev/go-service/business/web/v1/metrics"
+	"github.com/1core-dev/go-service/pkg/alert"
 	"github.com/1core-dev/go-service/pkg/keystore"
 	"github.com/1core-dev/go-service/pkg/logger"
+	"github.com/1core-dev/go-service/pkg/tracing"
 	"github.com/1core-dev/go-service/pkg/web"
 	"github.com/ardanlabs/conf/v3"
 )
 
 var build = "develop"
 
+// config represents the configuration of the service.
+type config struct {
+	conf.Version
+	Web struct {
+		ReadTimeout     time.Duration `conf:"default:5s"`
+		WriteTimeout    time.Duration `conf:"default:10s"`
+		IdleTimeout     time.Duration `conf:"default:120s"`
+		ShutdownTimeout time.Duration `conf:"default:20s"`
+		APIHost         string        `conf:"default:0.0.0.0:3000"`
+		DebugHost       string        `conf:"default:0.0.0.0:4000"`
+		CursorKey       string        `conf:"mask"`
+		CacheControl    string        `conf:"default:no-cache"`
+	}
+	Auth struct {
+		KeysFolder string `conf:"default:zarf/keys/"`
+		ActiveKID  string `conf:"default:32e0b6e7-1a49-4041-87bc-397c17bbfb16"`
+		Issuer     string `conf:"default:service project"`
+	}
+	DB struct {
+		User         string        `conf:"default:postgres"`
+		Password     string        `conf:"default:postgres,mask"`
+		Host         string        `conf:"default:database-service.sales-system.svc.cluster.local"`
+		Name         string        `conf:"default:postgres"`
+		MaxIdleConns int           `conf:"default:2"`
+		MaxOpenConns int           `conf:"default:0"`
+		DisableTLS   bool          `conf:"default:true"`
+		SlowQuery    time.Duration `conf:"default:200ms"`
+		QueryLog     string        `conf:"default:info"`
+		QuerySample  int           `conf:"default:1"`
+	}
+	Log struct {
+		Level            string `conf:"default:info"`
+		Format           string `conf:"default:json"`
+		Color            bool   `conf:"default:true"`
+		File             string
+		FileFormat       string `conf:"default:text"`
+		FileLevel        string
+		SampleInterval   time.Duration `conf:"default:1s"`
+		SampleFirst      int           `conf:"default:100"`
+		SampleThereafter int           `conf:"default:10"`
+		SampleBurst      int           `conf:"default:20"`
+	}
+	Alert struct {
+		WebhookURL string        `conf:"mask"`
+		SlackURL   string        `conf:"mask"`
+		QueueSize  int           `conf:"default:100"`
+		Window     time.Duration `conf:"default:1m"`
+		Retries    int           `conf:"default:3"`
+		Backoff    time.Duration `conf:"default:1s"`
+	}
+	Tracing struct {
+		Exporter    string  `conf:"default:none"`
+		Endpoint    string  `conf:"default:localhost:4318"`
+		Insecure    bool    `conf:"default:true"`
+		Probability float64 `conf:"default:0.05"`
+	}
+}
+
 func main() {
+	// Configuration
+	cfg := config{
+		Version: conf.Version{
+			Build: build,
+			Desc:  "1core",
+		},
+	}
+
+	const prefix = "SALES"
+	help, err := conf.Parse(prefix, &cfg)
+	if err != nil {
+		if errors.Is(err, conf.ErrHelpWanted) {
+			fmt.Println(help)
+			return
+		}
+		fmt.Fprintf(os.Stderr, "parsing config: %s\n", err)
+		os.Exit(1)
+	}
+
+	// Logger
 	var log *logger.Logger
+	var alerter *alert.Alerter
 
+	// The alerter is constructed with the logger below, so errors logged
+	// before then reach a nil alerter, which ignores them.
 	events := logger.Events{
 		Error: func(ctx context.Context, r logger.Record) {
-			log.Info(ctx, "******* SEND ALERT *******")
+			alerter.Send(ctx, r)
 		},
 	}
 
@@ -38,62 +124,129 @@ func main() {
 		return web.GetTraceID(ctx)
 	}
 
-	log = logger.NewWithEvents(os.Stdout, logger.LevelInfo, "SALES-API", traceIDFunc, events)
+	logCfg, closeLog, err := newLogConfig(cfg)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "configuring logger: %s\n", err)
+		os.Exit(1)
+	}
+	defer closeLog()
+
+	logCfg.ServiceName = "SALES-API"
+	logCfg.TraceIDFunc = traceIDFunc
+	logCfg.Events = events
+
+	log = logger.NewWithConfig(logCfg)
 
 	ctx := context.Background()
 
-	if err := run(ctx, log); err != nil {
+	// Alerting
+	alerter = alert.New(alert.Config{
+		Log:       log,
+		Service:   "SALES-API",
+		Notifiers: notifiers(cfg, log),
+		QueueSize: cfg.Alert.QueueSize,
+		Window:    cfg.Alert.Window,
+		Retries:   cfg.Alert.Retries,
+		Backoff:   cfg.Alert.Backoff,
+	})
+	defer func() {
+		ctx, cancel := context.WithTimeout(ctx, cfg.Web.ShutdownTimeout)
+		defer cancel()
+
+		if err := alerter.Shutdown(ctx); err != nil {
+			log.Warn(ctx, "shutdown", "status", "stopping alerting", "msg", err)
+		}
+	}()
+
+	if err := run(ctx, log, cfg); err != nil {
 		log.Error(ctx, "startup", "msg", err)
 		return
 	}
 }
 
-func run(ctx context.Context, log *logger.Logger) error {
-	// GOMAXPROCS
-	log.Info(ctx, "startup", "GOMAXPROCS", runtime.GOMAXPROCS(0), "build", build)
+// newLogConfig constructs the configuration of the logger, opening the file
+// to log to if one is configured. The returned function closes that file.
+func newLogConfig(cfg config) (logger.Config, func(), error) {
+	level, err := logger.ParseLevel(cfg.Log.Level)
+	if err != nil {
+		return logger.Config{}, nil, fmt.Errorf("parsing level: %w", err)
+	}
 
-	// Configuration
-	cfg := struct {
-		conf.Version
-		Web struct {
-			ReadTimeout     time.Duration `conf:"default:5s"`
-			WriteTimeout    time.Duration `conf:"default:10s"`
-			IdleTimeout     time.Duration `conf:"default:120s"`
-			ShutdownTimeout time.Duration `conf:"default:20s"`
-			APIHost         string        `conf:"default:0.0.0.0:3000"`
-			DebugHost       string        `conf:"default:0.0.0.0:4000"`
+	format, err := logger.ParseFormat(cfg.Log.Format)
+	if err != nil {
+		return logger.Config{}, nil, fmt.Errorf("parsing format: %w", err)
+	}
+
+	sinks := []logger.Sink{
+		{Writer: os.Stdout, Format: format, Color: cfg.Log.Color},
+	}
+
+	closeLog := func() {}
+
+	if cfg.Log.File != "" {
+		fileFormat, err := logger.ParseFormat(cfg.Log.FileFormat)
+		if err != nil {
+			return logger.Config{}, nil, fmt.Errorf("parsing file format: %w", err)
 		}
-		Auth struct {
-			KeysFolder string `conf:"default:zarf/keys/"`
-			ActiveKID  string `conf:"default:32e0b6e7-1a49-4041-87bc-397c17bbfb16"`
-			Issuer     string `conf:"default:service project"`
+
+		sink := logger.Sink{Format: fileFormat}
+
+		if cfg.Log.FileLevel != "" {
+			fileLevel, err := logger.ParseLevel(cfg.Log.FileLevel)
+			if err != nil {
+				return logger.Config{}, nil, fmt.Errorf("parsing file level: %w", err)
+			}
+			sink.Level = slog.Level(fileLevel)
 		}
-		DB struct {
-			User         string `conf:"default:postgres"`
-			Password     string `conf:"default:postgres,mask"`
-			Host         string `conf:"default:database-service.sales-system.svc.cluster.local"`
-			Name         string `conf:"default:postgres"`
-			MaxIdleConns int    `conf:"default:2"`
-			MaxOpenConns int    `conf:"default:0"`
-			DisableTLS   bool   `conf:"default:true"`
+
+		f, err := os.OpenFile(cfg.Log.File, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+		if err != nil {
+			return logger.Config{}, nil, fmt.Errorf("opening file: %w", err)
 		}
-	}{
-		Version: conf.Version{
-			Build: build,
-			Desc:  "1core",
+		sink.Writer = f
+		closeLog = func() { f.Close() }
+
+		sinks = append(sinks, sink)
+	}
+
+	logCfg := logger.Config{
+		Sinks:    sinks,
+		MinLevel: level,
+		Sampling: logger.Sampling{
+			Interval:   cfg.Log.SampleInterval,
+			First:      cfg.Log.SampleFirst,
+			Thereafter: cfg.Log.SampleThereafter,
+			Burst:      cfg.Log.SampleBurst,
 		},
 	}
 
-	const prefix = "SALES"
-	help, err := conf.Parse(prefix, &cfg)
-	if err != nil {
-		if errors.Is(err, conf.ErrHelpWanted) {
-			fmt.Println(help)
-			return nil
-		}
-		return fmt.Errorf("passing config: %w", err)
+	return logCfg, closeLog, nil
+}
+
+// notifiers constructs the notifiers the alerts are delivered to. Without a
+// configured destination, the alerts are logged.
+func notifiers(cfg config, log *logger.Logger) []alert.Notifier {
+	var ns []alert.Notifier
+
+	if cfg.Alert.WebhookURL != "" {
+		ns = append(ns, alert.Webhook{URL: cfg.Alert.WebhookURL})
+	}
+
+	if cfg.Alert.SlackURL != "" {
+		ns = append(ns, alert.Slack{URL: cfg.Alert.SlackURL})
+	}
+
+	if len(ns) == 0 {
+		ns = append(ns, alert.Log{Log: log})
 	}
 
+	return ns
+}
+
+func run(ctx context.Context, log *logger.Logger, cfg config) error {
+	// GOMAXPROCS
+	log.Info(ctx, "startup", "GOMAXPROCS", runtime.GOMAXPROCS(0), "build", build)
+
 	// App starting
 	log.Info(ctx, "starting service", "version", build)
 	defer log.Info(ctx, "shutdown complete")
@@ -109,23 +262,35 @@ func run(ctx context.Context, log *logger.Logger) error {
 	// Database Support
 	log.Info(ctx, "startup", "status", "initializing database support", "host", cfg.DB.Host)
 
-	db, err := db.Open(db.Config{
-		User:         cfg.DB.User,
-		Password:     cfg.DB.Password,
-		Host:         cfg.DB.Host,
-		Name:         cfg.DB.Name,
-		MaxIdleConns: cfg.DB.MaxIdleConns,
-		MaxOpenConns: cfg.DB.MaxOpenConns,
-		DisableTLS:   cfg.DB.DisableTLS,
+	queryLogLevel, err := logger.ParseLevel(cfg.DB.QueryLog)
+	if err != nil {
+		return fmt.Errorf("parsing query log level: %w", err)
+	}
+
+	dbConn, err := db.Open(db.Config{
+		User:               cfg.DB.User,
+		Password:           cfg.DB.Password,
+		Host:               cfg.DB.Host,
+		Name:               cfg.DB.Name,
+		MaxIdleConns:       cfg.DB.MaxIdleConns,
+		MaxOpenConns:       cfg.DB.MaxOpenConns,
+		DisableTLS:         cfg.DB.DisableTLS,
+		SlowQueryThreshold: cfg.DB.SlowQuery,
+		QueryLogLevel:      queryLogLevel,
+		QueryLogSampling:   cfg.DB.QuerySample,
 	})
 	if err != nil {
 		return fmt.Errorf("connecting to db: %w", err)
 	}
 	defer func() {
 		log.Info(ctx, "shutdown", "status", "stopping database support", "host", cfg.DB.Host)
-		db.Close()
+		dbConn.Close()
 	}()
 
+	if err := metrics.Register(db.Collectors(dbConn, cfg.DB.Name)...); err != nil {
+		return fmt.Errorf("registering db metrics: %w", err)
+	}
+
 	// Initialize authentication support
 	log.Info(ctx, "startup", "status", "initializing authentication support")
 
@@ -135,6 +300,18 @@ func run(ctx context.Context, log *logger.Logger) error {
 		return fmt.Errorf("reading keys: %w", err)
 	}
 
+	// The cursor key is derived from the active signing key when none is
+	// configured, so every instance signs cursors the same way.
+	cursorKey := []byte(cfg.Web.CursorKey)
+	if len(cursorKey) == 0 {
+		pk, err := ks.PrivateKey(cfg.Auth.ActiveKID)
+		if err != nil {
+			return fmt.Errorf("deriving cursor key: %w", err)
+		}
+
+		cursorKey = page.DeriveKey([]byte(pk))
+	}
+
 	authCfg := auth.Config{
 		Log:       log,
 		KeyLookup: ks,
@@ -145,11 +322,32 @@ func run(ctx context.Context, log *logger.Logger) error {
 		return fmt.Errorf("constructing auth: %w", err)
 	}
 
+	// Start Tracing Support
+	log.Info(ctx, "startup", "status", "initializing tracing support", "exporter", cfg.Tracing.Exporter)
+
+	traceProvider, err := tracing.New(tracing.Config{
+		ServiceName: "sales-api",
+		Exporter:    cfg.Tracing.Exporter,
+		Endpoint:    cfg.Tracing.Endpoint,
+		Insecure:    cfg.Tracing.Insecure,
+		Probability: cfg.Tracing.Probability,
+		IDGenerator: web.IDGenerator{},
+	})
+	if err != nil {
+		return fmt.Errorf("starting tracing: %w", err)
+	}
+	defer func() {
+		log.Info(ctx, "shutdown", "status", "stopping tracing support")
+		traceProvider.Shutdown(context.Background())
+	}()
+
+	tracer := traceProvider.Tracer("service")
+
 	// Start debug Service
 	go func() {
 		log.Info(ctx, "startup", "status", "debug v1 router started", "host", cfg.Web.DebugHost)
 
-		if err := http.ListenAndServe(cfg.Web.DebugHost, debug.Mux()); err != nil {
+		if err := http.ListenAndServe(cfg.Web.DebugHost, debug.Mux(log)); err != nil {
 			log.Error(ctx, "shutdown", "status", "debug v1 router closed", "host", cfg.Web.DebugHost, "msg", err)
 		}
 	}()
@@ -161,11 +359,14 @@ func run(ctx context.Context, log *logger.Logger) error {
 	signal.Notify(shutdown, syscall.SIGINT, syscall.SIGTERM)
 
 	cfgMux := v1.APIMuxConfig{
-		Build:    build,
-		Shutdown: shutdown,
-		Log:      log,
-		Auth:     auth,
-		DB:       db,
+		Build:        build,
+		Shutdown:     shutdown,
+		Log:          log,
+		Auth:         auth,
+		DB:           dbConn,
+		CursorKey:    cursorKey,
+		CacheControl: cfg.Web.CacheControl,
+		Tracer:       tracer,
 	}
 
 	apiMux := v1.APIMux(cfgMux, handlers.Routes{})
@@ -2,6 +2,7 @@ package checkgroup
 
 import (
 	"context"
+	"database/sql"
 	"net/http"
 	"os"
 	"time"
@@ -44,12 +45,14 @@ func (h *Handlers) Readiness(ctx context.Context, w http.ResponseWriter, r *http
 	}
 
 	data := struct {
-		Status string `json:"status"`
+		Status string     `json:"status"`
+		Pool   poolHealth `json:"pool"`
 	}{
 		Status: status,
+		Pool:   toPoolHealth(h.db.Stats()),
 	}
 
-	h.log.Info(ctx, "readiness", "status", status)
+	h.log.Info(ctx, "readiness", "status", status, "pool", data.Pool)
 
 	return web.Respond(ctx, w, data, statusCode)
 }
@@ -93,3 +96,32 @@ func (h *Handlers) Liveness(ctx context.Context, w http.ResponseWriter, r *http.
 
 	return web.Respond(ctx, w, data, http.StatusOK)
 }
+
+// poolHealth reports the state of the database connection pool so the pool
+// can be sized from data. The pool is saturated when every connection it
+// may open is in use and new queries have to wait.
+type poolHealth struct {
+	MaxOpenConnections int    `json:"maxOpenConnections"`
+	OpenConnections    int    `json:"openConnections"`
+	InUse              int    `json:"inUse"`
+	Idle               int    `json:"idle"`
+	WaitCount          int64  `json:"waitCount"`
+	WaitDuration       string `json:"waitDuration"`
+	MaxIdleClosed      int64  `json:"maxIdleClosed"`
+	MaxLifetimeClosed  int64  `json:"maxLifetimeClosed"`
+	Saturated          bool   `json:"saturated"`
+}
+
+func toPoolHealth(stats sql.DBStats) poolHealth {
+	return poolHealth{
+		MaxOpenConnections: stats.MaxOpenConnections,
+		OpenConnections:    stats.OpenConnections,
+		InUse:              stats.InUse,
+		Idle:               stats.Idle,
+		WaitCount:          stats.WaitCount,
+		WaitDuration:       stats.WaitDuration.String(),
+		MaxIdleClosed:      stats.MaxIdleClosed,
+		MaxLifetimeClosed:  stats.MaxLifetimeClosed,
+		Saturated:          stats.MaxOpenConnections > 0 && stats.InUse >= stats.MaxOpenConnections,
+	}
+}
@@ -23,9 +23,11 @@ func (Routes) Add(app *web.App, apiCfg v1.APIMuxConfig) {
 	})
 
 	usergroup.Routes(app, usergroup.Config{
-		Build: apiCfg.Build,
-		Log:   apiCfg.Log,
-		DB:    apiCfg.DB,
-		Auth:  apiCfg.Auth,
+		Build:        apiCfg.Build,
+		Log:          apiCfg.Log,
+		DB:           apiCfg.DB,
+		Auth:         apiCfg.Auth,
+		CursorKey:    apiCfg.CursorKey,
+		CacheControl: apiCfg.CacheControl,
 	})
 }
@@ -0,0 +1,55 @@
+package usergroup
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"slices"
+
+	"github.com/1core-dev/go-service/business/core/user"
+	"github.com/1core-dev/go-service/business/web/v1/auth"
+	"github.com/1core-dev/go-service/business/web/v1/response"
+	"github.com/google/uuid"
+)
+
+// Set of error variables for what only admins are allowed to do.
+var (
+	ErrAdminOnly        = errors.New("only admins can change the roles or enabled status of a user")
+	ErrHistoryAdminOnly = errors.New("only admins can expand the history of a user")
+)
+
+// isAdmin reports whether the authenticated user is an admin.
+func (h *Handlers) isAdmin(ctx context.Context) bool {
+	return h.auth.Authorize(ctx, auth.GetClaims(ctx), uuid.Nil, auth.RuleAdminOnly) == nil
+}
+
+// checkAdminChanges rejects an update changing the roles or enabled status
+// of the user unless it's made by an admin. The subject of the request is
+// otherwise allowed to update their own user.
+func (h *Handlers) checkAdminChanges(ctx context.Context, usr user.User, uu user.UpdateUser) error {
+	rolesChanged := uu.Roles != nil && !sameRoles(usr.Roles, uu.Roles)
+	enabledChanged := uu.Enabled != nil && *uu.Enabled != usr.Enabled
+
+	if (rolesChanged || enabledChanged) && !h.isAdmin(ctx) {
+		return response.NewError(ErrAdminOnly, http.StatusForbidden)
+	}
+
+	return nil
+}
+
+// sameRoles reports whether both lists hold the same set of roles.
+func sameRoles(a []user.Role, b []user.Role) bool {
+	for _, role := range a {
+		if !slices.Contains(b, role) {
+			return false
+		}
+	}
+
+	for _, role := range b {
+		if !slices.Contains(a, role) {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,290 @@
+package usergroup
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/1core-dev/go-service/business/core/user"
+	db "github.com/1core-dev/go-service/business/data/dbsql/pgx"
+	"github.com/1core-dev/go-service/business/data/transaction"
+	"github.com/1core-dev/go-service/business/web/v1/response"
+	"github.com/1core-dev/go-service/pkg/validate"
+	"github.com/1core-dev/go-service/pkg/web"
+	"github.com/google/uuid"
+)
+
+// Set of modes a bulk request can execute under.
+const (
+	BulkModeAtomic     = "atomic"
+	BulkModeBestEffort = "bestEffort"
+)
+
+// Set of operations a bulk request can perform.
+const (
+	BulkOpCreate  = "create"
+	BulkOpUpdate  = "update"
+	BulkOpEnable  = "enable"
+	BulkOpDisable = "disable"
+	BulkOpDelete  = "delete"
+)
+
+// errBulkFailed is used to roll back an atomic bulk request.
+var errBulkFailed = errors.New("bulk operation failed")
+
+// AppBulkRequest contains the set of operations to perform in bulk.
+type AppBulkRequest struct {
+	Mode       string             `json:"mode" validate:"required,oneof=atomic bestEffort"`
+	Operations []AppBulkOperation `json:"operations" validate:"required,min=1,max=1000"`
+}
+
+// Validate checks the data in the model is considered clean.
+func (app AppBulkRequest) Validate() error {
+	if err := validate.Check(app); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// AppBulkOperation represents a single operation within a bulk request. The
+// user document is decoded based on the operation.
+type AppBulkOperation struct {
+	Op      string          `json:"op"`
+	ID      string          `json:"id"`
+	Version *int            `json:"version"`
+	User    json.RawMessage `json:"user"`
+}
+
+// AppBulkResult represents the outcome of a single bulk operation.
+type AppBulkResult struct {
+	Index  int               `json:"index"`
+	Op     string            `json:"op"`
+	Status int               `json:"status"`
+	User   *AppUser          `json:"user,omitempty"`
+	Error  string            `json:"error,omitempty"`
+	Fields map[string]string `json:"fields,omitempty"`
+}
+
+// AppBulkResponse reports the outcome of every operation in a bulk request.
+// When an atomic request is not committed, no operation was applied.
+type AppBulkResponse struct {
+	Mode      string          `json:"mode"`
+	Committed bool            `json:"committed"`
+	Results   []AppBulkResult `json:"results"`
+}
+
+// =============================================================================
+
+// Bulk performs a set of user operations within a single transaction. In
+// atomic mode every operation is rolled back if any of them fails. In best
+// effort mode only the failing operations are rolled back.
+func (h *Handlers) Bulk(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	tx, ok := transaction.Get(ctx)
+	if !ok {
+		return errors.New("bulk: no transaction in context")
+	}
+
+	h, err := h.executeUnderTransaction(ctx)
+	if err != nil {
+		return err
+	}
+
+	var app AppBulkRequest
+	if err := web.Decode(r, &app); err != nil {
+		return response.NewError(err, http.StatusBadRequest)
+	}
+
+	resp := AppBulkResponse{
+		Mode:    app.Mode,
+		Results: make([]AppBulkResult, len(app.Operations)),
+	}
+
+	f := func() error {
+		var failed bool
+		for i, op := range app.Operations {
+			var res AppBulkResult
+
+			itemF := func() error {
+				var err error
+				if res, err = h.bulkOperation(ctx, op); err != nil {
+					return err
+				}
+				if res.Error != "" {
+					return errBulkFailed
+				}
+				return nil
+			}
+
+			if err := db.ExecuteUnderSavepoint(ctx, tx, "bulk_item", itemF); err != nil && !errors.Is(err, errBulkFailed) {
+				return err
+			}
+
+			res.Index = i
+			res.Op = op.Op
+			resp.Results[i] = res
+
+			if res.Error != "" {
+				failed = true
+			}
+		}
+
+		if failed && app.Mode == BulkModeAtomic {
+			return errBulkFailed
+		}
+
+		return nil
+	}
+
+	statusCode := http.StatusOK
+
+	switch err := db.ExecuteUnderSavepoint(ctx, tx, "bulk", f); {
+	case errors.Is(err, errBulkFailed):
+		statusCode = http.StatusUnprocessableEntity
+	case err != nil:
+		return fmt.Errorf("bulk: %w", err)
+	default:
+		resp.Committed = true
+	}
+
+	return web.Respond(ctx, w, resp, statusCode)
+}
+
+// bulkOperation performs a single operation and reports the outcome. Only
+// unexpected errors are returned, they abort the entire request.
+func (h *Handlers) bulkOperation(ctx context.Context, op AppBulkOperation) (AppBulkResult, error) {
+	switch op.Op {
+	case BulkOpCreate, BulkOpUpdate, BulkOpEnable, BulkOpDisable, BulkOpDelete:
+	default:
+		return bulkError(response.NewError(validate.NewFieldsError("op", fmt.Errorf("unknown operation %q", op.Op)), http.StatusBadRequest))
+	}
+
+	if op.Op == BulkOpCreate {
+		var app AppNewUser
+		if err := decodeBulkUser(op.User, &app); err != nil {
+			return bulkError(response.NewError(err, http.StatusBadRequest))
+		}
+
+		nu, err := toCoreNewUser(app)
+		if err != nil {
+			return bulkError(response.NewError(err, http.StatusBadRequest))
+		}
+
+		usr, err := h.user.Create(ctx, nu)
+		if err != nil {
+			return bulkError(err)
+		}
+
+		return bulkSuccess(usr, http.StatusCreated), nil
+	}
+
+	id, err := uuid.Parse(op.ID)
+	if err != nil {
+		return bulkError(response.NewError(validate.NewFieldsError("id", err), http.StatusBadRequest))
+	}
+
+	usr, err := h.user.QueryByID(ctx, id)
+	if err != nil {
+		return bulkError(err)
+	}
+
+	if op.Version != nil && *op.Version != usr.Version {
+		return bulkError(user.ErrVersionConflict)
+	}
+
+	var uu user.UpdateUser
+
+	switch op.Op {
+	case BulkOpUpdate:
+		var app AppUpdateUser
+		if err := decodeBulkUser(op.User, &app); err != nil {
+			return bulkError(response.NewError(err, http.StatusBadRequest))
+		}
+
+		uu, err = toCoreUpdateUser(app)
+		if err != nil {
+			return bulkError(response.NewError(err, http.StatusBadRequest))
+		}
+
+	case BulkOpEnable, BulkOpDisable:
+		enabled := op.Op == BulkOpEnable
+		uu.Enabled = &enabled
+
+	case BulkOpDelete:
+		if err := h.user.Delete(ctx, usr); err != nil {
+			return bulkError(err)
+		}
+
+		return AppBulkResult{Status: http.StatusNoContent}, nil
+	}
+
+	usr, err = h.user.Update(ctx, usr, uu)
+	if err != nil {
+		return bulkError(err)
+	}
+
+	return bulkSuccess(usr, http.StatusOK), nil
+}
+
+// decodeBulkUser decodes the user document of an operation and validates it.
+func decodeBulkUser(data json.RawMessage, val interface{ Validate() error }) error {
+	if len(data) == 0 {
+		return validate.NewFieldsError("user", errors.New("user is required"))
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(val); err != nil {
+		return fmt.Errorf("unable to decode user: %w", err)
+	}
+
+	if err := val.Validate(); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func bulkSuccess(usr user.User, status int) AppBulkResult {
+	app := toAppUser(usr)
+
+	return AppBulkResult{
+		Status: status,
+		User:   &app,
+	}
+}
+
+// bulkError converts the error from an operation into a result the same way
+// the Errors middleware would respond to a single request. Errors that are
+// not expected are returned as is.
+func bulkError(err error) (AppBulkResult, error) {
+	switch {
+	case response.IsError(err):
+		reqErr := response.GetError(err)
+
+		if validate.IsFieldErrors(reqErr.Err) {
+			res := AppBulkResult{
+				Status: reqErr.Status,
+				Error:  "data validation error",
+				Fields: validate.GetFieldErrors(reqErr.Err).Fields(),
+			}
+			return res, nil
+		}
+
+		return AppBulkResult{Status: reqErr.Status, Error: reqErr.Error()}, nil
+
+	case errors.Is(err, user.ErrNotFound):
+		return AppBulkResult{Status: http.StatusNotFound, Error: user.ErrNotFound.Error()}, nil
+
+	case errors.Is(err, user.ErrUniqueEmail):
+		return AppBulkResult{Status: http.StatusConflict, Error: user.ErrUniqueEmail.Error()}, nil
+
+	case errors.Is(err, user.ErrVersionConflict):
+		return AppBulkResult{Status: http.StatusPreconditionFailed, Error: ErrPreconditionFailed.Error()}, nil
+	}
+
+	return AppBulkResult{}, err
+}
@@ -0,0 +1,74 @@
+package usergroup
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/1core-dev/go-service/business/core/user"
+	"github.com/1core-dev/go-service/business/web/v1/response"
+)
+
+// Set of error variables for handling conditional requests.
+var (
+	ErrPreconditionRequired = errors.New("If-Match header is required")
+	ErrPreconditionFailed   = errors.New("user version does not match If-Match header")
+	ErrWeakETag             = errors.New("If-Match header can't use weak entity tags")
+)
+
+// etag returns the entity tag representing the version of the user.
+func etag(usr user.User) string {
+	return fmt.Sprintf(`"%d"`, usr.Version)
+}
+
+// ifMatch represents the entity tags listed in the If-Match header. When
+// any is set the header was *, which matches whatever version is current.
+type ifMatch struct {
+	any      bool
+	versions []int
+}
+
+// matches reports whether the current version of the user is one of the
+// versions the request was made against.
+func (im ifMatch) matches(usr user.User) bool {
+	return im.any || slices.Contains(im.versions, usr.Version)
+}
+
+// parseIfMatch returns the user versions from the If-Match header. A missing
+// header results in a 428 and a malformed one in a 412. Weak entity tags
+// are rejected with a 412 since If-Match requires the strong comparison.
+func parseIfMatch(r *http.Request) (ifMatch, error) {
+	v := strings.TrimSpace(r.Header.Get("If-Match"))
+	if v == "" {
+		return ifMatch{}, response.NewError(ErrPreconditionRequired, http.StatusPreconditionRequired)
+	}
+
+	if v == "*" {
+		return ifMatch{any: true}, nil
+	}
+
+	var im ifMatch
+	for _, tag := range strings.Split(v, ",") {
+		tag = strings.TrimSpace(tag)
+
+		if strings.HasPrefix(tag, "W/") {
+			return ifMatch{}, response.NewError(ErrWeakETag, http.StatusPreconditionFailed)
+		}
+
+		if len(tag) < 2 || tag[0] != '"' || tag[len(tag)-1] != '"' {
+			return ifMatch{}, response.NewError(ErrPreconditionFailed, http.StatusPreconditionFailed)
+		}
+
+		version, err := strconv.Atoi(tag[1 : len(tag)-1])
+		if err != nil {
+			return ifMatch{}, response.NewError(ErrPreconditionFailed, http.StatusPreconditionFailed)
+		}
+
+		im.versions = append(im.versions, version)
+	}
+
+	return im, nil
+}
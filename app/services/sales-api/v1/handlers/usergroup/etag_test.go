@@ -0,0 +1,61 @@
+package usergroup
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/1core-dev/go-service/business/core/user"
+	"github.com/1core-dev/go-service/business/web/v1/response"
+)
+
+func Test_IfMatch(t *testing.T) {
+	usr := user.User{Version: 3}
+
+	tests := []struct {
+		name    string
+		header  string
+		matches bool
+		status  int
+		err     error
+	}{
+		{name: "current", header: `"3"`, matches: true},
+		{name: "stale", header: `"2"`, matches: false},
+		{name: "list", header: `"1", "3"`, matches: true},
+		{name: "any", header: `*`, matches: true},
+		{name: "anySpaces", header: ` * `, matches: true},
+		{name: "missing", header: "", status: http.StatusPreconditionRequired, err: ErrPreconditionRequired},
+		{name: "weak", header: `W/"3"`, status: http.StatusPreconditionFailed, err: ErrWeakETag},
+		{name: "weakInList", header: `"3", W/"3"`, status: http.StatusPreconditionFailed, err: ErrWeakETag},
+		{name: "unquoted", header: `3`, status: http.StatusPreconditionFailed, err: ErrPreconditionFailed},
+		{name: "notNumber", header: `"abc"`, status: http.StatusPreconditionFailed, err: ErrPreconditionFailed},
+		{name: "anyInList", header: `"3", *`, status: http.StatusPreconditionFailed, err: ErrPreconditionFailed},
+	}
+
+	for _, tt := range tests {
+		r := httptest.NewRequest(http.MethodPut, "/v1/users/1", nil)
+		if tt.header != "" {
+			r.Header.Set("If-Match", tt.header)
+		}
+
+		im, err := parseIfMatch(r)
+
+		if tt.err != nil {
+			var re *response.Error
+			if !errors.As(err, &re) || re.Status != tt.status || re.Err != tt.err {
+				t.Errorf("%s: Should get the expected error : got %v, exp %d %v.", tt.name, err, tt.status, tt.err)
+			}
+			continue
+		}
+
+		if err != nil {
+			t.Errorf("%s: Should be able to parse the header : %s.", tt.name, err)
+			continue
+		}
+
+		if got := im.matches(usr); got != tt.matches {
+			t.Errorf("%s: Should get the expected match : got %t, exp %t.", tt.name, got, tt.matches)
+		}
+	}
+}
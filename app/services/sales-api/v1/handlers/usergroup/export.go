@@ -0,0 +1,303 @@
+package usergroup
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/1core-dev/go-service/business/core/user"
+	"github.com/1core-dev/go-service/business/web/v1/response"
+	"github.com/1core-dev/go-service/pkg/validate"
+	"github.com/1core-dev/go-service/pkg/web"
+	"github.com/1core-dev/go-service/pkg/xlsx"
+)
+
+// Set of export tuning values. Rows are flushed to the client in batches
+// and every flush extends the write deadline of the connection so large
+// exports are not cut off by the server write timeout.
+const (
+	exportFlushRows    = 500
+	exportWriteTimeout = 30 * time.Second
+)
+
+// ErrNotAcceptable is returned when none of the accepted media types can
+// be produced by the export.
+var ErrNotAcceptable = errors.New("none of the accepted media types are supported, use csv, ndjson or xlsx")
+
+// exportFormat describes a supported export file format.
+type exportFormat struct {
+	name        string
+	contentType string
+}
+
+var exportFormats = []exportFormat{
+	{name: "csv", contentType: "text/csv"},
+	{name: "ndjson", contentType: "application/x-ndjson"},
+	{name: "xlsx", contentType: xlsx.ContentType},
+}
+
+var exportHeader = []string{"id", "name", "email", "roles", "department", "enabled", "dateCreated", "dateUpdated"}
+
+// Export streams every user matching the filter as a csv, ndjson or xlsx
+// file. The format is selected by the format query parameter or the
+// Accept header and defaults to csv.
+func (h *Handlers) Export(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	format, err := parseExportFormat(r)
+	if err != nil {
+		return err
+	}
+
+	filter, err := parseFilter(r)
+	if err != nil {
+		return err
+	}
+
+	orderBy, err := parseOrder(r)
+	if err != nil {
+		return err
+	}
+
+	rc := http.NewResponseController(w)
+
+	// The response is started with the first row so errors found before
+	// any data is sent can still be reported as an error document. After
+	// that, errors abort the connection so the client can't mistake the
+	// partial file for a complete one.
+	var ew exportWriter
+	var started bool
+	start := func() error {
+		started = true
+
+		w.Header().Set("Content-Type", format.contentType)
+		w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="users.%s"`, format.name))
+
+		web.SetStatusCode(ctx, http.StatusOK)
+		w.WriteHeader(http.StatusOK)
+
+		var err error
+		ew, err = newExportWriter(format, w)
+		return err
+	}
+
+	var rows int
+	f := func(usr user.User) error {
+		if ew == nil {
+			if err := start(); err != nil {
+				return err
+			}
+		}
+
+		if err := ew.Write(toAppUser(usr)); err != nil {
+			return err
+		}
+
+		rows++
+		if rows%exportFlushRows == 0 {
+			return flushExport(ew, rc)
+		}
+
+		return nil
+	}
+
+	if err := h.user.Export(ctx, filter, orderBy, f); err != nil {
+		err = fmt.Errorf("export: rows[%d]: %w", rows, err)
+		if started {
+			return web.NewAbortError(err)
+		}
+		return err
+	}
+
+	if ew == nil {
+		if err := start(); err != nil {
+			return web.NewAbortError(fmt.Errorf("export: %w", err))
+		}
+	}
+
+	if err := ew.Close(); err != nil {
+		return web.NewAbortError(fmt.Errorf("export: close: %w", err))
+	}
+
+	return nil
+}
+
+// parseExportFormat selects the export format from the format query
+// parameter, falling back to the Accept header.
+func parseExportFormat(r *http.Request) (exportFormat, error) {
+	if name := r.URL.Query().Get("format"); name != "" {
+		for _, format := range exportFormats {
+			if strings.EqualFold(format.name, name) {
+				return format, nil
+			}
+		}
+
+		return exportFormat{}, response.NewError(validate.NewFieldsError("format", fmt.Errorf("unknown format %q", name)), http.StatusBadRequest)
+	}
+
+	accept := r.Header.Get("Accept")
+	if accept == "" {
+		return exportFormats[0], nil
+	}
+
+	for _, value := range strings.Split(accept, ",") {
+		mediaType, _, err := mime.ParseMediaType(strings.TrimSpace(value))
+		if err != nil {
+			continue
+		}
+
+		if mediaType == "*/*" || mediaType == "text/*" {
+			return exportFormats[0], nil
+		}
+
+		for _, format := range exportFormats {
+			if mediaType == format.contentType {
+				return format, nil
+			}
+		}
+	}
+
+	return exportFormat{}, response.NewError(ErrNotAcceptable, http.StatusNotAcceptable)
+}
+
+func flushExport(ew exportWriter, rc *http.ResponseController) error {
+	if err := ew.Flush(); err != nil {
+		return err
+	}
+
+	if err := rc.Flush(); err != nil && !errors.Is(err, http.ErrNotSupported) {
+		return err
+	}
+
+	if err := rc.SetWriteDeadline(time.Now().Add(exportWriteTimeout)); err != nil && !errors.Is(err, http.ErrNotSupported) {
+		return err
+	}
+
+	return nil
+}
+
+// =============================================================================
+
+// exportWriter writes users to the response in a specific file format.
+type exportWriter interface {
+	Write(usr AppUser) error
+	Flush() error
+	Close() error
+}
+
+func newExportWriter(format exportFormat, w io.Writer) (exportWriter, error) {
+	switch format.name {
+	case "ndjson":
+		return &ndjsonExport{enc: json.NewEncoder(w)}, nil
+
+	case "xlsx":
+		xw, err := xlsx.NewWriter(w, "Users")
+		if err != nil {
+			return nil, err
+		}
+
+		if err := xw.Write(exportHeader); err != nil {
+			return nil, err
+		}
+
+		return &xlsxExport{xw: xw}, nil
+
+	default:
+		cw := csv.NewWriter(w)
+		if err := cw.Write(exportHeader); err != nil {
+			return nil, err
+		}
+
+		return &csvExport{cw: cw}, nil
+	}
+}
+
+// formulaPrefixes are the first characters of the values that spreadsheet
+// applications evaluate as a formula when opening a csv file.
+var formulaPrefixes = "=+-@\t\r"
+
+// escapeFormula prevents the value from being evaluated as a formula.
+func escapeFormula(value string) string {
+	if value != "" && strings.ContainsRune(formulaPrefixes, rune(value[0])) {
+		return "'" + value
+	}
+
+	return value
+}
+
+// exportRecord converts the user into the columns of a tabular export.
+func exportRecord(usr AppUser) []string {
+	return []string{
+		usr.ID,
+		usr.Name,
+		usr.Email,
+		strings.Join(usr.Roles, ";"),
+		usr.Department,
+		strconv.FormatBool(usr.Enabled),
+		usr.DateCreated,
+		usr.DateUpdated,
+	}
+}
+
+type csvExport struct {
+	cw *csv.Writer
+}
+
+// Write escapes the values that could be evaluated as a formula, since the
+// cells of a csv file aren't typed. The cells of an xlsx file are always
+// text so they don't need it.
+func (e *csvExport) Write(usr AppUser) error {
+	record := exportRecord(usr)
+	for i, value := range record {
+		record[i] = escapeFormula(value)
+	}
+
+	return e.cw.Write(record)
+}
+
+func (e *csvExport) Flush() error {
+	e.cw.Flush()
+	return e.cw.Error()
+}
+
+func (e *csvExport) Close() error {
+	return e.Flush()
+}
+
+type ndjsonExport struct {
+	enc *json.Encoder
+}
+
+func (e *ndjsonExport) Write(usr AppUser) error {
+	return e.enc.Encode(usr)
+}
+
+func (e *ndjsonExport) Flush() error {
+	return nil
+}
+
+func (e *ndjsonExport) Close() error {
+	return nil
+}
+
+type xlsxExport struct {
+	xw *xlsx.Writer
+}
+
+func (e *xlsxExport) Write(usr AppUser) error {
+	return e.xw.Write(exportRecord(usr))
+}
+
+func (e *xlsxExport) Flush() error {
+	return e.xw.Flush()
+}
+
+func (e *xlsxExport) Close() error {
+	return e.xw.Close()
+}
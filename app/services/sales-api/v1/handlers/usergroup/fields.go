@@ -0,0 +1,103 @@
+package usergroup
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/1core-dev/go-service/business/core/user"
+	"github.com/1core-dev/go-service/business/web/v1/response"
+	"github.com/1core-dev/go-service/pkg/validate"
+)
+
+// Set of related resources that can be expanded into a user.
+const (
+	expandHistory = "history"
+)
+
+// expandHistoryRows is the number of the most recent changes included when
+// the history is expanded.
+const expandHistoryRows = 10
+
+// parseFields returns the fields of AppUser requested with the fields query
+// string, along with the matching core fields to load. No fields are
+// returned when the query string isn't provided.
+func parseFields(r *http.Request) ([]string, []string, error) {
+	var userFields = map[string]string{
+		"id":          user.FieldID,
+		"name":        user.FieldName,
+		"email":       user.FieldEmail,
+		"roles":       user.FieldRoles,
+		"department":  user.FieldDepartment,
+		"enabled":     user.FieldEnabled,
+		"dateCreated": user.FieldDateCreated,
+		"dateUpdated": user.FieldDateUpdated,
+	}
+
+	names, err := parseList(r, "fields", userFields)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	fields := make([]string, len(names))
+	for i, name := range names {
+		fields[i] = userFields[name]
+	}
+
+	return names, fields, nil
+}
+
+// parseExpand returns the related resources requested with the expand query
+// string that are in the allowed set.
+func parseExpand(r *http.Request, allowed ...string) ([]string, error) {
+	resources := make(map[string]string, len(allowed))
+	for _, name := range allowed {
+		resources[name] = name
+	}
+
+	return parseList(r, "expand", resources)
+}
+
+// parseList parses a comma separated query string, rejecting names that are
+// not known and names that are repeated.
+func parseList(r *http.Request, key string, known map[string]string) ([]string, error) {
+	v := r.URL.Query().Get(key)
+	if v == "" {
+		return nil, nil
+	}
+
+	var names []string
+	seen := make(map[string]bool)
+
+	for _, name := range strings.Split(v, ",") {
+		name = strings.TrimSpace(name)
+
+		if _, exists := known[name]; !exists {
+			return nil, response.NewError(validate.NewFieldsError(key, fmt.Errorf("unknown %s %q", key, name)), http.StatusBadRequest)
+		}
+
+		if seen[name] {
+			return nil, response.NewError(validate.NewFieldsError(key, fmt.Errorf("duplicate %s %q", key, name)), http.StatusBadRequest)
+		}
+		seen[name] = true
+
+		names = append(names, name)
+	}
+
+	return names, nil
+}
+
+// projectUsers reduces the users to the requested fields.
+func projectUsers(users []AppUser, fields []string) ([]map[string]json.RawMessage, error) {
+	items := make([]map[string]json.RawMessage, len(users))
+	for i, usr := range users {
+		m, err := response.Fields(usr, fields)
+		if err != nil {
+			return nil, err
+		}
+		items[i] = m
+	}
+
+	return items, nil
+}
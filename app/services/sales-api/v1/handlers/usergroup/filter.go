@@ -3,9 +3,14 @@ package usergroup
 import (
 	"net/http"
 	"net/mail"
+	"net/url"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/1core-dev/go-service/business/core/user"
+	"github.com/1core-dev/go-service/business/data/filter"
+	"github.com/1core-dev/go-service/business/web/v1/response"
 	"github.com/1core-dev/go-service/pkg/validate"
 	"github.com/google/uuid"
 )
@@ -16,7 +21,13 @@ func parseFilter(r *http.Request) (user.QueryFilter, error) {
 		filterByEmail            = "email"
 		filterByStartCreatedDate = "start_created_date"
 		filterByEndCreatedDate   = "end_created_date"
+		filterByStartUpdatedDate = "start_updated_date"
+		filterByEndUpdatedDate   = "end_updated_date"
 		filterByName             = "name"
+		filterByRoles            = "roles"
+		filterByDepartment       = "department"
+		filterByEnabled          = "enabled"
+		filterByExpression       = "filter"
 	)
 
 	values := r.URL.Query()
@@ -26,7 +37,7 @@ func parseFilter(r *http.Request) (user.QueryFilter, error) {
 	if userID := values.Get(filterByUserID); userID != "" {
 		id, err := uuid.Parse(userID)
 		if err != nil {
-			return user.QueryFilter{}, validate.NewFieldsError(filterByUserID, err)
+			return user.QueryFilter{}, response.NewError(validate.NewFieldsError(filterByUserID, err), http.StatusBadRequest)
 		}
 		filter.WithUserID(id)
 	}
@@ -34,7 +45,7 @@ func parseFilter(r *http.Request) (user.QueryFilter, error) {
 	if email := values.Get(filterByEmail); email != "" {
 		addr, err := mail.ParseAddress(email)
 		if err != nil {
-			return user.QueryFilter{}, validate.NewFieldsError(filterByEmail, err)
+			return user.QueryFilter{}, response.NewError(validate.NewFieldsError(filterByEmail, err), http.StatusBadRequest)
 		}
 		filter.WithEmail(*addr)
 	}
@@ -42,7 +53,7 @@ func parseFilter(r *http.Request) (user.QueryFilter, error) {
 	if createdDate := values.Get(filterByStartCreatedDate); createdDate != "" {
 		t, err := time.Parse(time.RFC3339, createdDate)
 		if err != nil {
-			return user.QueryFilter{}, validate.NewFieldsError(filterByStartCreatedDate, err)
+			return user.QueryFilter{}, response.NewError(validate.NewFieldsError(filterByStartCreatedDate, err), http.StatusBadRequest)
 		}
 		filter.WithStartDateCreated(t)
 	}
@@ -50,18 +61,85 @@ func parseFilter(r *http.Request) (user.QueryFilter, error) {
 	if createdDate := values.Get(filterByEndCreatedDate); createdDate != "" {
 		t, err := time.Parse(time.RFC3339, createdDate)
 		if err != nil {
-			return user.QueryFilter{}, validate.NewFieldsError(filterByEndCreatedDate, err)
+			return user.QueryFilter{}, response.NewError(validate.NewFieldsError(filterByEndCreatedDate, err), http.StatusBadRequest)
 		}
 		filter.WithEndCreatedDate(t)
 	}
 
+	if updatedDate := values.Get(filterByStartUpdatedDate); updatedDate != "" {
+		t, err := time.Parse(time.RFC3339, updatedDate)
+		if err != nil {
+			return user.QueryFilter{}, response.NewError(validate.NewFieldsError(filterByStartUpdatedDate, err), http.StatusBadRequest)
+		}
+		filter.WithStartUpdatedDate(t)
+	}
+
+	if updatedDate := values.Get(filterByEndUpdatedDate); updatedDate != "" {
+		t, err := time.Parse(time.RFC3339, updatedDate)
+		if err != nil {
+			return user.QueryFilter{}, response.NewError(validate.NewFieldsError(filterByEndUpdatedDate, err), http.StatusBadRequest)
+		}
+		filter.WithEndUpdatedDate(t)
+	}
+
+	if roleNames := listValues(values, filterByRoles); len(roleNames) > 0 {
+		roles := make([]user.Role, len(roleNames))
+		for i, name := range roleNames {
+			role, err := user.ParseRole(name)
+			if err != nil {
+				return user.QueryFilter{}, response.NewError(validate.NewFieldsError(filterByRoles, err), http.StatusBadRequest)
+			}
+			roles[i] = role
+		}
+		filter.WithRoles(roles)
+	}
+
+	if departments := listValues(values, filterByDepartment); len(departments) > 0 {
+		filter.WithDepartments(departments)
+	}
+
+	if enabled := values.Get(filterByEnabled); enabled != "" {
+		b, err := strconv.ParseBool(enabled)
+		if err != nil {
+			return user.QueryFilter{}, response.NewError(validate.NewFieldsError(filterByEnabled, err), http.StatusBadRequest)
+		}
+		filter.WithEnabled(b)
+	}
+
 	if name := values.Get(filterByName); name != "" {
 		filter.WithName(name)
 	}
 
+	if expression := values.Get(filterByExpression); expression != "" {
+		expr, err := parseExpression(expression)
+		if err != nil {
+			return user.QueryFilter{}, response.NewError(validate.NewFieldsError(filterByExpression, err), http.StatusBadRequest)
+		}
+		filter.WithExpression(expr)
+	}
+
 	if err := filter.Validate(); err != nil {
-		return user.QueryFilter{}, err
+		return user.QueryFilter{}, response.NewError(err, http.StatusBadRequest)
 	}
 
 	return filter, nil
 }
+
+func parseExpression(expression string) (filter.Expr, error) {
+	return filter.Parse(expression, user.FilterSchema)
+}
+
+// listValues returns the values of a query string that can be repeated or
+// provided as a comma separated list.
+func listValues(values url.Values, key string) []string {
+	var list []string
+	for _, value := range values[key] {
+		for _, v := range strings.Split(value, ",") {
+			if v = strings.TrimSpace(v); v != "" {
+				list = append(list, v)
+			}
+		}
+	}
+
+	return list
+}
@@ -2,7 +2,9 @@ package usergroup
 
 import (
 	"fmt"
+	"html"
 	"net/mail"
+	"strings"
 	"time"
 
 	"github.com/1core-dev/go-service/business/core/user"
@@ -52,6 +54,40 @@ func toAppUsers(users []user.User) []AppUser {
 
 // =============================================================================
 
+// AppHistory represents a single recorded change made to a user.
+type AppHistory struct {
+	ID          string                 `json:"id"`
+	UserID      string                 `json:"userID"`
+	Action      string                 `json:"action"`
+	Diff        map[string]user.Change `json:"diff"`
+	Actor       string                 `json:"actor"`
+	TraceID     string                 `json:"traceID"`
+	DateCreated string                 `json:"dateCreated"`
+}
+
+func toAppHistory(h user.History) AppHistory {
+	return AppHistory{
+		ID:          h.ID.String(),
+		UserID:      h.UserID.String(),
+		Action:      h.Action,
+		Diff:        h.Diff,
+		Actor:       h.Actor,
+		TraceID:     h.TraceID,
+		DateCreated: h.DateCreated.Format(time.RFC3339),
+	}
+}
+
+func toAppHistories(hists []user.History) []AppHistory {
+	items := make([]AppHistory, len(hists))
+	for i, h := range hists {
+		items[i] = toAppHistory(h)
+	}
+
+	return items
+}
+
+// =============================================================================
+
 // AppNewUser contains information needed to create a new user.
 type AppNewUser struct {
 	Name            string   `json:"name" validate:"required"`
@@ -97,3 +133,161 @@ func (app AppNewUser) Validate() error {
 
 	return nil
 }
+
+// =============================================================================
+
+// AppUpdateUser contains information needed to update a user.
+type AppUpdateUser struct {
+	Name            *string  `json:"name"`
+	Email           *string  `json:"email" validate:"omitempty,email"`
+	Roles           []string `json:"roles"`
+	Department      *string  `json:"department"`
+	Password        *string  `json:"password"`
+	PasswordConfirm *string  `json:"passwordConfirm" validate:"omitempty,eqfield=Password"`
+	Enabled         *bool    `json:"enabled"`
+}
+
+func toCoreUpdateUser(app AppUpdateUser) (user.UpdateUser, error) {
+	var roles []user.Role
+	if app.Roles != nil {
+		roles = make([]user.Role, len(app.Roles))
+		for i, roleStr := range app.Roles {
+			role, err := user.ParseRole(roleStr)
+			if err != nil {
+				return user.UpdateUser{}, fmt.Errorf("parsing role: %w", err)
+			}
+			roles[i] = role
+		}
+	}
+
+	var addr *mail.Address
+	if app.Email != nil {
+		var err error
+		addr, err = mail.ParseAddress(*app.Email)
+		if err != nil {
+			return user.UpdateUser{}, fmt.Errorf("parsing email: %w", err)
+		}
+	}
+
+	nu := user.UpdateUser{
+		Name:            app.Name,
+		Email:           addr,
+		Roles:           roles,
+		Department:      app.Department,
+		Password:        app.Password,
+		PasswordConfirm: app.PasswordConfirm,
+		Enabled:         app.Enabled,
+	}
+
+	return nu, nil
+}
+
+// Validate checks the data in the model is considered clean.
+func (app AppUpdateUser) Validate() error {
+	if err := validate.Check(app); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// =============================================================================
+
+// AppPatchUser is the document a patch is applied to when patching a user.
+type AppPatchUser struct {
+	Name            string   `json:"name" validate:"required"`
+	Email           string   `json:"email" validate:"required,email"`
+	Roles           []string `json:"roles" validate:"required"`
+	Department      string   `json:"department"`
+	Enabled         bool     `json:"enabled"`
+	Password        *string  `json:"password,omitempty"`
+	PasswordConfirm *string  `json:"passwordConfirm,omitempty" validate:"omitempty,eqfield=Password"`
+}
+
+func toAppPatchUser(usr user.User) AppPatchUser {
+	roles := make([]string, len(usr.Roles))
+	for i, role := range usr.Roles {
+		roles[i] = role.Name()
+	}
+
+	return AppPatchUser{
+		Name:       usr.Name,
+		Email:      usr.Email.Address,
+		Roles:      roles,
+		Department: usr.Department,
+		Enabled:    usr.Enabled,
+	}
+}
+
+func toAppUpdateUser(app AppPatchUser) AppUpdateUser {
+	return AppUpdateUser{
+		Name:            &app.Name,
+		Email:           &app.Email,
+		Roles:           app.Roles,
+		Department:      &app.Department,
+		Password:        app.Password,
+		PasswordConfirm: app.PasswordConfirm,
+		Enabled:         &app.Enabled,
+	}
+}
+
+// Validate checks the data in the model is considered clean.
+func (app AppPatchUser) Validate() error {
+	if err := validate.Check(app); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// =============================================================================
+
+// AppSearchQuery contains the query of a user search.
+type AppSearchQuery struct {
+	Query string `json:"q" validate:"required,min=2,max=100"`
+}
+
+// Validate checks the data in the model is considered clean.
+func (app AppSearchQuery) Validate() error {
+	if err := validate.Check(app); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// AppSearchResult represents a user matching a search. The highlights are
+// HTML escaped with the matching terms wrapped in mark elements.
+type AppSearchResult struct {
+	User       AppUser           `json:"user"`
+	Rank       float64           `json:"rank"`
+	Highlights map[string]string `json:"highlights"`
+}
+
+func toAppSearchResult(result user.SearchResult) AppSearchResult {
+	return AppSearchResult{
+		User: toAppUser(result.User),
+		Rank: result.Rank,
+		Highlights: map[string]string{
+			"name":  toAppHighlight(result.NameHighlight),
+			"email": toAppHighlight(result.EmailHighlight),
+		},
+	}
+}
+
+func toAppSearchResults(results []user.SearchResult) []AppSearchResult {
+	items := make([]AppSearchResult, len(results))
+	for i, result := range results {
+		items[i] = toAppSearchResult(result)
+	}
+
+	return items
+}
+
+var highlightReplacer = strings.NewReplacer(user.HighlightStart, "<mark>", user.HighlightStop, "</mark>")
+
+// toAppHighlight escapes the value before replacing the highlight markers
+// so user data can't inject markup.
+func toAppHighlight(highlight string) string {
+	return highlightReplacer.Replace(html.EscapeString(highlight))
+}
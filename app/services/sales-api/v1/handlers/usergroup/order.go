@@ -6,36 +6,42 @@ import (
 
 	"github.com/1core-dev/go-service/business/core/user"
 	"github.com/1core-dev/go-service/business/data/order"
+	"github.com/1core-dev/go-service/business/web/v1/response"
 	"github.com/1core-dev/go-service/pkg/validate"
 )
 
-func parseOrder(r *http.Request) (order.By, error) {
+func parseOrder(r *http.Request) (order.List, error) {
 	const (
-		orderByID      = "user_id"
-		orderByName    = "name"
-		orderByEmail   = "email"
-		orderByRoles   = "roles"
-		orderByEnabled = "enabled"
+		orderByID         = "user_id"
+		orderByName       = "name"
+		orderByEmail      = "email"
+		orderByRoles      = "roles"
+		orderByEnabled    = "enabled"
+		orderByDepartment = "department"
 	)
 
 	var orderByFields = map[string]string{
-		orderByID:      user.OrderByID,
-		orderByName:    user.OrderByName,
-		orderByEmail:   user.OrderByEmail,
-		orderByRoles:   user.OrderByRoles,
-		orderByEnabled: user.OrderByEnabled,
+		orderByID:         user.OrderByID,
+		orderByName:       user.OrderByName,
+		orderByEmail:      user.OrderByEmail,
+		orderByRoles:      user.OrderByRoles,
+		orderByEnabled:    user.OrderByEnabled,
+		orderByDepartment: user.OrderByDepartment,
 	}
 
-	orderBy, err := order.Parse(r, order.NewBy(orderByID, order.ASC))
+	orderBy, err := order.Parse(r, order.NewBy(orderByID, order.ASC), order.DefaultMaxFields)
 	if err != nil {
-		return order.By{}, err
+		return nil, response.NewError(err, http.StatusBadRequest)
 	}
 
-	if _, exists := orderByFields[orderBy.Field]; !exists {
-		return order.By{}, validate.NewFieldsError(orderBy.Field, errors.New("order field does not exist"))
-	}
+	for i, by := range orderBy {
+		field, exists := orderByFields[by.Field]
+		if !exists {
+			return nil, response.NewError(validate.NewFieldsError(by.Field, errors.New("order field does not exist")), http.StatusBadRequest)
+		}
 
-	orderBy.Field = orderByFields[orderBy.Field]
+		orderBy[i].Field = field
+	}
 
 	return orderBy, nil
 }
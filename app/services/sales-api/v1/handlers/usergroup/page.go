@@ -0,0 +1,53 @@
+package usergroup
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/1core-dev/go-service/business/data/page"
+	"github.com/1core-dev/go-service/business/web/v1/response"
+	"github.com/1core-dev/go-service/pkg/web"
+)
+
+// countTotal returns the total number of rows using the count mode of the
+// page. A nil total means the rows weren't counted. When no estimate is
+// provided, an estimated count falls back to the exact count.
+func countTotal(ctx context.Context, pg page.Page, exact func(context.Context) (int, error), estimate func(context.Context) (int, error)) (*int, bool, error) {
+	switch {
+	case pg.Count == page.CountNone:
+		return nil, false, nil
+
+	case pg.Count == page.CountEstimated && estimate != nil:
+		total, err := estimate(ctx)
+		if err != nil {
+			return nil, false, err
+		}
+		return &total, true, nil
+	}
+
+	total, err := exact(ctx)
+	if err != nil {
+		return nil, false, err
+	}
+
+	return &total, false, nil
+}
+
+// respondPage responds with a page document for the items, linking to the
+// neighbouring pages in the document and in the Link header.
+func respondPage[T any](ctx context.Context, w http.ResponseWriter, r *http.Request, items []T, pg page.Page, total *int, estimated bool) error {
+	doc := response.PageDocument[T]{
+		Items:          items,
+		Total:          total,
+		TotalEstimated: estimated,
+		Page:           pg.Number,
+		RowsPerPage:    pg.RowsPerPage,
+		Links:          response.NewPageLinks(r.URL, pg.Number, pg.RowsPerPage, len(items), total, estimated),
+	}
+
+	if link := doc.Links.Header(); link != "" {
+		w.Header().Set("Link", link)
+	}
+
+	return web.Respond(ctx, w, doc, http.StatusOK)
+}
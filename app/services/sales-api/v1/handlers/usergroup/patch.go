@@ -0,0 +1,112 @@
+package usergroup
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+
+	"github.com/1core-dev/go-service/business/web/v1/response"
+	"github.com/1core-dev/go-service/pkg/patch"
+	"github.com/1core-dev/go-service/pkg/validate"
+)
+
+// ErrUnsupportedPatch is returned when the patch content type is not known.
+var ErrUnsupportedPatch = fmt.Errorf("content type must be %s or %s", patch.MergePatchContentType, patch.JSONPatchContentType)
+
+// maxPatchSize is the maximum size of a patch document.
+const maxPatchSize = 1 << 20
+
+// applyPatch applies the patch in the request body to the user document. The
+// content type of the request selects the patch format. A body larger than
+// maxPatchSize results in a 413.
+func applyPatch(w http.ResponseWriter, r *http.Request, app AppPatchUser) (AppPatchUser, error) {
+	mediaType, _, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if err != nil {
+		return AppPatchUser{}, response.NewError(ErrUnsupportedPatch, http.StatusUnsupportedMediaType)
+	}
+
+	var apply func(doc []byte, patch []byte) ([]byte, error)
+	switch mediaType {
+	case patch.MergePatchContentType:
+		apply = patch.MergePatch
+	case patch.JSONPatchContentType:
+		apply = patch.JSONPatch
+	default:
+		return AppPatchUser{}, response.NewError(ErrUnsupportedPatch, http.StatusUnsupportedMediaType)
+	}
+
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxPatchSize))
+	if err != nil {
+		var maxErr *http.MaxBytesError
+		if errors.As(err, &maxErr) {
+			return AppPatchUser{}, response.NewError(fmt.Errorf("patch document larger than %d bytes", maxErr.Limit), http.StatusRequestEntityTooLarge)
+		}
+		return AppPatchUser{}, fmt.Errorf("read body: %w", err)
+	}
+
+	doc, err := json.Marshal(app)
+	if err != nil {
+		return AppPatchUser{}, fmt.Errorf("marshal document: %w", err)
+	}
+
+	patched, err := apply(doc, body)
+	if err != nil {
+		return AppPatchUser{}, response.NewError(err, http.StatusBadRequest)
+	}
+
+	if err := checkPatchFields(patched); err != nil {
+		return AppPatchUser{}, response.NewError(err, http.StatusBadRequest)
+	}
+
+	var result AppPatchUser
+	decoder := json.NewDecoder(bytes.NewReader(patched))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&result); err != nil {
+		return AppPatchUser{}, response.NewError(fmt.Errorf("unable to decode patched document: %w", err), http.StatusBadRequest)
+	}
+
+	if err := result.Validate(); err != nil {
+		return AppPatchUser{}, response.NewError(err, http.StatusBadRequest)
+	}
+
+	return result, nil
+}
+
+// checkPatchFields reports every field of the patched document that is not
+// part of the patchable user document.
+func checkPatchFields(patched []byte) error {
+	known := map[string]bool{
+		"name":            true,
+		"email":           true,
+		"roles":           true,
+		"department":      true,
+		"enabled":         true,
+		"password":        true,
+		"passwordConfirm": true,
+	}
+
+	var doc map[string]json.RawMessage
+	if err := json.Unmarshal(patched, &doc); err != nil {
+		return validate.NewFieldsError("patch", errors.New("patched document must be an object"))
+	}
+
+	var fields validate.FieldErrors
+	for field := range doc {
+		if !known[field] {
+			fields = append(fields, validate.FieldError{
+				Field: field,
+				Err:   "field can not be patched",
+			})
+		}
+	}
+
+	if len(fields) > 0 {
+		return fields
+	}
+
+	return nil
+}
@@ -0,0 +1,81 @@
+package usergroup
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/1core-dev/go-service/business/web/v1/middlewares"
+	"github.com/1core-dev/go-service/business/web/v1/response"
+	"github.com/1core-dev/go-service/pkg/logger"
+	"github.com/1core-dev/go-service/pkg/web"
+)
+
+// Test_QueryBadRequest checks the query strings rejected before the users
+// are queried are reported to the client as bad requests.
+func Test_QueryBadRequest(t *testing.T) {
+	log := logger.New(io.Discard, logger.LevelInfo, "TEST", nil)
+
+	var h Handlers
+
+	tests := []struct {
+		name    string
+		handler web.Handler
+		query   string
+		field   string
+	}{
+		{name: "pageNumber", query: "page=one", field: "page"},
+		{name: "pageRows", query: "rows=ten", field: "rows"},
+		{name: "pageCount", query: "count=all", field: "count"},
+		{name: "searchCount", handler: h.Search, query: "q=bill&count=all", field: "count"},
+		{name: "historyCount", handler: h.QueryHistory, query: "count=all", field: "count"},
+		{name: "filterSyntax", query: "filter=" + url.QueryEscape(`name eq "x" and`), field: "filter"},
+		{name: "filterField", query: "filter=" + url.QueryEscape(`password_hash eq "x"`), field: "filter"},
+		{name: "filterInjection", query: "filter=" + url.QueryEscape(`name eq "x"; DROP TABLE users; --`), field: "filter"},
+		{name: "filterTautology", query: "filter=" + url.QueryEscape(`name eq 'x' or 1=1`), field: "filter"},
+		{name: "filterEnabled", query: "enabled=maybe", field: "enabled"},
+		{name: "filterEmail", query: "email=bill", field: "email"},
+		{name: "orderDuplicate", query: "orderBy=name;name,DESC", field: "orderBy"},
+		{name: "orderTooMany", query: "orderBy=name;email;roles;enabled", field: "orderBy"},
+		{name: "orderDirection", query: "orderBy=name,UP", field: "orderBy"},
+		{name: "orderMissing", query: "orderBy=name;;email", field: "orderBy"},
+		{name: "orderUnknown", query: "orderBy=password_hash", field: "password_hash"},
+	}
+
+	for _, tt := range tests {
+		handler := tt.handler
+		if handler == nil {
+			handler = h.Query
+		}
+		handler = middlewares.Errors(log)(handler)
+
+		r := httptest.NewRequest(http.MethodGet, "/v1/users?"+tt.query, nil)
+		w := httptest.NewRecorder()
+
+		ctx := web.SetValues(context.Background(), &web.Values{})
+
+		if err := handler(ctx, w, r); err != nil {
+			t.Errorf("%s: Should handle the error : %s.", tt.name, err)
+			continue
+		}
+
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("%s: Should receive a status code of 400 for the response : %d.", tt.name, w.Code)
+			continue
+		}
+
+		var doc response.ErrorDocument
+		if err := json.Unmarshal(w.Body.Bytes(), &doc); err != nil {
+			t.Errorf("%s: Should be able to unmarshal the response : %s.", tt.name, err)
+			continue
+		}
+
+		if _, exists := doc.Fields[tt.field]; !exists {
+			t.Errorf("%s: Should get the error of the %s field : %+v.", tt.name, tt.field, doc)
+		}
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"github.com/1core-dev/go-service/business/core/user"
 	"github.com/1core-dev/go-service/business/core/user/stores/userdb"
 	db "github.com/1core-dev/go-service/business/data/dbsql/pgx"
+	"github.com/1core-dev/go-service/business/data/page"
 	"github.com/1core-dev/go-service/business/web/v1/auth"
 	"github.com/1core-dev/go-service/business/web/v1/middlewares"
 	"github.com/1core-dev/go-service/pkg/logger"
@@ -16,10 +17,12 @@ import (
 
 // Config contains all the mandatory systems required by handlers.
 type Config struct {
-	Build string
-	Log   *logger.Logger
-	DB    *sqlx.DB
-	Auth  *auth.Auth
+	Build        string
+	Log          *logger.Logger
+	DB           *sqlx.DB
+	Auth         *auth.Auth
+	CursorKey    []byte
+	CacheControl string
 }
 
 // Routes adds specific routes for this group.
@@ -31,12 +34,24 @@ func Routes(app *web.App, cfg Config) {
 	ruleAdminOrSubject := middlewares.Authorize(cfg.Auth, auth.RuleAdminOrSubject)
 	tx := middlewares.ExecuteInTransation(cfg.Log, db.NewBeginner(cfg.DB))
 
-	usrCore := user.NewCore(cfg.Log, userdb.NewStore(cfg.Log, cfg.DB))
+	// Reads can be cached by the caller and revalidated, except for the
+	// export which holds every user.
+	cache := middlewares.CacheControl(cfg.CacheControl)
+	noStore := middlewares.CacheControl("no-store")
 
-	handler := New(usrCore, cfg.Auth)
+	usrCore := user.NewCore(cfg.Log, db.NewBeginner(cfg.DB), userdb.NewStore(cfg.Log, cfg.DB))
+
+	handler := New(usrCore, cfg.Auth, page.NewCodec(cfg.CursorKey))
 	app.Handle(http.MethodPost, version, "/users", handler.Create)
 	app.Handle(http.MethodPost, version, "/userstran", handler.CreateWithTran, authentication, ruleAdmin, tx)
 	app.Handle(http.MethodPost, version, "/usersauth", handler.Create, authentication, ruleAdmin)
-	app.Handle(http.MethodGet, version, "/users", handler.Query, authentication, ruleAdmin)
-	app.Handle(http.MethodGet, version, "/users/:user_id", handler.QueryByID, authentication, ruleAdminOrSubject)
+	app.Handle(http.MethodPost, version, "/users/bulk", handler.Bulk, authentication, ruleAdmin, tx)
+	app.Handle(http.MethodGet, version, "/users", handler.Query, authentication, ruleAdmin, cache)
+	app.Handle(http.MethodGet, version, "/users/export", handler.Export, authentication, ruleAdmin, noStore)
+	app.Handle(http.MethodGet, version, "/users/search", handler.Search, authentication, ruleAdmin, cache)
+	app.Handle(http.MethodGet, version, "/users/:user_id", handler.QueryByID, authentication, ruleAdminOrSubject, cache)
+	app.Handle(http.MethodPut, version, "/users/:user_id", handler.Update, authentication, ruleAdminOrSubject)
+	app.Handle(http.MethodPatch, version, "/users/:user_id", handler.Patch, authentication, ruleAdminOrSubject)
+	app.Handle(http.MethodDelete, version, "/users/:user_id", handler.Delete, authentication, ruleAdminOrSubject)
+	app.Handle(http.MethodGet, version, "/users/:user_id/history", handler.QueryHistory, authentication, ruleAdmin, cache)
 }
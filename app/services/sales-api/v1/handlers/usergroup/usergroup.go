@@ -2,29 +2,35 @@ package usergroup
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"net/http"
+	"slices"
 
 	"github.com/1core-dev/go-service/business/core/user"
+	"github.com/1core-dev/go-service/business/data/order"
 	"github.com/1core-dev/go-service/business/data/page"
 	"github.com/1core-dev/go-service/business/data/transaction"
 	"github.com/1core-dev/go-service/business/web/v1/auth"
 	"github.com/1core-dev/go-service/business/web/v1/response"
+	"github.com/1core-dev/go-service/pkg/validate"
 	"github.com/1core-dev/go-service/pkg/web"
 )
 
 // Handlers manages the set of user endpoints.
 type Handlers struct {
-	user *user.Core
-	auth *auth.Auth
+	user    *user.Core
+	auth    *auth.Auth
+	cursors *page.Codec
 }
 
 // New constructs a handlers for route access.
-func New(user *user.Core, auth *auth.Auth) *Handlers {
+func New(user *user.Core, auth *auth.Auth, cursors *page.Codec) *Handlers {
 	return &Handlers{
-		user: user,
-		auth: auth,
+		user:    user,
+		auth:    auth,
+		cursors: cursors,
 	}
 }
 
@@ -79,6 +85,153 @@ func (h *Handlers) CreateWithTran(ctx context.Context, w http.ResponseWriter, r
 	return web.Respond(ctx, w, toAppUser(usr), http.StatusCreated)
 }
 
+// Update updates a user in the system. The request must carry the user
+// version in the If-Match header.
+func (h *Handlers) Update(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	im, err := parseIfMatch(r)
+	if err != nil {
+		return err
+	}
+
+	var app AppUpdateUser
+	if err := web.Decode(r, &app); err != nil {
+		return response.NewError(err, http.StatusBadRequest)
+	}
+
+	uu, err := toCoreUpdateUser(app)
+	if err != nil {
+		return response.NewError(err, http.StatusBadRequest)
+	}
+
+	id := auth.GetUserID(ctx)
+
+	usr, err := h.user.QueryByID(ctx, id)
+	if err != nil {
+		switch {
+		case errors.Is(err, user.ErrNotFound):
+			return response.NewError(err, http.StatusNotFound)
+		default:
+			return fmt.Errorf("querybyid: id[%s]: %w", id, err)
+		}
+	}
+
+	if !im.matches(usr) {
+		return response.NewError(ErrPreconditionFailed, http.StatusPreconditionFailed)
+	}
+
+	if err := h.checkAdminChanges(ctx, usr, uu); err != nil {
+		return err
+	}
+
+	usr, err = h.user.Update(ctx, usr, uu)
+	if err != nil {
+		switch {
+		case errors.Is(err, user.ErrVersionConflict):
+			return response.NewError(ErrPreconditionFailed, http.StatusPreconditionFailed)
+		case errors.Is(err, user.ErrUniqueEmail):
+			return response.NewError(err, http.StatusConflict)
+		default:
+			return fmt.Errorf("update: id[%s] uu[%+v]: %w", id, uu, err)
+		}
+	}
+
+	w.Header().Set("ETag", etag(usr))
+
+	return web.Respond(ctx, w, toAppUser(usr), http.StatusOK)
+}
+
+// Patch applies a JSON Merge Patch or JSON Patch document to a user in the
+// system. The request must carry the user version in the If-Match header.
+func (h *Handlers) Patch(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	im, err := parseIfMatch(r)
+	if err != nil {
+		return err
+	}
+
+	id := auth.GetUserID(ctx)
+
+	usr, err := h.user.QueryByID(ctx, id)
+	if err != nil {
+		switch {
+		case errors.Is(err, user.ErrNotFound):
+			return response.NewError(err, http.StatusNotFound)
+		default:
+			return fmt.Errorf("querybyid: id[%s]: %w", id, err)
+		}
+	}
+
+	if !im.matches(usr) {
+		return response.NewError(ErrPreconditionFailed, http.StatusPreconditionFailed)
+	}
+
+	app, err := applyPatch(w, r, toAppPatchUser(usr))
+	if err != nil {
+		return err
+	}
+
+	uu, err := toCoreUpdateUser(toAppUpdateUser(app))
+	if err != nil {
+		return response.NewError(err, http.StatusBadRequest)
+	}
+
+	if err := h.checkAdminChanges(ctx, usr, uu); err != nil {
+		return err
+	}
+
+	usr, err = h.user.Update(ctx, usr, uu)
+	if err != nil {
+		switch {
+		case errors.Is(err, user.ErrVersionConflict):
+			return response.NewError(ErrPreconditionFailed, http.StatusPreconditionFailed)
+		case errors.Is(err, user.ErrUniqueEmail):
+			return response.NewError(err, http.StatusConflict)
+		default:
+			return fmt.Errorf("update: id[%s] uu[%+v]: %w", id, uu, err)
+		}
+	}
+
+	w.Header().Set("ETag", etag(usr))
+
+	return web.Respond(ctx, w, toAppUser(usr), http.StatusOK)
+}
+
+// Delete removes a user from the system. The request must carry the user
+// version in the If-Match header. Deleting a user that doesn't exist is a
+// 404, since there is no version the precondition could match.
+func (h *Handlers) Delete(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	im, err := parseIfMatch(r)
+	if err != nil {
+		return err
+	}
+
+	id := auth.GetUserID(ctx)
+
+	usr, err := h.user.QueryByID(ctx, id)
+	if err != nil {
+		switch {
+		case errors.Is(err, user.ErrNotFound):
+			return response.NewError(err, http.StatusNotFound)
+		default:
+			return fmt.Errorf("querybyid: id[%s]: %w", id, err)
+		}
+	}
+
+	if !im.matches(usr) {
+		return response.NewError(ErrPreconditionFailed, http.StatusPreconditionFailed)
+	}
+
+	if err := h.user.Delete(ctx, usr); err != nil {
+		switch {
+		case errors.Is(err, user.ErrVersionConflict):
+			return response.NewError(ErrPreconditionFailed, http.StatusPreconditionFailed)
+		default:
+			return fmt.Errorf("delete: id[%s]: %w", id, err)
+		}
+	}
+
+	return web.Respond(ctx, w, nil, http.StatusNoContent)
+}
+
 // executeUnderTransaction constructs a new Handlers value with the core APIs
 // using a store transaction that was created via middleware.
 func (h *Handlers) executeUnderTransaction(ctx context.Context) (*Handlers, error) {
@@ -88,22 +241,22 @@ func (h *Handlers) executeUnderTransaction(ctx context.Context) (*Handlers, erro
 			return nil, err
 		}
 
-		h = &Handlers{
-			user: user,
-			auth: h.auth,
-		}
+		// The copy keeps every other dependency of the handlers.
+		h2 := *h
+		h2.user = user
 
-		return h, nil
+		return &h2, nil
 	}
 
 	return h, nil
 }
 
-// Query returns a list of users with paging.
+// Query returns a list of users with paging. The fields query string limits
+// the fields returned for each user.
 func (h *Handlers) Query(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
 	page, err := page.Parse(r)
 	if err != nil {
-		return err
+		return response.NewError(err, http.StatusBadRequest)
 	}
 
 	filter, err := parseFilter(r)
@@ -116,21 +269,142 @@ func (h *Handlers) Query(ctx context.Context, w http.ResponseWriter, r *http.Req
 		return err
 	}
 
-	users, err := h.user.Query(ctx, filter, orderBy, page.Number, page.RowsPerPage)
+	names, fields, err := parseFields(r)
+	if err != nil {
+		return err
+	}
+
+	// No related resources can be expanded on a list of users.
+	if _, err := parseExpand(r); err != nil {
+		return err
+	}
+
+	if page.Keyset {
+		return h.queryKeyset(ctx, w, filter, orderBy, names, page)
+	}
+
+	users, err := h.user.QueryFields(ctx, filter, orderBy, fields, page.Number, page.RowsPerPage)
 	if err != nil {
 		return fmt.Errorf("query: %w", err)
 	}
 
-	total, err := h.user.Count(ctx, filter)
+	count := func(ctx context.Context) (int, error) {
+		return h.user.Count(ctx, filter)
+	}
+
+	estimate := func(ctx context.Context) (int, error) {
+		return h.user.EstimateCount(ctx, filter)
+	}
+
+	total, estimated, err := countTotal(ctx, page, count, estimate)
 	if err != nil {
 		return fmt.Errorf("count: %w", err)
 	}
 
-	return web.Respond(ctx, w, response.NewPageDocument(toAppUsers(users), total, page.Number, page.RowsPerPage), http.StatusOK)
+	if names == nil {
+		return respondPage(ctx, w, r, toAppUsers(users), page, total, estimated)
+	}
+
+	items, err := projectUsers(toAppUsers(users), names)
+	if err != nil {
+		return fmt.Errorf("project: %w", err)
+	}
+
+	return respondPage(ctx, w, r, items, page, total, estimated)
+}
+
+// Search returns the users best matching the query, ranked by relevance.
+func (h *Handlers) Search(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	page, err := page.Parse(r)
+	if err != nil {
+		return response.NewError(err, http.StatusBadRequest)
+	}
+
+	app := AppSearchQuery{
+		Query: r.URL.Query().Get("q"),
+	}
+
+	if err := app.Validate(); err != nil {
+		return response.NewError(err, http.StatusBadRequest)
+	}
+
+	results, err := h.user.Search(ctx, app.Query, page.Number, page.RowsPerPage)
+	if err != nil {
+		return fmt.Errorf("search: %w", err)
+	}
+
+	count := func(ctx context.Context) (int, error) {
+		return h.user.CountSearch(ctx, app.Query)
+	}
+
+	total, estimated, err := countTotal(ctx, page, count, nil)
+	if err != nil {
+		return fmt.Errorf("count search: %w", err)
+	}
+
+	return respondPage(ctx, w, r, toAppSearchResults(results), page, total, estimated)
 }
 
-// QueryByID returns a user by its ID.
+// queryKeyset returns the page of users following the cursor provided in the
+// request along with the cursors for the neighbouring pages.
+func (h *Handlers) queryKeyset(ctx context.Context, w http.ResponseWriter, filter user.QueryFilter, orderBy order.List, names []string, pg page.Page) error {
+	var cursor page.Cursor
+	if pg.Cursor != "" {
+		var err error
+		cursor, err = h.cursors.Decode(pg.Cursor)
+		if err != nil {
+			return response.NewError(validate.NewFieldsError("cursor", err), http.StatusBadRequest)
+		}
+	}
+
+	users, cursors, err := h.user.QueryKeyset(ctx, filter, orderBy, cursor, pg.RowsPerPage)
+	if err != nil {
+		if errors.Is(err, page.ErrInvalidCursor) {
+			return response.NewError(validate.NewFieldsError("cursor", page.ErrInvalidCursor), http.StatusBadRequest)
+		}
+		return fmt.Errorf("query: %w", err)
+	}
+
+	var next, prev string
+	if cursors.Next != nil {
+		next = h.cursors.Encode(*cursors.Next)
+	}
+	if cursors.Prev != nil {
+		prev = h.cursors.Encode(*cursors.Prev)
+	}
+
+	if names == nil {
+		return web.Respond(ctx, w, response.NewCursorDocument(toAppUsers(users), pg.RowsPerPage, next, prev), http.StatusOK)
+	}
+
+	items, err := projectUsers(toAppUsers(users), names)
+	if err != nil {
+		return fmt.Errorf("project: %w", err)
+	}
+
+	return web.Respond(ctx, w, response.NewCursorDocument(items, pg.RowsPerPage, next, prev), http.StatusOK)
+}
+
+// QueryByID returns a user by its ID. The fields query string limits the
+// fields returned and expand=history includes the most recent changes for
+// admins.
 func (h *Handlers) QueryByID(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	names, _, err := parseFields(r)
+	if err != nil {
+		return err
+	}
+
+	expand, err := parseExpand(r, expandHistory)
+	if err != nil {
+		return err
+	}
+
+	// The history of a user is only available to admins, like on the
+	// history route.
+	if slices.Contains(expand, expandHistory) && !h.isAdmin(ctx) {
+		return response.NewError(ErrHistoryAdminOnly, http.StatusForbidden)
+	}
+
 	id := auth.GetUserID(ctx)
 
 	usr, err := h.user.QueryByID(ctx, id)
@@ -143,5 +417,59 @@ func (h *Handlers) QueryByID(ctx context.Context, w http.ResponseWriter, r *http
 		}
 	}
 
-	return web.Respond(ctx, w, toAppUser(usr), http.StatusOK)
+	// The version changes along with DateUpdated on every update, so both
+	// validators identify the same state of the user.
+	if web.CheckNotModified(w, r, etag(usr), usr.DateUpdated) {
+		return web.RespondNotModified(ctx, w)
+	}
+
+	if names == nil && expand == nil {
+		return web.Respond(ctx, w, toAppUser(usr), http.StatusOK)
+	}
+
+	doc, err := response.Fields(toAppUser(usr), names)
+	if err != nil {
+		return fmt.Errorf("project: %w", err)
+	}
+
+	if slices.Contains(expand, expandHistory) {
+		hists, err := h.user.QueryHistory(ctx, id, 1, expandHistoryRows)
+		if err != nil {
+			return fmt.Errorf("queryhistory: id[%s]: %w", id, err)
+		}
+
+		data, err := json.Marshal(toAppHistories(hists))
+		if err != nil {
+			return fmt.Errorf("marshal history: %w", err)
+		}
+		doc[expandHistory] = data
+	}
+
+	return web.Respond(ctx, w, doc, http.StatusOK)
+}
+
+// QueryHistory returns the recorded changes for a user with paging.
+func (h *Handlers) QueryHistory(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	page, err := page.Parse(r)
+	if err != nil {
+		return response.NewError(err, http.StatusBadRequest)
+	}
+
+	id := auth.GetUserID(ctx)
+
+	hists, err := h.user.QueryHistory(ctx, id, page.Number, page.RowsPerPage)
+	if err != nil {
+		return fmt.Errorf("queryhistory: id[%s]: %w", id, err)
+	}
+
+	count := func(ctx context.Context) (int, error) {
+		return h.user.CountHistory(ctx, id)
+	}
+
+	total, estimated, err := countTotal(ctx, page, count, nil)
+	if err != nil {
+		return fmt.Errorf("counthistory: id[%s]: %w", id, err)
+	}
+
+	return respondPage(ctx, w, r, toAppHistories(hists), page, total, estimated)
 }
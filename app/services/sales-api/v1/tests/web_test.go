@@ -8,6 +8,7 @@ import (
 	"net/http/httptest"
 	"os"
 	"runtime/debug"
+	"strings"
 	"testing"
 
 	"github.com/1core-dev/go-service/app/services/sales-api/v1/handlers"
@@ -18,6 +19,7 @@ import (
 	v1 "github.com/1core-dev/go-service/business/web/v1"
 	"github.com/1core-dev/go-service/business/web/v1/response"
 	"github.com/google/go-cmp/cmp"
+	"github.com/google/uuid"
 )
 
 type seedData struct {
@@ -51,10 +53,11 @@ func Test_Web(t *testing.T) {
 	shutdown := make(chan os.Signal, 1)
 	tests := WebTests{
 		app: v1.APIMux(v1.APIMuxConfig{
-			Shutdown: shutdown,
-			Log:      test.Log,
-			Auth:     test.V1.Auth,
-			DB:       test.DB,
+			Shutdown:  shutdown,
+			Log:       test.Log,
+			Auth:      test.V1.Auth,
+			DB:        test.DB,
+			CursorKey: []byte("test cursor key"),
 		}, handlers.Routes{}),
 		userToken:  test.TokenV1("user@example.com", "gophers"),
 		adminToken: test.TokenV1("admin@example.com", "gophers"),
@@ -63,7 +66,7 @@ func Test_Web(t *testing.T) {
 	// -------------------------------------------------------------------------
 
 	seed := func(ctx context.Context, api dbtest.CoreAPIs) (seedData, error) {
-		usrs, err := api.User.Query(ctx, user.QueryFilter{}, order.By{Field: user.OrderByName, Direction: order.ASC}, 1, 2)
+		usrs, err := api.User.Query(ctx, user.QueryFilter{}, order.NewList(order.NewBy(user.OrderByName, order.ASC)), 1, 2)
 		if err != nil {
 			return seedData{}, fmt.Errorf("seeding users : %w", err)
 		}
@@ -85,6 +88,12 @@ func Test_Web(t *testing.T) {
 	// -------------------------------------------------------------------------
 
 	t.Run("get200", tests.get200(sd))
+	t.Run("update403", tests.update403(sd))
+	t.Run("patch413", tests.patch413(sd))
+	t.Run("expand403", tests.expand403(sd))
+	t.Run("ifMatch", tests.ifMatch(sd))
+	t.Run("delete404", tests.delete404(sd))
+	t.Run("bulk", tests.bulk(sd))
 }
 
 func (wt *WebTests) get200(sd seedData) func(t *testing.T) {
@@ -102,8 +111,12 @@ func (wt *WebTests) get200(sd seedData) func(t *testing.T) {
 				expResp: &response.PageDocument[usergroup.AppUser]{
 					Page:        1,
 					RowsPerPage: 2,
-					Total:       len(sd.users),
+					Total:       dbtest.IntPointer(len(sd.users)),
 					Items:       toAppUsers(sd.users),
+					Links: response.PageLinks{
+						First: "/v1/users?orderBy=user_id,DESC&page=1&rows=2",
+						Last:  "/v1/users?orderBy=user_id,DESC&page=1&rows=2",
+					},
 				},
 			},
 		}
@@ -137,3 +150,243 @@ func (wt *WebTests) get200(sd seedData) func(t *testing.T) {
 		}
 	}
 }
+
+func (wt *WebTests) update403(sd seedData) func(t *testing.T) {
+	return func(t *testing.T) {
+		var usr user.User
+		for _, u := range sd.users {
+			if u.Email.Address == "user@example.com" {
+				usr = u
+			}
+		}
+
+		table := []struct {
+			name        string
+			method      string
+			contentType string
+			body        string
+		}{
+			{
+				name:        "roles",
+				method:      http.MethodPut,
+				contentType: "application/json",
+				body:        `{"roles":["ADMIN"]}`,
+			},
+			{
+				name:        "enabled",
+				method:      http.MethodPut,
+				contentType: "application/json",
+				body:        `{"enabled":false}`,
+			},
+			{
+				name:        "mergepatch",
+				method:      http.MethodPatch,
+				contentType: "application/merge-patch+json",
+				body:        `{"roles":["ADMIN","USER"]}`,
+			},
+			{
+				name:        "jsonpatch",
+				method:      http.MethodPatch,
+				contentType: "application/json-patch+json",
+				body:        `[{"op":"add","path":"/roles/-","value":"ADMIN"}]`,
+			},
+		}
+
+		for _, tt := range table {
+			r := httptest.NewRequest(tt.method, "/v1/users/"+usr.ID.String(), strings.NewReader(tt.body))
+			w := httptest.NewRecorder()
+
+			r.Header.Set("Authorization", "Bearer "+wt.userToken)
+			r.Header.Set("Content-Type", tt.contentType)
+			r.Header.Set("If-Match", fmt.Sprintf(`"%d"`, usr.Version))
+			wt.app.ServeHTTP(w, r)
+
+			if w.Code != http.StatusForbidden {
+				t.Errorf("%s: Should receive a status code of 403 for the response : %d", tt.name, w.Code)
+			}
+		}
+	}
+}
+
+func (wt *WebTests) patch413(sd seedData) func(t *testing.T) {
+	return func(t *testing.T) {
+		usr := sd.users[0]
+
+		body := `{"department":"` + strings.Repeat("x", 2<<20) + `"}`
+
+		r := httptest.NewRequest(http.MethodPatch, "/v1/users/"+usr.ID.String(), strings.NewReader(body))
+		w := httptest.NewRecorder()
+
+		r.Header.Set("Authorization", "Bearer "+wt.adminToken)
+		r.Header.Set("Content-Type", "application/merge-patch+json")
+		r.Header.Set("If-Match", fmt.Sprintf(`"%d"`, usr.Version))
+		wt.app.ServeHTTP(w, r)
+
+		if w.Code != http.StatusRequestEntityTooLarge {
+			t.Errorf("Should receive a status code of 413 for the response : %d", w.Code)
+		}
+	}
+}
+
+func (wt *WebTests) expand403(sd seedData) func(t *testing.T) {
+	return func(t *testing.T) {
+		var usr user.User
+		for _, u := range sd.users {
+			if u.Email.Address == "user@example.com" {
+				usr = u
+			}
+		}
+
+		r := httptest.NewRequest(http.MethodGet, "/v1/users/"+usr.ID.String()+"?expand=history", nil)
+		w := httptest.NewRecorder()
+
+		r.Header.Set("Authorization", "Bearer "+wt.userToken)
+		wt.app.ServeHTTP(w, r)
+
+		if w.Code != http.StatusForbidden {
+			t.Errorf("Should receive a status code of 403 for the response : %d", w.Code)
+		}
+	}
+}
+
+func (wt *WebTests) ifMatch(sd seedData) func(t *testing.T) {
+	return func(t *testing.T) {
+		usr := sd.users[0]
+
+		table := []struct {
+			name       string
+			ifMatch    string
+			statusCode int
+		}{
+			{name: "weak", ifMatch: fmt.Sprintf(`W/"%d"`, usr.Version), statusCode: http.StatusPreconditionFailed},
+			{name: "stale", ifMatch: fmt.Sprintf(`"%d"`, usr.Version+1), statusCode: http.StatusPreconditionFailed},
+			{name: "any", ifMatch: "*", statusCode: http.StatusOK},
+		}
+
+		for _, tt := range table {
+			r := httptest.NewRequest(http.MethodPut, "/v1/users/"+usr.ID.String(), strings.NewReader(`{"department":"IT"}`))
+			w := httptest.NewRecorder()
+
+			r.Header.Set("Authorization", "Bearer "+wt.adminToken)
+			r.Header.Set("Content-Type", "application/json")
+			r.Header.Set("If-Match", tt.ifMatch)
+			wt.app.ServeHTTP(w, r)
+
+			if w.Code != tt.statusCode {
+				t.Errorf("%s: Should receive a status code of %d for the response : %d", tt.name, tt.statusCode, w.Code)
+			}
+		}
+	}
+}
+
+func (wt *WebTests) delete404(sd seedData) func(t *testing.T) {
+	return func(t *testing.T) {
+		r := httptest.NewRequest(http.MethodDelete, "/v1/users/"+uuid.NewString(), nil)
+		w := httptest.NewRecorder()
+
+		r.Header.Set("Authorization", "Bearer "+wt.adminToken)
+		r.Header.Set("If-Match", "*")
+		wt.app.ServeHTTP(w, r)
+
+		if w.Code != http.StatusNotFound {
+			t.Errorf("Should receive a status code of 404 for the response : %d", w.Code)
+		}
+	}
+}
+
+func (wt *WebTests) bulk(sd seedData) func(t *testing.T) {
+	return func(t *testing.T) {
+		create := func(email string) string {
+			return `{"op":"create","user":{"name":"Bulk User","email":"` + email + `","roles":["USER"],"password":"gophers","passwordConfirm":"gophers"}}`
+		}
+
+		// The duplicated email fails in the database, which aborts the
+		// transaction unless the operation runs under its own savepoint.
+		duplicate := create(sd.users[0].Email.Address)
+
+		table := []struct {
+			name       string
+			mode       string
+			operations []string
+			statusCode int
+			committed  bool
+			statuses   []int
+		}{
+			{
+				name:       "bestEffort",
+				mode:       usergroup.BulkModeBestEffort,
+				operations: []string{create("bulk1@example.com"), duplicate, create("bulk2@example.com")},
+				statusCode: http.StatusOK,
+				committed:  true,
+				statuses:   []int{http.StatusCreated, http.StatusConflict, http.StatusCreated},
+			},
+			{
+				name:       "atomic",
+				mode:       usergroup.BulkModeAtomic,
+				operations: []string{create("bulk3@example.com"), duplicate, create("bulk4@example.com")},
+				statusCode: http.StatusUnprocessableEntity,
+				committed:  false,
+				statuses:   []int{http.StatusCreated, http.StatusConflict, http.StatusCreated},
+			},
+		}
+
+		for _, tt := range table {
+			body := `{"mode":"` + tt.mode + `","operations":[` + strings.Join(tt.operations, ",") + `]}`
+
+			r := httptest.NewRequest(http.MethodPost, "/v1/users/bulk", strings.NewReader(body))
+			w := httptest.NewRecorder()
+
+			r.Header.Set("Authorization", "Bearer "+wt.adminToken)
+			r.Header.Set("Content-Type", "application/json")
+			wt.app.ServeHTTP(w, r)
+
+			if w.Code != tt.statusCode {
+				t.Errorf("%s: Should receive a status code of %d for the response : %d", tt.name, tt.statusCode, w.Code)
+				continue
+			}
+
+			var resp usergroup.AppBulkResponse
+			if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+				t.Errorf("%s: Should be able to unmarshal the response : %s", tt.name, err)
+				continue
+			}
+
+			if resp.Committed != tt.committed || len(resp.Results) != len(tt.statuses) {
+				t.Errorf("%s: Should get the expected outcome : committed %t, results %d", tt.name, resp.Committed, len(resp.Results))
+				continue
+			}
+
+			for i, res := range resp.Results {
+				if res.Index != i || res.Status != tt.statuses[i] {
+					t.Errorf("%s: Should get the expected result for operation %d : %+v", tt.name, i, res)
+					continue
+				}
+
+				if res.User == nil {
+					continue
+				}
+
+				// The created users only exist when the request is committed.
+				exp := http.StatusNotFound
+				if tt.committed {
+					exp = http.StatusOK
+				}
+
+				if code := wt.getUser(res.User.ID); code != exp {
+					t.Errorf("%s: Should receive a status code of %d for user %d : %d", tt.name, exp, i, code)
+				}
+			}
+		}
+	}
+}
+
+// getUser returns the status code of a request for the user.
+func (wt *WebTests) getUser(id string) int {
+	r := httptest.NewRequest(http.MethodGet, "/v1/users/"+id, nil)
+	w := httptest.NewRecorder()
+
+	r.Header.Set("Authorization", "Bearer "+wt.adminToken)
+	wt.app.ServeHTTP(w, r)
+
+	return w.Code
+}
@@ -41,7 +41,7 @@ func main() {
 		}
 
 		// Stub to have a traceID in the logs.
-		traceID := "00000000-0000-0000-0000-000000000000"
+		traceID := strings.Repeat("0", 32)
 
 		if v, ok := m["trace_id"]; ok {
 			traceID = fmt.Sprintf("%v", v)
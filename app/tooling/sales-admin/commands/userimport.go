@@ -0,0 +1,473 @@
+// Package commands contains the functionality for the set of commands
+// currently supported by the CLI tooling.
+package commands
+
+import (
+	"bufio"
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net/mail"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/1core-dev/go-service/business/core/user"
+	"github.com/1core-dev/go-service/business/core/user/stores/userdb"
+	"github.com/1core-dev/go-service/business/data/actor"
+	sqldb "github.com/1core-dev/go-service/business/data/dbsql/pgx"
+	"github.com/1core-dev/go-service/pkg/logger"
+	"github.com/1core-dev/go-service/pkg/validate"
+)
+
+// importActor is recorded in the user history as the author of imports.
+const importActor = "sales-admin"
+
+// maxBatchSize keeps a batch insert below the postgres limit of 65535
+// parameters per statement.
+const maxBatchSize = 5000
+
+// UserImportConfig represents the options for a user import.
+type UserImportConfig struct {
+	File      string
+	Report    string
+	BatchSize int
+	DryRun    bool
+}
+
+// ParseUserImportConfig parses the command line arguments of the user
+// import command.
+func ParseUserImportConfig(args []string) (UserImportConfig, error) {
+	var cfg UserImportConfig
+
+	fs := flag.NewFlagSet("users import", flag.ContinueOnError)
+	fs.StringVar(&cfg.File, "file", "", "csv or ndjson file of users to import")
+	fs.StringVar(&cfg.Report, "report", "", "file for rows that failed (default <file>.errors.csv)")
+	fs.IntVar(&cfg.BatchSize, "batch-size", 500, "number of users written per statement")
+	fs.BoolVar(&cfg.DryRun, "dry-run", false, "validate the file without writing to the database")
+
+	if err := fs.Parse(args); err != nil {
+		return UserImportConfig{}, err
+	}
+
+	if cfg.File == "" {
+		return UserImportConfig{}, errors.New("missing --file")
+	}
+
+	if cfg.BatchSize < 1 || cfg.BatchSize > maxBatchSize {
+		return UserImportConfig{}, fmt.Errorf("batch size must be between 1 and %d", maxBatchSize)
+	}
+
+	if cfg.Report == "" {
+		cfg.Report = cfg.File + ".errors.csv"
+	}
+
+	return cfg, nil
+}
+
+// =============================================================================
+
+// importUser represents the fields of a user in the import file. They are
+// validated with the same rules the user api applies to a new user.
+type importUser struct {
+	Name            string   `json:"name" validate:"required"`
+	Email           string   `json:"email" validate:"required,email"`
+	Roles           []string `json:"roles" validate:"required"`
+	Department      string   `json:"department"`
+	Password        string   `json:"password" validate:"required"`
+	PasswordConfirm string   `json:"passwordConfirm" validate:"eqfield=Password"`
+}
+
+// Validate checks the data in the model is considered clean.
+func (app importUser) Validate() error {
+	if err := validate.Check(app); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// importRow represents a single user read from the import file.
+type importRow struct {
+	line   int
+	app    importUser
+	invite bool
+}
+
+// importError represents a problem found with a single row.
+type importError struct {
+	line  int
+	email string
+	field string
+	err   string
+}
+
+// UserImport reads the users from a csv or ndjson file and adds them to the
+// system. Users without a password are given a generated one which is written
+// to an invitations file. Rows that fail validation are written to the report
+// file and the remaining rows are still imported. Importing the same file
+// again updates the existing users.
+func UserImport(dbConfig sqldb.Config, cfg UserImportConfig) error {
+	rows, err := readImportFile(cfg.File)
+	if err != nil {
+		return fmt.Errorf("read file: %w", err)
+	}
+
+	nus, invites, rowErrs := validateRows(rows)
+
+	if err := writeReport(cfg.Report, rowErrs); err != nil {
+		return fmt.Errorf("write report: %w", err)
+	}
+
+	fmt.Printf("rows read: %d, valid: %d, rejected: %d\n", len(rows), len(nus), len(rows)-len(nus))
+	if len(rowErrs) > 0 {
+		fmt.Printf("errors written to %s\n", cfg.Report)
+	}
+
+	if cfg.DryRun {
+		fmt.Println("dry run: no users were imported")
+		return nil
+	}
+
+	// -------------------------------------------------------------------------
+
+	db, err := sqldb.Open(dbConfig)
+	if err != nil {
+		return fmt.Errorf("connect database: %w", err)
+	}
+	defer db.Close()
+
+	ctx := actor.Set(context.Background(), importActor)
+
+	if err := sqldb.StatusCheck(ctx, db); err != nil {
+		return fmt.Errorf("status check database: %w", err)
+	}
+
+	log := logger.New(io.Discard, logger.LevelInfo, "SALES-ADMIN", nil)
+	core := user.NewCore(log, sqldb.NewBeginner(db), userdb.NewStore(log, db))
+
+	// Existing users keep their password, so only newly created users
+	// need an invitation.
+	created := make(map[string]bool)
+
+	var imported int
+	for start := 0; start < len(nus); start += cfg.BatchSize {
+		end := min(start+cfg.BatchSize, len(nus))
+
+		usrs, err := core.Import(ctx, nus[start:end])
+		if err != nil {
+			return fmt.Errorf("import: imported[%d]: %w", imported, err)
+		}
+		imported += len(usrs)
+
+		for _, usr := range usrs {
+			if usr.Version == 1 {
+				created[strings.ToLower(usr.Email.Address)] = true
+			}
+		}
+	}
+
+	fmt.Printf("users imported: %d, created: %d, updated: %d\n", imported, len(created), imported-len(created))
+
+	// -------------------------------------------------------------------------
+
+	invites = slices.DeleteFunc(invites, func(app importUser) bool {
+		return !created[strings.ToLower(app.Email)]
+	})
+
+	if len(invites) > 0 {
+		file := cfg.File + ".invitations.csv"
+		if err := writeInvitations(file, invites); err != nil {
+			return fmt.Errorf("write invitations: %w", err)
+		}
+
+		fmt.Printf("invitations written to %s\n", file)
+	}
+
+	return nil
+}
+
+// validateRows applies the same rules used by the user api to every row and
+// converts the valid ones. A row reusing the email of an earlier row is
+// rejected since the batch insert can only touch a user once.
+func validateRows(rows []importRow) ([]user.NewUser, []importUser, []importError) {
+	var nus []user.NewUser
+	var invites []importUser
+	var rowErrs []importError
+
+	seen := make(map[string]int)
+
+	for _, row := range rows {
+		if err := row.app.Validate(); err != nil {
+			fe := validate.GetFieldErrors(err)
+			if fe == nil {
+				rowErrs = append(rowErrs, importError{line: row.line, email: row.app.Email, err: err.Error()})
+				continue
+			}
+
+			for _, fld := range fe {
+				rowErrs = append(rowErrs, importError{line: row.line, email: row.app.Email, field: fld.Field, err: fld.Err})
+			}
+			continue
+		}
+
+		nu, field, err := toCoreNewUser(row.app)
+		if err != nil {
+			rowErrs = append(rowErrs, importError{line: row.line, email: row.app.Email, field: field, err: err.Error()})
+			continue
+		}
+
+		email := strings.ToLower(nu.Email.Address)
+		if line, exists := seen[email]; exists {
+			rowErrs = append(rowErrs, importError{line: row.line, email: row.app.Email, field: "email", err: fmt.Sprintf("duplicate of line %d", line)})
+			continue
+		}
+		seen[email] = row.line
+
+		nus = append(nus, nu)
+		if row.invite {
+			invites = append(invites, row.app)
+		}
+	}
+
+	return nus, invites, rowErrs
+}
+
+func toCoreNewUser(app importUser) (user.NewUser, string, error) {
+	roles := make([]user.Role, len(app.Roles))
+	for i, roleStr := range app.Roles {
+		role, err := user.ParseRole(roleStr)
+		if err != nil {
+			return user.NewUser{}, "roles", err
+		}
+		roles[i] = role
+	}
+
+	addr, err := mail.ParseAddress(app.Email)
+	if err != nil {
+		return user.NewUser{}, "email", err
+	}
+
+	nu := user.NewUser{
+		Name:            app.Name,
+		Email:           *addr,
+		Roles:           roles,
+		Department:      app.Department,
+		Password:        app.Password,
+		PasswordConfirm: app.PasswordConfirm,
+	}
+
+	return nu, "", nil
+}
+
+// =============================================================================
+
+// readImportFile reads the rows from the file using the format identified
+// by the file extension.
+func readImportFile(file string) ([]importRow, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var rows []importRow
+
+	switch ext := strings.ToLower(filepath.Ext(file)); ext {
+	case ".csv":
+		rows, err = readCSV(f)
+	case ".ndjson", ".jsonl":
+		rows, err = readNDJSON(f)
+	default:
+		return nil, fmt.Errorf("unsupported file extension %q", ext)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range rows {
+		if err := prepareRow(&rows[i]); err != nil {
+			return nil, err
+		}
+	}
+
+	return rows, nil
+}
+
+// readCSV reads rows with a header naming the columns name, email, roles,
+// department and password. Multiple roles are separated by a semicolon.
+func readCSV(r io.Reader) ([]importRow, error) {
+	cr := csv.NewReader(r)
+	cr.TrimLeadingSpace = true
+
+	header, err := cr.Read()
+	if err != nil {
+		return nil, fmt.Errorf("read header: %w", err)
+	}
+
+	cols := make(map[string]int)
+	for i, name := range header {
+		cols[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+
+	for _, name := range []string{"name", "email", "roles"} {
+		if _, exists := cols[name]; !exists {
+			return nil, fmt.Errorf("header missing column %q", name)
+		}
+	}
+
+	field := func(record []string, name string) string {
+		i, exists := cols[name]
+		if !exists || i >= len(record) {
+			return ""
+		}
+		return strings.TrimSpace(record[i])
+	}
+
+	var rows []importRow
+	for {
+		record, err := cr.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		line, _ := cr.FieldPos(0)
+
+		var roles []string
+		for _, role := range strings.Split(field(record, "roles"), ";") {
+			if role = strings.TrimSpace(role); role != "" {
+				roles = append(roles, role)
+			}
+		}
+
+		row := importRow{
+			line: line,
+			app: importUser{
+				Name:       field(record, "name"),
+				Email:      field(record, "email"),
+				Roles:      roles,
+				Department: field(record, "department"),
+				Password:   field(record, "password"),
+			},
+		}
+
+		rows = append(rows, row)
+	}
+
+	return rows, nil
+}
+
+// readNDJSON reads one json document per line using the same fields as the
+// user api. Blank lines are skipped.
+func readNDJSON(r io.Reader) ([]importRow, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+
+	var rows []importRow
+	for line := 1; scanner.Scan(); line++ {
+		data := strings.TrimSpace(scanner.Text())
+		if data == "" {
+			continue
+		}
+
+		var app importUser
+		if err := json.Unmarshal([]byte(data), &app); err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+
+		rows = append(rows, importRow{line: line, app: app})
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return rows, nil
+}
+
+// prepareRow generates a password for rows that don't provide one and fills
+// in the confirmation since import files only carry a single password.
+func prepareRow(row *importRow) error {
+	if row.app.Password == "" {
+		pass, err := generatePassword()
+		if err != nil {
+			return fmt.Errorf("generate password: %w", err)
+		}
+
+		row.app.Password = pass
+		row.app.PasswordConfirm = pass
+		row.invite = true
+	}
+
+	if row.app.PasswordConfirm == "" {
+		row.app.PasswordConfirm = row.app.Password
+	}
+
+	return nil
+}
+
+func generatePassword() (string, error) {
+	b := make([]byte, 18)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// =============================================================================
+
+// writeReport writes the rejected rows to the report file. Any report from
+// a previous run is removed when there are no errors.
+func writeReport(file string, rowErrs []importError) error {
+	if len(rowErrs) == 0 {
+		if err := os.Remove(file); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+		return nil
+	}
+
+	records := [][]string{{"line", "email", "field", "error"}}
+	for _, re := range rowErrs {
+		records = append(records, []string{strconv.Itoa(re.line), re.email, re.field, re.err})
+	}
+
+	return writeCSV(file, records)
+}
+
+// writeInvitations writes the generated passwords so the users can be
+// invited to sign in and change them.
+func writeInvitations(file string, invites []importUser) error {
+	records := [][]string{{"email", "name", "temporary_password"}}
+	for _, app := range invites {
+		records = append(records, []string{app.Email, app.Name, app.Password})
+	}
+
+	return writeCSV(file, records)
+}
+
+func writeCSV(file string, records [][]string) error {
+	f, err := os.OpenFile(file, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0600)
+	if err != nil {
+		return err
+	}
+
+	if err := csv.NewWriter(f).WriteAll(records); err != nil {
+		f.Close()
+		return err
+	}
+
+	return f.Close()
+}
@@ -0,0 +1,176 @@
+package commands
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func Test_UserImport(t *testing.T) {
+	t.Run("csv", csvRows)
+	t.Run("csvErrors", csvErrors)
+	t.Run("ndjson", ndjsonRows)
+	t.Run("validate", validateImportRows)
+}
+
+// =============================================================================
+
+func csvRows(t *testing.T) {
+	const data = `Name, EMAIL ,roles,department,password
+Bill Kennedy, bill@example.com, ADMIN;USER , IT, secret
+"Doe, Jane",jane@example.com,USER,,
+
+Ed,ed@example.com,;USER;,Sales,
+`
+
+	rows, err := readCSV(strings.NewReader(data))
+	if err != nil {
+		t.Fatalf("Should be able to read the rows : %s.", err)
+	}
+
+	exp := []importRow{
+		{line: 2, app: importUser{Name: "Bill Kennedy", Email: "bill@example.com", Roles: []string{"ADMIN", "USER"}, Department: "IT", Password: "secret"}},
+		{line: 3, app: importUser{Name: "Doe, Jane", Email: "jane@example.com", Roles: []string{"USER"}}},
+		{line: 5, app: importUser{Name: "Ed", Email: "ed@example.com", Roles: []string{"USER"}, Department: "Sales"}},
+	}
+
+	if diff := cmp.Diff(rows, exp, cmp.AllowUnexported(importRow{})); diff != "" {
+		t.Errorf("Should get the rows with their line. Diff:\n%s", diff)
+	}
+}
+
+func csvErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+		err  string
+	}{
+		{name: "empty", data: "", err: "read header"},
+		{name: "missingColumn", data: "name,roles\nBill,USER\n", err: `header missing column "email"`},
+		{name: "badQuote", data: "name,email,roles\n\"Bill,bill@example.com,USER\n", err: "extraneous or missing"},
+	}
+
+	for _, tt := range tests {
+		_, err := readCSV(strings.NewReader(tt.data))
+		if err == nil || !strings.Contains(err.Error(), tt.err) {
+			t.Errorf("%s: Should get the expected error : got %v, exp %q.", tt.name, err, tt.err)
+		}
+	}
+}
+
+func ndjsonRows(t *testing.T) {
+	const data = `{"name":"Bill Kennedy","email":"bill@example.com","roles":["ADMIN","USER"],"department":"IT","password":"secret","passwordConfirm":"secret"}
+
+{"name":"Jane","email":"jane@example.com","roles":["USER"]}
+`
+
+	rows, err := readNDJSON(strings.NewReader(data))
+	if err != nil {
+		t.Fatalf("Should be able to read the rows : %s.", err)
+	}
+
+	exp := []importRow{
+		{line: 1, app: importUser{Name: "Bill Kennedy", Email: "bill@example.com", Roles: []string{"ADMIN", "USER"}, Department: "IT", Password: "secret", PasswordConfirm: "secret"}},
+		{line: 3, app: importUser{Name: "Jane", Email: "jane@example.com", Roles: []string{"USER"}}},
+	}
+
+	if diff := cmp.Diff(rows, exp, cmp.AllowUnexported(importRow{})); diff != "" {
+		t.Errorf("Should get the rows with their line. Diff:\n%s", diff)
+	}
+
+	_, err = readNDJSON(strings.NewReader(`{"name":"Bill"}` + "\n" + `{"name":`))
+	if err == nil || !strings.HasPrefix(err.Error(), "line 2:") {
+		t.Errorf("Should report the line of an invalid document : %v.", err)
+	}
+
+	// -------------------------------------------------------------------------
+
+	row := importRow{app: importUser{Name: "Jane"}}
+	if err := prepareRow(&row); err != nil {
+		t.Fatalf("Should be able to prepare the row : %s.", err)
+	}
+
+	if !row.invite || row.app.Password == "" || row.app.PasswordConfirm != row.app.Password {
+		t.Errorf("Should generate a password for a row without one : %+v.", row)
+	}
+
+	row = importRow{app: importUser{Name: "Bill", Password: "secret"}}
+	if err := prepareRow(&row); err != nil {
+		t.Fatalf("Should be able to prepare the row : %s.", err)
+	}
+
+	if row.invite || row.app.PasswordConfirm != "secret" {
+		t.Errorf("Should confirm the password of a row with one : %+v.", row)
+	}
+}
+
+func validateImportRows(t *testing.T) {
+	valid := func(line int, email string) importRow {
+		return importRow{
+			line: line,
+			app: importUser{
+				Name:            "User",
+				Email:           email,
+				Roles:           []string{"USER"},
+				Password:        "secret",
+				PasswordConfirm: "secret",
+			},
+		}
+	}
+
+	invited := valid(2, "bill@example.com")
+	invited.invite = true
+
+	missingName := valid(4, "jane@example.com")
+	missingName.app.Name = ""
+
+	badRole := valid(5, "ed@example.com")
+	badRole.app.Roles = []string{"OWNER"}
+
+	mismatch := valid(6, "sam@example.com")
+	mismatch.app.PasswordConfirm = "other"
+
+	rows := []importRow{
+		invited,
+		valid(3, "ann@example.com"),
+		missingName,
+		badRole,
+		mismatch,
+		valid(7, "BILL@example.com"),
+		valid(8, "not-an-email"),
+	}
+
+	nus, invites, rowErrs := validateRows(rows)
+
+	if len(nus) != 2 || nus[0].Email.Address != "bill@example.com" || nus[1].Email.Address != "ann@example.com" {
+		t.Errorf("Should convert the valid rows : %+v.", nus)
+	}
+
+	if len(invites) != 1 || invites[0].Email != "bill@example.com" {
+		t.Errorf("Should only invite the rows with a generated password : %+v.", invites)
+	}
+
+	exp := []struct {
+		line  int
+		field string
+		err   string
+	}{
+		{line: 4, field: "name", err: "required"},
+		{line: 5, field: "roles", err: "invalid role"},
+		{line: 6, field: "passwordConfirm", err: "Password"},
+		{line: 7, field: "email", err: "duplicate of line 2"},
+		{line: 8, field: "email", err: "email"},
+	}
+
+	if len(rowErrs) != len(exp) {
+		t.Fatalf("Should get an error for every invalid row : got %d, exp %d : %+v.", len(rowErrs), len(exp), rowErrs)
+	}
+
+	for i, e := range exp {
+		re := rowErrs[i]
+		if re.line != e.line || re.field != e.field || !strings.Contains(re.err, e.err) {
+			t.Errorf("Should get the expected error : got %+v, exp %+v.", re, e)
+		}
+	}
+}
@@ -15,8 +15,11 @@ import (
 	"os"
 	"time"
 
+	"github.com/1core-dev/go-service/app/tooling/sales-admin/commands"
+	"github.com/1core-dev/go-service/business/data/audit"
 	"github.com/1core-dev/go-service/business/data/dbmigrate"
 	sqldb "github.com/1core-dev/go-service/business/data/dbsql/pgx"
+	"github.com/1core-dev/go-service/pkg/logger"
 	"github.com/ardanlabs/conf/v3"
 	"github.com/golang-jwt/jwt/v5"
 	"github.com/open-policy-agent/opa/rego"
@@ -29,16 +32,15 @@ var (
 )
 
 func main() {
-	err := migrateSeed()
-
-	if err != nil {
+	if err := run(); err != nil {
 		log.Fatalln(err)
 	}
 }
 
-func migrateSeed() error {
+func run() error {
 	var cfg struct {
-		DB struct {
+		Args conf.Args
+		DB   struct {
 			User         string `conf:"default:postgres"`
 			Password     string `conf:"default:postgres,mask"`
 			Host         string `conf:"default:database-service.sales-system.svc.cluster.local"`
@@ -70,6 +72,33 @@ func migrateSeed() error {
 		DisableTLS:   cfg.DB.DisableTLS,
 	}
 
+	switch cfg.Args.Num(0) {
+	case "audit":
+		switch cfg.Args.Num(1) {
+		case "verify":
+			return auditVerify(dbConfig)
+		default:
+			return fmt.Errorf("unknown audit command %q", cfg.Args.Num(1))
+		}
+
+	case "users":
+		switch cfg.Args.Num(1) {
+		case "import":
+			importCfg, err := commands.ParseUserImportConfig(cfg.Args[2:])
+			if err != nil {
+				return fmt.Errorf("parsing import config: %w", err)
+			}
+			return commands.UserImport(dbConfig, importCfg)
+		default:
+			return fmt.Errorf("unknown users command %q", cfg.Args.Num(1))
+		}
+
+	default:
+		return migrateSeed(dbConfig)
+	}
+}
+
+func migrateSeed(dbConfig sqldb.Config) error {
 	db, err := sqldb.Open(dbConfig)
 	if err != nil {
 		return fmt.Errorf("connect database: %w", err)
@@ -95,6 +124,34 @@ func migrateSeed() error {
 	return nil
 }
 
+// auditVerify walks the audit log chain and reports the first break.
+func auditVerify(dbConfig sqldb.Config) error {
+	db, err := sqldb.Open(dbConfig)
+	if err != nil {
+		return fmt.Errorf("connect database: %w", err)
+	}
+	defer db.Close()
+
+	ctx := context.Background()
+
+	if err := sqldb.StatusCheck(ctx, db); err != nil {
+		return fmt.Errorf("status check database: %w", err)
+	}
+
+	log := logger.New(io.Discard, logger.LevelInfo, "SALES-ADMIN", nil)
+
+	verified, err := audit.New(log, db).Verify(ctx)
+	if err != nil {
+		if audit.IsChainError(err) {
+			fmt.Printf("audit chain broken after %d verified entries\n", verified)
+		}
+		return fmt.Errorf("verify audit log: %w", err)
+	}
+
+	fmt.Printf("audit chain verified: %d entries\n", verified)
+	return nil
+}
+
 func gentoken() error {
 
 	// Generate a new private key.
@@ -1,21 +1,42 @@
 package user
 
 import (
+	"errors"
 	"fmt"
 	"net/mail"
 	"time"
 
+	"github.com/1core-dev/go-service/business/data/filter"
 	"github.com/1core-dev/go-service/pkg/validate"
 	"github.com/google/uuid"
 )
 
-// QueryFilter holds the available fields a query can be filtered on.
+// FilterSchema describes the fields and types a filter expression can use.
+var FilterSchema = filter.Schema{
+	"user_id":      filter.UUID,
+	"name":         filter.String,
+	"email":        filter.String,
+	"department":   filter.String,
+	"enabled":      filter.Bool,
+	"roles":        filter.StringArray,
+	"date_created": filter.Time,
+	"date_updated": filter.Time,
+}
+
+// QueryFilter holds the available fields a query can be filtered on. The
+// Expression is combined with the other fields using AND.
 type QueryFilter struct {
 	ID               *uuid.UUID    `validate:"omitempty"`
 	Name             *string       `validate:"omitempty,min=3"`
 	Email            *mail.Address `validate:"omitempty"`
 	StartCreatedDate *time.Time    `validate:"omitempty"`
 	EndCreatedDate   *time.Time    `validate:"omitempty"`
+	StartUpdatedDate *time.Time    `validate:"omitempty"`
+	EndUpdatedDate   *time.Time    `validate:"omitempty"`
+	Roles            []Role        `validate:"omitempty"`
+	Departments      []string      `validate:"omitempty,max=20,dive,required"`
+	Enabled          *bool         `validate:"omitempty"`
+	Expression       filter.Expr   `validate:"-"`
 }
 
 // Validate checks the data in the model is considered clean.
@@ -23,6 +44,14 @@ func (qf *QueryFilter) Validate() error {
 	if err := validate.Check(qf); err != nil {
 		return fmt.Errorf("validate: %w", err)
 	}
+
+	if qf.StartCreatedDate != nil && qf.EndCreatedDate != nil && qf.StartCreatedDate.After(*qf.EndCreatedDate) {
+		return validate.NewFieldsError("start_created_date", errors.New("must not be after the end created date"))
+	}
+
+	if qf.StartUpdatedDate != nil && qf.EndUpdatedDate != nil && qf.StartUpdatedDate.After(*qf.EndUpdatedDate) {
+		return validate.NewFieldsError("start_updated_date", errors.New("must not be after the end updated date"))
+	}
 	return nil
 }
 
@@ -47,8 +76,42 @@ func (qf *QueryFilter) WithStartDateCreated(startDate time.Time) {
 	qf.StartCreatedDate = &d
 }
 
+// WithExpression sets the Expression field of the QueryFilter value.
+func (qf *QueryFilter) WithExpression(expr filter.Expr) {
+	qf.Expression = expr
+}
+
 // WithEndCreatedDate sets the DateCreated field of the QueryFilter value.
 func (qf *QueryFilter) WithEndCreatedDate(endDate time.Time) {
 	d := endDate.UTC()
 	qf.EndCreatedDate = &d
 }
+
+// WithStartUpdatedDate sets the StartUpdatedDate field of the QueryFilter value.
+func (qf *QueryFilter) WithStartUpdatedDate(startDate time.Time) {
+	d := startDate.UTC()
+	qf.StartUpdatedDate = &d
+}
+
+// WithEndUpdatedDate sets the EndUpdatedDate field of the QueryFilter value.
+func (qf *QueryFilter) WithEndUpdatedDate(endDate time.Time) {
+	d := endDate.UTC()
+	qf.EndUpdatedDate = &d
+}
+
+// WithRoles sets the Roles field of the QueryFilter value. Users must have
+// every one of the roles to match.
+func (qf *QueryFilter) WithRoles(roles []Role) {
+	qf.Roles = roles
+}
+
+// WithDepartments sets the Departments field of the QueryFilter value. Users
+// in any of the departments match.
+func (qf *QueryFilter) WithDepartments(departments []string) {
+	qf.Departments = departments
+}
+
+// WithEnabled sets the Enabled field of the QueryFilter value.
+func (qf *QueryFilter) WithEnabled(enabled bool) {
+	qf.Enabled = &enabled
+}
@@ -0,0 +1,142 @@
+package user
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/1core-dev/go-service/business/data/actor"
+	"github.com/1core-dev/go-service/pkg/web"
+	"github.com/google/uuid"
+)
+
+// Set of actions recorded in the user history.
+const (
+	ActionCreate = "CREATE"
+	ActionUpdate = "UPDATE"
+	ActionDelete = "DELETE"
+	ActionImport = "IMPORT"
+)
+
+// History represents a single recorded change made to a user.
+type History struct {
+	ID          uuid.UUID
+	UserID      uuid.UUID
+	Action      string
+	Diff        map[string]Change
+	Actor       string
+	TraceID     string
+	DateCreated time.Time
+}
+
+// Change represents the before and after value of a single user field.
+type Change struct {
+	Before any `json:"before"`
+	After  any `json:"after"`
+}
+
+// newHistory constructs a history entry for the specified action by
+// diffing the before and after state of the user. The actor and trace id
+// are extracted from the context.
+func newHistory(ctx context.Context, action string, before User, after User) History {
+	subject, _ := actor.Get(ctx)
+
+	userID := after.ID
+	if userID == uuid.Nil {
+		userID = before.ID
+	}
+
+	return History{
+		ID:          uuid.New(),
+		UserID:      userID,
+		Action:      action,
+		Diff:        diff(before, after),
+		Actor:       subject,
+		TraceID:     web.GetTraceID(ctx),
+		DateCreated: time.Now(),
+	}
+}
+
+// historyFields is the set of user fields tracked in the history.
+var historyFields = []string{"name", "email", "roles", "department", "enabled"}
+
+// diff returns the set of fields that changed between the two users. The
+// password hash is never included, only the fact that it changed.
+func diff(before User, after User) map[string]Change {
+	b := snapshot(before)
+	a := snapshot(after)
+
+	changes := make(map[string]Change)
+	for _, field := range historyFields {
+		bv, av := b[field], a[field]
+
+		switch av := av.(type) {
+		case []string:
+			bv, _ := bv.([]string)
+			if slices.Equal(av, bv) {
+				continue
+			}
+		default:
+			if av == bv {
+				continue
+			}
+		}
+
+		changes[field] = Change{Before: bv, After: av}
+	}
+
+	if string(before.PasswordHash) != string(after.PasswordHash) {
+		changes["password"] = Change{Before: masked(before.PasswordHash), After: masked(after.PasswordHash)}
+	}
+
+	return changes
+}
+
+// snapshot converts the user into the set of fields tracked in the history.
+// The zero value of a user produces no fields so a create is recorded with
+// no before state and a delete with no after state.
+func snapshot(usr User) map[string]any {
+	if usr.ID == uuid.Nil {
+		return nil
+	}
+
+	roles := make([]string, len(usr.Roles))
+	for i, role := range usr.Roles {
+		roles[i] = role.Name()
+	}
+
+	return map[string]any{
+		"name":       usr.Name,
+		"email":      usr.Email.Address,
+		"roles":      roles,
+		"department": usr.Department,
+		"enabled":    usr.Enabled,
+	}
+}
+
+// masked hides the password hash while still recording if one was set.
+func masked(hash []byte) any {
+	if len(hash) == 0 {
+		return nil
+	}
+	return "*"
+}
+
+// =============================================================================
+
+// QueryHistory retrieves the list of recorded changes for the specified user.
+func (c *Core) QueryHistory(ctx context.Context, userID uuid.UUID, pageNumber int, rowsPerPage int) ([]History, error) {
+	hist, err := c.storer.QueryHistory(ctx, userID, pageNumber, rowsPerPage)
+	if err != nil {
+		return nil, fmt.Errorf("query: userID[%s]: %w", userID, err)
+	}
+
+	return hist, nil
+}
+
+// CountHistory returns the total number of recorded changes for the
+// specified user.
+func (c *Core) CountHistory(ctx context.Context, userID uuid.UUID) (int, error) {
+	return c.storer.CountHistory(ctx, userID)
+}
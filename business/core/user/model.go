@@ -16,6 +16,7 @@ type User struct {
 	PasswordHash []byte
 	Department   string
 	Enabled      bool
+	Version      int
 	DateCreated  time.Time
 	DateUpdated  time.Time
 }
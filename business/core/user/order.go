@@ -1,6 +1,11 @@
 package user
 
-import "github.com/1core-dev/go-service/business/data/order"
+import (
+	"strconv"
+	"strings"
+
+	"github.com/1core-dev/go-service/business/data/order"
+)
 
 // DefaultOrderBy represents the default way we sort.
 var DefaultOrderBy = order.NewBy(OrderByID, order.ASC)
@@ -8,9 +13,56 @@ var DefaultOrderBy = order.NewBy(OrderByID, order.ASC)
 // Set of fields that the results can be ordered by. These are the names
 // that should be used by the application layer.
 const (
-	OrderByID      = "user_id"
-	OrderByName    = "name"
-	OrderByEmail   = "email"
-	OrderByRoles   = "roles"
-	OrderByEnabled = "enabled"
+	OrderByID         = "user_id"
+	OrderByName       = "name"
+	OrderByEmail      = "email"
+	OrderByRoles      = "roles"
+	OrderByEnabled    = "enabled"
+	OrderByDepartment = "department"
+)
+
+// Set of fields that can be selected in a query. These are the names that
+// should be used by the application layer.
+const (
+	FieldID          = "user_id"
+	FieldName        = "name"
+	FieldEmail       = "email"
+	FieldRoles       = "roles"
+	FieldDepartment  = "department"
+	FieldEnabled     = "enabled"
+	FieldDateCreated = "date_created"
+	FieldDateUpdated = "date_updated"
 )
+
+// orderKeys returns the value of every order by field for the specified
+// user as they're recorded in a page cursor.
+func orderKeys(usr User, orderBy order.List) []string {
+	keys := make([]string, len(orderBy))
+	for i, by := range orderBy {
+		keys[i] = orderKey(usr, by.Field)
+	}
+
+	return keys
+}
+
+// orderKey returns the value of the order by field for the specified user.
+func orderKey(usr User, field string) string {
+	switch field {
+	case OrderByName:
+		return usr.Name
+	case OrderByEmail:
+		return usr.Email.Address
+	case OrderByRoles:
+		roles := make([]string, len(usr.Roles))
+		for i, role := range usr.Roles {
+			roles[i] = role.Name()
+		}
+		return strings.Join(roles, ",")
+	case OrderByEnabled:
+		return strconv.FormatBool(usr.Enabled)
+	case OrderByDepartment:
+		return usr.Department
+	default:
+		return usr.ID.String()
+	}
+}
@@ -0,0 +1,39 @@
+package user
+
+import (
+	"context"
+	"fmt"
+)
+
+// Set of markers placed around the matching terms of a search highlight.
+// They are control characters so they can't be confused with user data.
+const (
+	HighlightStart = "\x02"
+	HighlightStop  = "\x03"
+)
+
+// SearchResult represents a user matching a search along with how relevant
+// the match is and the name and email with the matching terms marked.
+type SearchResult struct {
+	User           User
+	Rank           float64
+	NameHighlight  string
+	EmailHighlight string
+}
+
+// Search retrieves the users best matching the query. Words are matched by
+// prefix against the name, email and department and misspelled words are
+// matched by similarity. Results are ordered by relevance.
+func (c *Core) Search(ctx context.Context, query string, pageNumber int, rowsPerPage int) ([]SearchResult, error) {
+	results, err := c.storer.Search(ctx, query, pageNumber, rowsPerPage)
+	if err != nil {
+		return nil, fmt.Errorf("search: %w", err)
+	}
+
+	return results, nil
+}
+
+// CountSearch returns the total number of users matching the query.
+func (c *Core) CountSearch(ctx context.Context, query string) (int, error) {
+	return c.storer.CountSearch(ctx, query)
+}
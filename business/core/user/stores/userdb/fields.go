@@ -0,0 +1,49 @@
+package userdb
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/1core-dev/go-service/business/core/user"
+)
+
+// allColumns is the column list used when no fields are selected.
+const allColumns = "user_id, name, email, password_hash, roles, enabled, department, version, date_created, date_updated"
+
+// fieldColumns whitelists the columns that can be selected. Only these
+// values are ever written into a query.
+var fieldColumns = map[string]string{
+	user.FieldID:          "user_id",
+	user.FieldName:        "name",
+	user.FieldEmail:       "email",
+	user.FieldRoles:       "roles",
+	user.FieldDepartment:  "department",
+	user.FieldEnabled:     "enabled",
+	user.FieldDateCreated: "date_created",
+	user.FieldDateUpdated: "date_updated",
+}
+
+// selectColumns returns the column list for the selected fields. The user
+// id is always selected.
+func selectColumns(fields []string) (string, error) {
+	if len(fields) == 0 {
+		return allColumns, nil
+	}
+
+	columns := []string{"user_id"}
+	seen := map[string]bool{"user_id": true}
+
+	for _, field := range fields {
+		column, exists := fieldColumns[field]
+		if !exists {
+			return "", fmt.Errorf("field %q does not exist", field)
+		}
+
+		if !seen[column] {
+			columns = append(columns, column)
+			seen[column] = true
+		}
+	}
+
+	return strings.Join(columns, ", "), nil
+}
@@ -6,9 +6,38 @@ import (
 	"strings"
 
 	"github.com/1core-dev/go-service/business/core/user"
+	"github.com/1core-dev/go-service/business/data/dbsql/pgx/dbarray"
+	"github.com/1core-dev/go-service/business/data/filter"
 )
 
-func (s *Store) applyFilter(filter user.QueryFilter, data map[string]interface{}, buf *bytes.Buffer) {
+// filterColumns maps the fields of the user filter schema to the columns
+// used when compiling a filter expression.
+var filterColumns = map[string]string{
+	"user_id":      "user_id",
+	"name":         "name",
+	"email":        "email",
+	"department":   "COALESCE(department, '')",
+	"enabled":      "enabled",
+	"roles":        "roles",
+	"date_created": "date_created",
+	"date_updated": "date_updated",
+}
+
+func (s *Store) applyFilter(filter user.QueryFilter, data map[string]interface{}, buf *bytes.Buffer) error {
+	wc, err := filterClauses(filter, data)
+	if err != nil {
+		return err
+	}
+
+	if len(wc) > 0 {
+		buf.WriteString(" WHERE ")
+		buf.WriteString(strings.Join(wc, " AND "))
+	}
+
+	return nil
+}
+
+func filterClauses(filter user.QueryFilter, data map[string]interface{}) ([]string, error) {
 	var wc []string
 
 	if filter.ID != nil {
@@ -36,8 +65,51 @@ func (s *Store) applyFilter(filter user.QueryFilter, data map[string]interface{}
 		wc = append(wc, "date_created <= :end_date_created")
 	}
 
-	if len(wc) > 0 {
-		buf.WriteString(" WHERE ")
-		buf.WriteString(strings.Join(wc, " AND "))
+	if filter.StartUpdatedDate != nil {
+		data["start_date_updated"] = *filter.StartUpdatedDate
+		wc = append(wc, "date_updated >= :start_date_updated")
 	}
+
+	if filter.EndUpdatedDate != nil {
+		data["end_date_updated"] = *filter.EndUpdatedDate
+		wc = append(wc, "date_updated <= :end_date_updated")
+	}
+
+	if len(filter.Roles) > 0 {
+		roles := make(dbarray.String, len(filter.Roles))
+		for i, role := range filter.Roles {
+			roles[i] = role.Name()
+		}
+		data["roles"] = roles
+		wc = append(wc, "roles @> :roles")
+	}
+
+	if len(filter.Departments) > 0 {
+		data["departments"] = dbarray.String(filter.Departments)
+		wc = append(wc, "department = ANY(:departments)")
+	}
+
+	if filter.Enabled != nil {
+		data["enabled"] = *filter.Enabled
+		wc = append(wc, "enabled = :enabled")
+	}
+
+	if filter.Expression != nil {
+		clause, err := expressionClause(filter.Expression, data)
+		if err != nil {
+			return nil, err
+		}
+		wc = append(wc, clause)
+	}
+
+	return wc, nil
+}
+
+func expressionClause(expr filter.Expr, data map[string]interface{}) (string, error) {
+	clause, err := filter.SQL(expr, filterColumns, data)
+	if err != nil {
+		return "", fmt.Errorf("filter expression: %w", err)
+	}
+
+	return clause, nil
 }
@@ -0,0 +1,149 @@
+package userdb
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/1core-dev/go-service/business/core/user"
+	db "github.com/1core-dev/go-service/business/data/dbsql/pgx"
+	"github.com/google/uuid"
+)
+
+// dbHistory represent the structure we need for moving user history
+// between the app and the database.
+type dbHistory struct {
+	ID          uuid.UUID `db:"history_id"`
+	UserID      uuid.UUID `db:"user_id"`
+	Action      string    `db:"action"`
+	Diff        []byte    `db:"diff" log:"redact"`
+	Actor       string    `db:"actor"`
+	TraceID     string    `db:"trace_id"`
+	DateCreated time.Time `db:"date_created"`
+}
+
+func toDBHistory(h user.History) (dbHistory, error) {
+	diff, err := json.Marshal(h.Diff)
+	if err != nil {
+		return dbHistory{}, fmt.Errorf("marshal diff: %w", err)
+	}
+
+	return dbHistory{
+		ID:          h.ID,
+		UserID:      h.UserID,
+		Action:      h.Action,
+		Diff:        diff,
+		Actor:       h.Actor,
+		TraceID:     h.TraceID,
+		DateCreated: h.DateCreated.UTC(),
+	}, nil
+}
+
+func toCoreHistory(dbHist dbHistory) (user.History, error) {
+	var diff map[string]user.Change
+	if err := json.Unmarshal(dbHist.Diff, &diff); err != nil {
+		return user.History{}, fmt.Errorf("unmarshal diff: %w", err)
+	}
+
+	h := user.History{
+		ID:          dbHist.ID,
+		UserID:      dbHist.UserID,
+		Action:      dbHist.Action,
+		Diff:        diff,
+		Actor:       dbHist.Actor,
+		TraceID:     dbHist.TraceID,
+		DateCreated: dbHist.DateCreated.In(time.Local),
+	}
+
+	return h, nil
+}
+
+func toCoreHistorySlice(dbHists []dbHistory) ([]user.History, error) {
+	hists := make([]user.History, len(dbHists))
+	for i, dbHist := range dbHists {
+		var err error
+		hists[i], err = toCoreHistory(dbHist)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return hists, nil
+}
+
+// =============================================================================
+
+// CreateHistory inserts a new user history record into the database.
+func (s *Store) CreateHistory(ctx context.Context, h user.History) error {
+	const q = `
+	INSERT INTO user_history
+		(history_id, user_id, action, diff, actor, trace_id, date_created)
+	VALUES
+		(:history_id, :user_id, :action, :diff, :actor, :trace_id, :date_created)`
+
+	dbHist, err := toDBHistory(h)
+	if err != nil {
+		return err
+	}
+
+	if err := db.NamedExecContext(ctx, s.log, s.db, q, dbHist); err != nil {
+		return fmt.Errorf("namedexeccontext: %w", err)
+	}
+
+	return nil
+}
+
+// QueryHistory retrieves the recorded changes for the specified user from
+// the database, most recent first.
+func (s *Store) QueryHistory(ctx context.Context, userID uuid.UUID, pageNumber int, rowsPerPage int) ([]user.History, error) {
+	data := map[string]interface{}{
+		"user_id":       userID,
+		"offset":        (pageNumber - 1) * rowsPerPage,
+		"rows_per_page": rowsPerPage,
+	}
+
+	const q = `
+	SELECT
+		history_id, user_id, action, diff, actor, trace_id, date_created
+	FROM
+		user_history
+	WHERE
+		user_id = :user_id
+	ORDER BY
+		date_created DESC, history_id
+	OFFSET :offset ROWS FETCH NEXT :rows_per_page ROWS ONLY`
+
+	var dbHists []dbHistory
+	if err := db.NamedQuerySlice(ctx, s.log, s.db, q, data, &dbHists); err != nil {
+		return nil, fmt.Errorf("namedqueryslice: %w", err)
+	}
+
+	return toCoreHistorySlice(dbHists)
+}
+
+// CountHistory returns the total number of recorded changes for the
+// specified user in the DB.
+func (s *Store) CountHistory(ctx context.Context, userID uuid.UUID) (int, error) {
+	data := struct {
+		UserID string `db:"user_id"`
+	}{
+		UserID: userID.String(),
+	}
+
+	const q = `
+	SELECT
+		count(1)
+	FROM
+		user_history
+	WHERE
+		user_id = :user_id`
+
+	var count struct {
+		Count int `db:"count"`
+	}
+	if err := db.NamedQueryStruct(ctx, s.log, s.db, q, data, &count); err != nil {
+		return 0, fmt.Errorf("namedquerystruct: %w", err)
+	}
+
+	return count.Count, nil
+}
@@ -16,11 +16,12 @@ import (
 type dbUser struct {
 	ID           uuid.UUID      `db:"user_id"`
 	Name         string         `db:"name"`
-	Email        string         `db:"email"`
+	Email        string         `db:"email" log:"redact"`
 	Roles        dbarray.String `db:"roles"`
-	PasswordHash []byte         `db:"password_hash"`
+	PasswordHash []byte         `db:"password_hash" log:"redact"`
 	Department   sql.NullString `db:"department"`
 	Enabled      bool           `db:"enabled"`
+	Version      int            `db:"version"`
 	DateCreated  time.Time      `db:"date_created"`
 	DateUpdated  time.Time      `db:"date_updated"`
 }
@@ -42,6 +43,7 @@ func toDBUser(usr user.User) dbUser {
 			Valid:  usr.Department != "",
 		},
 		Enabled:     usr.Enabled,
+		Version:     usr.Version,
 		DateCreated: usr.DateCreated.UTC(),
 		DateUpdated: usr.DateUpdated.UTC(),
 	}
@@ -69,6 +71,7 @@ func toCoreUser(dbUsr dbUser) (user.User, error) {
 		PasswordHash: dbUsr.PasswordHash,
 		Enabled:      dbUsr.Enabled,
 		Department:   dbUsr.Department.String,
+		Version:      dbUsr.Version,
 		DateCreated:  dbUsr.DateCreated.In(time.Local),
 		DateUpdated:  dbUsr.DateUpdated.In(time.Local),
 	}
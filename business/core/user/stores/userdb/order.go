@@ -2,24 +2,129 @@ package userdb
 
 import (
 	"fmt"
+	"slices"
+	"strconv"
+	"strings"
 
 	"github.com/1core-dev/go-service/business/core/user"
+	"github.com/1core-dev/go-service/business/data/dbsql/pgx/dbarray"
 	"github.com/1core-dev/go-service/business/data/order"
+	"github.com/1core-dev/go-service/business/data/page"
+	"github.com/google/uuid"
 )
 
+// orderByFields whitelists the columns that can be ordered by. Only these
+// values are ever written into a query. Department can be NULL, which is
+// ordered and compared as an empty string.
 var orderByFields = map[string]string{
-	user.OrderByID:      "user_id",
-	user.OrderByName:    "name",
-	user.OrderByEmail:   "email",
-	user.OrderByRoles:   "roles",
-	user.OrderByEnabled: "enabled",
+	user.OrderByID:         "user_id",
+	user.OrderByName:       "name",
+	user.OrderByEmail:      "email",
+	user.OrderByRoles:      "roles",
+	user.OrderByEnabled:    "enabled",
+	user.OrderByDepartment: "COALESCE(department, '')",
 }
 
-func orderByClause(orderBy order.By) (string, error) {
-	by, exists := orderByFields[orderBy.Field]
-	if !exists {
-		return "", fmt.Errorf("field %q does not exist", orderBy.Field)
+var reverse = map[string]string{
+	order.ASC:  order.DESC,
+	order.DESC: order.ASC,
+}
+
+func orderByClause(orderBy order.List) (string, error) {
+	return orderByColumns(orderBy, false)
+}
+
+// orderByColumns builds the ORDER BY clause from the whitelisted columns.
+// When reversed, every direction is flipped.
+func orderByColumns(orderBy order.List, reversed bool) (string, error) {
+	if len(orderBy) == 0 {
+		return "", nil
+	}
+
+	parts := make([]string, len(orderBy))
+	for i, ob := range orderBy {
+		by, exists := orderByFields[ob.Field]
+		if !exists {
+			return "", fmt.Errorf("field %q does not exist", ob.Field)
+		}
+
+		direction, exists := reverse[ob.Direction]
+		if !exists {
+			return "", fmt.Errorf("direction %q does not exist", ob.Direction)
+		}
+
+		if !reversed {
+			direction = ob.Direction
+		}
+
+		parts[i] = by + " " + direction
+	}
+
+	return " ORDER BY " + strings.Join(parts, ", "), nil
+}
+
+// keysetClause returns the condition selecting the rows after the cursor
+// position and the ordering to read them in. Since the fields can be ordered
+// in different directions, the condition is expanded into one term per
+// field. Reading backward reverses the ordering, so the rows are returned
+// nearest to the cursor first.
+func keysetClause(orderBy order.List, cursor page.Cursor, data map[string]interface{}) (string, string, error) {
+	orderClause, err := orderByColumns(orderBy, cursor.Backward)
+	if err != nil {
+		return "", "", err
+	}
+
+	if cursor.Order == "" {
+		return "", orderClause, nil
+	}
+
+	if len(cursor.Keys) != len(orderBy) {
+		return "", "", fmt.Errorf("cursor keys: %w", page.ErrInvalidCursor)
+	}
+
+	var terms []string
+	var equal []string
+
+	for i, ob := range orderBy {
+		by := orderByFields[ob.Field]
+
+		key, err := keysetValue(ob.Field, cursor.Keys[i])
+		if err != nil {
+			return "", "", fmt.Errorf("parse cursor key %q: %w", ob.Field, page.ErrInvalidCursor)
+		}
+
+		// The parameter is named after the field so the value is redacted
+		// along with the column when the query is logged.
+		name := fmt.Sprintf("%s_cursor_%d", ob.Field, i)
+		data[name] = key
+
+		op := ">"
+		if (ob.Direction == order.DESC) != cursor.Backward {
+			op = "<"
+		}
+
+		term := append(slices.Clip(equal), by+" "+op+" :"+name)
+		terms = append(terms, "("+strings.Join(term, " AND ")+")")
+
+		equal = append(equal, by+" = :"+name)
 	}
 
-	return " ORDER BY " + by + " " + orderBy.Direction, nil
+	return "(" + strings.Join(terms, " OR ") + ")", orderClause, nil
+}
+
+// keysetValue converts the cursor key into the type of the column.
+func keysetValue(field string, key string) (any, error) {
+	switch field {
+	case user.OrderByID:
+		return uuid.Parse(key)
+	case user.OrderByRoles:
+		if key == "" {
+			return dbarray.String{}, nil
+		}
+		return dbarray.String(strings.Split(key, ",")), nil
+	case user.OrderByEnabled:
+		return strconv.ParseBool(key)
+	default:
+		return key, nil
+	}
 }
@@ -0,0 +1,122 @@
+package userdb
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"unicode"
+
+	"github.com/1core-dev/go-service/business/core/user"
+	db "github.com/1core-dev/go-service/business/data/dbsql/pgx"
+)
+
+// highlightOptions configures ts_headline to mark the matching terms and to
+// return the whole value instead of a fragment.
+var highlightOptions = fmt.Sprintf("StartSel=%s, StopSel=%s, HighlightAll=true", user.HighlightStart, user.HighlightStop)
+
+// dbSearchResult represents a user returned by a search.
+type dbSearchResult struct {
+	dbUser
+	Rank           float64 `db:"rank"`
+	NameHighlight  string  `db:"name_highlight"`
+	EmailHighlight string  `db:"email_highlight"`
+}
+
+// Search retrieves the users matching the query using the search column
+// for prefix matches and trigram similarity for misspelled words.
+func (s *Store) Search(ctx context.Context, query string, pageNumber int, rowsPerPage int) ([]user.SearchResult, error) {
+	// The query may hold an email, so it's redacted from the query log.
+	data := struct {
+		Query       string `db:"q" log:"redact"`
+		Terms       string `db:"terms" log:"redact"`
+		Highlight   string `db:"highlight"`
+		Offset      int    `db:"offset"`
+		RowsPerPage int    `db:"rows_per_page"`
+	}{
+		Query:       query,
+		Terms:       searchTerms(query),
+		Highlight:   highlightOptions,
+		Offset:      (pageNumber - 1) * rowsPerPage,
+		RowsPerPage: rowsPerPage,
+	}
+
+	const q = `
+	SELECT
+		user_id, name, email, password_hash, roles, enabled, department, version, date_created, date_updated,
+		ts_rank(search, query) + word_similarity(:q, name) + word_similarity(:q, email) AS rank,
+		ts_headline('simple', name, query, :highlight) AS name_highlight,
+		ts_headline('simple', email, query, :highlight) AS email_highlight
+	FROM
+		users, to_tsquery('simple', :terms) AS query
+	WHERE
+		search @@ query OR :q <% name OR :q <% email
+	ORDER BY
+		rank DESC, user_id
+	OFFSET :offset ROWS FETCH NEXT :rows_per_page ROWS ONLY`
+
+	var dbResults []dbSearchResult
+	if err := db.NamedQuerySlice(ctx, s.log, s.db, q, data, &dbResults); err != nil {
+		return nil, fmt.Errorf("namedqueryslice: %w", err)
+	}
+
+	results := make([]user.SearchResult, len(dbResults))
+	for i, dbResult := range dbResults {
+		usr, err := toCoreUser(dbResult.dbUser)
+		if err != nil {
+			return nil, err
+		}
+
+		results[i] = user.SearchResult{
+			User:           usr,
+			Rank:           dbResult.Rank,
+			NameHighlight:  dbResult.NameHighlight,
+			EmailHighlight: dbResult.EmailHighlight,
+		}
+	}
+
+	return results, nil
+}
+
+// CountSearch returns the total number of users matching the query.
+func (s *Store) CountSearch(ctx context.Context, query string) (int, error) {
+	data := struct {
+		Query string `db:"q" log:"redact"`
+		Terms string `db:"terms" log:"redact"`
+	}{
+		Query: query,
+		Terms: searchTerms(query),
+	}
+
+	const q = `
+	SELECT
+		count(1)
+	FROM
+		users, to_tsquery('simple', :terms) AS query
+	WHERE
+		search @@ query OR :q <% name OR :q <% email`
+
+	var count struct {
+		Count int `db:"count"`
+	}
+	if err := db.NamedQueryStruct(ctx, s.log, s.db, q, data, &count); err != nil {
+		return 0, fmt.Errorf("namedquerystruct: %w", err)
+	}
+
+	return count.Count, nil
+}
+
+// searchTerms converts the query into a tsquery matching every word by
+// prefix. Only letters and digits are kept so the query syntax of tsquery
+// can't be used.
+func searchTerms(query string) string {
+	words := strings.FieldsFunc(strings.ToLower(query), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+
+	terms := make([]string, len(words))
+	for i, word := range words {
+		terms[i] = word + ":*"
+	}
+
+	return strings.Join(terms, " & ")
+}
@@ -3,13 +3,18 @@ package userdb
 import (
 	"bytes"
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"net/mail"
+	"slices"
+	"strings"
 
 	"github.com/1core-dev/go-service/business/core/user"
 	db "github.com/1core-dev/go-service/business/data/dbsql/pgx"
+	"github.com/1core-dev/go-service/business/data/dbsql/pgx/dbarray"
 	"github.com/1core-dev/go-service/business/data/order"
+	"github.com/1core-dev/go-service/business/data/page"
 	"github.com/1core-dev/go-service/business/data/transaction"
 	"github.com/1core-dev/go-service/pkg/logger"
 	"github.com/google/uuid"
@@ -50,9 +55,9 @@ func (s *Store) ExecuteUnderTransaction(tx transaction.Transaction) (user.Storer
 func (s *Store) Create(ctx context.Context, usr user.User) error {
 	const q = `
 	INSERT INTO users
-		(user_id, name, email, password_hash, roles, enabled, department, date_created, date_updated)
+		(user_id, name, email, password_hash, roles, enabled, department, version, date_created, date_updated)
 	VALUES
-		(:user_id, :name, :email, :password_hash, :roles, :enabled, :department, :date_created, :date_updated)`
+		(:user_id, :name, :email, :password_hash, :roles, :enabled, :department, :version, :date_created, :date_updated)`
 
 	if err := db.NamedExecContext(ctx, s.log, s.db, q, toDBUser(usr)); err != nil {
 		if errors.Is(err, db.ErrDBDuplicatedEntry) {
@@ -64,21 +69,127 @@ func (s *Store) Create(ctx context.Context, usr user.User) error {
 	return nil
 }
 
+// Update replaces a user document in the database. The update is conditional
+// on the version of the user matching the version currently stored.
+func (s *Store) Update(ctx context.Context, usr user.User) error {
+	const q = `
+	UPDATE
+		users
+	SET
+		"name" = :name,
+		"email" = :email,
+		"roles" = :roles,
+		"password_hash" = :password_hash,
+		"department" = :department,
+		"enabled" = :enabled,
+		"version" = version + 1,
+		"date_updated" = :date_updated
+	WHERE
+		user_id = :user_id AND version = :version
+	RETURNING
+		version`
+
+	var result struct {
+		Version int `db:"version"`
+	}
+	if err := db.NamedQueryStruct(ctx, s.log, s.db, q, toDBUser(usr), &result); err != nil {
+		switch {
+		case errors.Is(err, db.ErrDBNotFound):
+			return fmt.Errorf("namedquerystruct: %w", user.ErrVersionConflict)
+		case errors.Is(err, db.ErrDBDuplicatedEntry):
+			return fmt.Errorf("namedquerystruct: %w", user.ErrUniqueEmail)
+		}
+		return fmt.Errorf("namedquerystruct: %w", err)
+	}
+
+	return nil
+}
+
+// Delete removes a user from the database. The delete is conditional on the
+// version of the user matching the version currently stored.
+func (s *Store) Delete(ctx context.Context, usr user.User) error {
+	data := struct {
+		ID      string `db:"user_id"`
+		Version int    `db:"version"`
+	}{
+		ID:      usr.ID.String(),
+		Version: usr.Version,
+	}
+
+	const q = `
+	DELETE FROM
+		users
+	WHERE
+		user_id = :user_id AND version = :version
+	RETURNING
+		user_id`
+
+	var result struct {
+		ID string `db:"user_id"`
+	}
+	if err := db.NamedQueryStruct(ctx, s.log, s.db, q, data, &result); err != nil {
+		if errors.Is(err, db.ErrDBNotFound) {
+			return fmt.Errorf("namedquerystruct: %w", user.ErrVersionConflict)
+		}
+		return fmt.Errorf("namedquerystruct: %w", err)
+	}
+
+	return nil
+}
+
+// Upsert inserts the batch of users into the database with a single
+// statement. Users whose email already exists are updated instead, leaving
+// the password hash untouched. The stored users are returned.
+func (s *Store) Upsert(ctx context.Context, usrs []user.User) ([]user.User, error) {
+	const q = `
+	INSERT INTO users
+		(user_id, name, email, password_hash, roles, enabled, department, version, date_created, date_updated)
+	VALUES
+		(:user_id, :name, :email, :password_hash, :roles, :enabled, :department, :version, :date_created, :date_updated)
+	ON CONFLICT (email) DO UPDATE SET
+		"name" = EXCLUDED.name,
+		"roles" = EXCLUDED.roles,
+		"department" = EXCLUDED.department,
+		"version" = users.version + 1,
+		"date_updated" = EXCLUDED.date_updated
+	RETURNING
+		user_id, name, email, password_hash, roles, enabled, department, version, date_created, date_updated`
+
+	dbUsrs := make([]dbUser, len(usrs))
+	for i, usr := range usrs {
+		dbUsrs[i] = toDBUser(usr)
+	}
+
+	var stored []dbUser
+	if err := db.NamedQuerySlice(ctx, s.log, s.db, q, dbUsrs, &stored); err != nil {
+		return nil, fmt.Errorf("namedqueryslice: %w", err)
+	}
+
+	return toCoreUserSlice(stored)
+}
+
 // Query retrieves a list of existing users from the database.
-func (s *Store) Query(ctx context.Context, filter user.QueryFilter, orderBy order.By, pageNumber int, rowsPerPage int) ([]user.User, error) {
+func (s *Store) Query(ctx context.Context, filter user.QueryFilter, orderBy order.List, fields []string, pageNumber int, rowsPerPage int) ([]user.User, error) {
 	data := map[string]interface{}{
 		"offset":        (pageNumber - 1) * rowsPerPage,
 		"rows_per_page": rowsPerPage,
 	}
 
-	const q = `
+	columns, err := selectColumns(fields)
+	if err != nil {
+		return nil, err
+	}
+
+	q := `
 	SELECT
-		user_id, name, email, password_hash, roles, enabled, department, date_created, date_updated
+		` + columns + `
 	FROM
 		users`
 
 	buf := bytes.NewBufferString(q)
-	s.applyFilter(filter, data, buf)
+	if err := s.applyFilter(filter, data, buf); err != nil {
+		return nil, err
+	}
 
 	orderByClause, err := orderByClause(orderBy)
 	if err != nil {
@@ -101,6 +212,96 @@ func (s *Store) Query(ctx context.Context, filter user.QueryFilter, orderBy orde
 	return usrs, nil
 }
 
+// QueryKeyset retrieves a list of users positioned relative to the cursor
+// using keyset paging, which performs the same on any page.
+func (s *Store) QueryKeyset(ctx context.Context, filter user.QueryFilter, orderBy order.List, cursor page.Cursor, rowsPerPage int) ([]user.User, error) {
+	data := map[string]interface{}{
+		"rows_per_page": rowsPerPage,
+	}
+
+	const q = `
+	SELECT
+		user_id, name, email, password_hash, roles, enabled, department, version, date_created, date_updated
+	FROM
+		users`
+
+	keyset, orderClause, err := keysetClause(orderBy, cursor, data)
+	if err != nil {
+		return nil, err
+	}
+
+	wc, err := filterClauses(filter, data)
+	if err != nil {
+		return nil, err
+	}
+
+	if keyset != "" {
+		wc = append(wc, keyset)
+	}
+
+	buf := bytes.NewBufferString(q)
+	if len(wc) > 0 {
+		buf.WriteString(" WHERE ")
+		buf.WriteString(strings.Join(wc, " AND "))
+	}
+
+	buf.WriteString(orderClause)
+	buf.WriteString(" FETCH NEXT :rows_per_page ROWS ONLY")
+
+	var dbUsrs []dbUser
+	if err := db.NamedQuerySlice(ctx, s.log, s.db, buf.String(), data, &dbUsrs); err != nil {
+		return nil, fmt.Errorf("namedqueryslice: %w", err)
+	}
+
+	// Rows read backward come nearest to the cursor first.
+	if cursor.Backward {
+		slices.Reverse(dbUsrs)
+	}
+
+	return toCoreUserSlice(dbUsrs)
+}
+
+// QueryCursor streams the users matching the filter to the fn function
+// using a server-side cursor, so the full result is never held in memory.
+func (s *Store) QueryCursor(ctx context.Context, filter user.QueryFilter, orderBy order.List, fn func(user.User) error) error {
+	const fetchSize = 500
+
+	data := map[string]interface{}{}
+
+	const q = `
+	SELECT
+		user_id, name, email, password_hash, roles, enabled, department, version, date_created, date_updated
+	FROM
+		users`
+
+	buf := bytes.NewBufferString(q)
+	if err := s.applyFilter(filter, data, buf); err != nil {
+		return err
+	}
+
+	orderByClause, err := orderByClause(orderBy)
+	if err != nil {
+		return err
+	}
+
+	buf.WriteString(orderByClause)
+
+	f := func(dbUsr dbUser) error {
+		usr, err := toCoreUser(dbUsr)
+		if err != nil {
+			return err
+		}
+
+		return fn(usr)
+	}
+
+	if err := db.NamedQueryCursor(ctx, s.log, s.db, buf.String(), data, fetchSize, f); err != nil {
+		return fmt.Errorf("namedquerycursor: %w", err)
+	}
+
+	return nil
+}
+
 // Count returns the total number of users in the DB.
 func (s *Store) Count(ctx context.Context, filter user.QueryFilter) (int, error) {
 	data := map[string]interface{}{}
@@ -112,7 +313,9 @@ func (s *Store) Count(ctx context.Context, filter user.QueryFilter) (int, error)
 		users`
 
 	buf := bytes.NewBufferString(q)
-	s.applyFilter(filter, data, buf)
+	if err := s.applyFilter(filter, data, buf); err != nil {
+		return 0, err
+	}
 
 	var count struct {
 		Count int `db:"count"`
@@ -124,6 +327,46 @@ func (s *Store) Count(ctx context.Context, filter user.QueryFilter) (int, error)
 	return count.Count, nil
 }
 
+// EstimateCount returns the number of users the query planner expects the
+// filter to match, taken from the plan of the count query.
+func (s *Store) EstimateCount(ctx context.Context, filter user.QueryFilter) (int, error) {
+	data := map[string]interface{}{}
+
+	const q = `
+	EXPLAIN (FORMAT JSON)
+	SELECT
+		user_id
+	FROM
+		users`
+
+	buf := bytes.NewBufferString(q)
+	if err := s.applyFilter(filter, data, buf); err != nil {
+		return 0, err
+	}
+
+	var explain struct {
+		Plan []byte `db:"QUERY PLAN"`
+	}
+	if err := db.NamedQueryStruct(ctx, s.log, s.db, buf.String(), data, &explain); err != nil {
+		return 0, fmt.Errorf("namedquerystruct: %w", err)
+	}
+
+	var plans []struct {
+		Plan struct {
+			Rows float64 `json:"Plan Rows"`
+		} `json:"Plan"`
+	}
+	if err := json.Unmarshal(explain.Plan, &plans); err != nil {
+		return 0, fmt.Errorf("unmarshal plan: %w", err)
+	}
+
+	if len(plans) == 0 {
+		return 0, errors.New("empty plan")
+	}
+
+	return int(plans[0].Plan.Rows), nil
+}
+
 // QueryByID gets the specified user from the database.
 func (s *Store) QueryByID(ctx context.Context, userID uuid.UUID) (user.User, error) {
 	data := struct {
@@ -134,7 +377,7 @@ func (s *Store) QueryByID(ctx context.Context, userID uuid.UUID) (user.User, err
 
 	const q = `
 	SELECT
-		user_id, name, email, password_hash, roles, enabled, department, date_created, date_updated
+		user_id, name, email, password_hash, roles, enabled, department, version, date_created, date_updated
 	FROM
 		users
 	WHERE 
@@ -166,7 +409,7 @@ func (s *Store) QueryByEmail(ctx context.Context, email mail.Address) (user.User
 
 	const q = `
 	SELECT
-		user_id, name, email, password_hash, roles, enabled, department, date_created, date_updated
+		user_id, name, email, password_hash, roles, enabled, department, version, date_created, date_updated
 	FROM
 		users
 	WHERE
@@ -187,3 +430,33 @@ func (s *Store) QueryByEmail(ctx context.Context, email mail.Address) (user.User
 
 	return usr, nil
 }
+
+// QueryByEmails gets the users with the specified emails from the database.
+// Emails without a user are ignored.
+func (s *Store) QueryByEmails(ctx context.Context, emails []mail.Address) ([]user.User, error) {
+	addrs := make(dbarray.String, len(emails))
+	for i, email := range emails {
+		addrs[i] = email.Address
+	}
+
+	data := struct {
+		Emails dbarray.String `db:"emails"`
+	}{
+		Emails: addrs,
+	}
+
+	const q = `
+	SELECT
+		user_id, name, email, password_hash, roles, enabled, department, version, date_created, date_updated
+	FROM
+		users
+	WHERE
+		email = ANY(:emails)`
+
+	var dbUsrs []dbUser
+	if err := db.NamedQuerySlice(ctx, s.log, s.db, q, data, &dbUsrs); err != nil {
+		return nil, fmt.Errorf("namedqueryslice: %w", err)
+	}
+
+	return toCoreUserSlice(dbUsrs)
+}
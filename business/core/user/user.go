@@ -3,12 +3,16 @@ package user
 
 import (
 	"context"
+	"database/sql"
 	"errors"
 	"fmt"
 	"net/mail"
+	"runtime"
+	"sync"
 	"time"
 
 	"github.com/1core-dev/go-service/business/data/order"
+	"github.com/1core-dev/go-service/business/data/page"
 	"github.com/1core-dev/go-service/business/data/transaction"
 	"github.com/1core-dev/go-service/pkg/logger"
 	"github.com/google/uuid"
@@ -20,6 +24,7 @@ var (
 	ErrNotFound              = errors.New("user not found")
 	ErrUniqueEmail           = errors.New("email is not unique")
 	ErrAuthenticationFailure = errors.New("authentication failed")
+	ErrVersionConflict       = errors.New("user was modified by another request")
 )
 
 // Storer interface declares the behavior this package needs to persists and
@@ -27,23 +32,38 @@ var (
 type Storer interface {
 	ExecuteUnderTransaction(tx transaction.Transaction) (Storer, error)
 	Create(ctx context.Context, usr User) error
-	Query(ctx context.Context, filter QueryFilter, orderBy order.By, pageNumber int, rowsPerPage int) ([]User, error)
+	Update(ctx context.Context, usr User) error
+	Delete(ctx context.Context, usr User) error
+	Upsert(ctx context.Context, usrs []User) ([]User, error)
+	Query(ctx context.Context, filter QueryFilter, orderBy order.List, fields []string, pageNumber int, rowsPerPage int) ([]User, error)
+	QueryCursor(ctx context.Context, filter QueryFilter, orderBy order.List, fn func(User) error) error
+	QueryKeyset(ctx context.Context, filter QueryFilter, orderBy order.List, cursor page.Cursor, rowsPerPage int) ([]User, error)
 	Count(ctx context.Context, filter QueryFilter) (int, error)
+	EstimateCount(ctx context.Context, filter QueryFilter) (int, error)
+	Search(ctx context.Context, query string, pageNumber int, rowsPerPage int) ([]SearchResult, error)
+	CountSearch(ctx context.Context, query string) (int, error)
 	QueryByID(ctx context.Context, userID uuid.UUID) (User, error)
 	QueryByEmail(ctx context.Context, email mail.Address) (User, error)
+	QueryByEmails(ctx context.Context, emails []mail.Address) ([]User, error)
+	CreateHistory(ctx context.Context, h History) error
+	QueryHistory(ctx context.Context, userID uuid.UUID, pageNumber int, rowsPerPage int) ([]History, error)
+	CountHistory(ctx context.Context, userID uuid.UUID) (int, error)
 }
 
 // Core manages the set of APIs for user access.
 type Core struct {
-	storer Storer
-	log    *logger.Logger
+	storer   Storer
+	beginner transaction.Beginner
+	log      *logger.Logger
 }
 
-// NewCore constructs a core for user api access.
-func NewCore(log *logger.Logger, storer Storer) *Core {
+// NewCore constructs a core for user api access. The beginner is used to
+// write every mutation together with its history record atomically.
+func NewCore(log *logger.Logger, beginner transaction.Beginner, storer Storer) *Core {
 	return &Core{
-		storer: storer,
-		log:    log,
+		storer:   storer,
+		beginner: beginner,
+		log:      log,
 	}
 }
 
@@ -64,17 +84,213 @@ func (c *Core) Create(ctx context.Context, nu NewUser) (User, error) {
 		Roles:        nu.Roles,
 		Department:   nu.Department,
 		Enabled:      true,
+		Version:      1,
 		DateCreated:  now,
 		DateUpdated:  now,
 	}
 
-	if err := c.storer.Create(ctx, usr); err != nil {
-		return User{}, fmt.Errorf("create: %w", err)
+	f := func(s Storer) error {
+		if err := s.Create(ctx, usr); err != nil {
+			return fmt.Errorf("create: %w", err)
+		}
+
+		if err := s.CreateHistory(ctx, newHistory(ctx, ActionCreate, User{}, usr)); err != nil {
+			return fmt.Errorf("create history: %w", err)
+		}
+
+		return nil
+	}
+
+	if err := c.executeInTransaction(ctx, f); err != nil {
+		return User{}, err
 	}
 
 	return usr, nil
 }
 
+// Update modifies information about a user. The update only succeeds if the
+// version of the provided user matches the version currently stored,
+// otherwise ErrVersionConflict is returned.
+func (c *Core) Update(ctx context.Context, usr User, uu UpdateUser) (User, error) {
+	before := usr
+
+	if uu.Name != nil {
+		usr.Name = *uu.Name
+	}
+
+	if uu.Email != nil {
+		usr.Email = *uu.Email
+	}
+
+	if uu.Roles != nil {
+		usr.Roles = uu.Roles
+	}
+
+	if uu.Password != nil {
+		pw, err := bcrypt.GenerateFromPassword([]byte(*uu.Password), bcrypt.DefaultCost)
+		if err != nil {
+			return User{}, fmt.Errorf("generate from password: %w", err)
+		}
+		usr.PasswordHash = pw
+	}
+
+	if uu.Department != nil {
+		usr.Department = *uu.Department
+	}
+
+	if uu.Enabled != nil {
+		usr.Enabled = *uu.Enabled
+	}
+
+	usr.DateUpdated = time.Now()
+
+	f := func(s Storer) error {
+		if err := s.Update(ctx, usr); err != nil {
+			return fmt.Errorf("update: %w", err)
+		}
+
+		if err := s.CreateHistory(ctx, newHistory(ctx, ActionUpdate, before, usr)); err != nil {
+			return fmt.Errorf("create history: %w", err)
+		}
+
+		return nil
+	}
+
+	if err := c.executeInTransaction(ctx, f); err != nil {
+		return User{}, err
+	}
+
+	usr.Version++
+
+	return usr, nil
+}
+
+// Delete removes the specified user. The delete only succeeds if the version
+// of the provided user matches the version currently stored, otherwise
+// ErrVersionConflict is returned.
+func (c *Core) Delete(ctx context.Context, usr User) error {
+	f := func(s Storer) error {
+		if err := s.Delete(ctx, usr); err != nil {
+			return fmt.Errorf("delete: %w", err)
+		}
+
+		if err := s.CreateHistory(ctx, newHistory(ctx, ActionDelete, usr, User{})); err != nil {
+			return fmt.Errorf("create history: %w", err)
+		}
+
+		return nil
+	}
+
+	return c.executeInTransaction(ctx, f)
+}
+
+// Import adds the batch of users to the system. A user with an email that
+// already exists is updated instead, except for the password which is never
+// replaced. This makes importing the same batch again safe.
+func (c *Core) Import(ctx context.Context, nus []NewUser) ([]User, error) {
+	if len(nus) == 0 {
+		return nil, nil
+	}
+
+	hashes, err := hashPasswords(nus)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+
+	usrs := make([]User, len(nus))
+	for i, nu := range nus {
+		usrs[i] = User{
+			ID:           uuid.New(),
+			Name:         nu.Name,
+			Email:        nu.Email,
+			PasswordHash: hashes[i],
+			Roles:        nu.Roles,
+			Department:   nu.Department,
+			Enabled:      true,
+			Version:      1,
+			DateCreated:  now,
+			DateUpdated:  now,
+		}
+	}
+
+	emails := make([]mail.Address, len(usrs))
+	for i, usr := range usrs {
+		emails[i] = usr.Email
+	}
+
+	var imported []User
+
+	f := func(s Storer) error {
+		// The existing users are loaded first so the history of an updated
+		// user records what the import changed.
+		existing, err := s.QueryByEmails(ctx, emails)
+		if err != nil {
+			return fmt.Errorf("query by emails: %w", err)
+		}
+
+		before := make(map[string]User, len(existing))
+		for _, usr := range existing {
+			before[usr.Email.Address] = usr
+		}
+
+		imported, err = s.Upsert(ctx, usrs)
+		if err != nil {
+			return fmt.Errorf("upsert: %w", err)
+		}
+
+		for _, usr := range imported {
+			if err := s.CreateHistory(ctx, newHistory(ctx, ActionImport, before[usr.Email.Address], usr)); err != nil {
+				return fmt.Errorf("create history: %w", err)
+			}
+		}
+
+		return nil
+	}
+
+	if err := c.executeInTransaction(ctx, f); err != nil {
+		return nil, err
+	}
+
+	return imported, nil
+}
+
+// hashPasswords generates the password hashes for the users concurrently
+// since bcrypt is intentionally slow.
+func hashPasswords(nus []NewUser) ([][]byte, error) {
+	hashes := make([][]byte, len(nus))
+	errs := make([]error, len(nus))
+
+	work := make(chan int)
+
+	var wg sync.WaitGroup
+	for range runtime.GOMAXPROCS(0) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range work {
+				hashes[i], errs[i] = bcrypt.GenerateFromPassword([]byte(nus[i].Password), bcrypt.DefaultCost)
+			}
+		}()
+	}
+
+	for i := range nus {
+		work <- i
+	}
+	close(work)
+
+	wg.Wait()
+
+	for i, err := range errs {
+		if err != nil {
+			return nil, fmt.Errorf("generate from password: email[%s]: %w", nus[i].Email.Address, err)
+		}
+	}
+
+	return hashes, nil
+}
+
 // ExecuteUnderTransaction constructs a new Core value that will use the
 // specified transaction in any store related calls.
 func (c *Core) ExecuteUnderTransaction(tx transaction.Transaction) (*Core, error) {
@@ -83,6 +299,7 @@ func (c *Core) ExecuteUnderTransaction(tx transaction.Transaction) (*Core, error
 		return nil, err
 	}
 
+	// The beginner is not carried over since the caller owns the transaction.
 	c = &Core{
 		storer: trS,
 		log:    c.log,
@@ -91,9 +308,54 @@ func (c *Core) ExecuteUnderTransaction(tx transaction.Transaction) (*Core, error
 	return c, nil
 }
 
+// executeInTransaction executes the function against a store that is bound
+// to a new transaction. If the core is already executing under a transaction
+// or no beginner was provided, the function is executed against the
+// current store.
+func (c *Core) executeInTransaction(ctx context.Context, f func(s Storer) error) error {
+	if c.beginner == nil {
+		return f(c.storer)
+	}
+
+	tx, err := c.beginner.Begin()
+	if err != nil {
+		return fmt.Errorf("begin: %w", err)
+	}
+
+	defer func() {
+		if err := tx.Rollback(); err != nil && !errors.Is(err, sql.ErrTxDone) {
+			c.log.Info(ctx, "rollback", "ERROR", err)
+		}
+	}()
+
+	trS, err := c.storer.ExecuteUnderTransaction(tx)
+	if err != nil {
+		return fmt.Errorf("execute under transaction: %w", err)
+	}
+
+	if err := f(trS); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("commit: %w", err)
+	}
+
+	return nil
+}
+
 // Query retrieves a list of existing users.
-func (c *Core) Query(ctx context.Context, filter QueryFilter, orderBy order.By, pageNumber int, rowsPerPage int) ([]User, error) {
-	users, err := c.storer.Query(ctx, filter, orderBy, pageNumber, rowsPerPage)
+func (c *Core) Query(ctx context.Context, filter QueryFilter, orderBy order.List, pageNumber int, rowsPerPage int) ([]User, error) {
+	return c.QueryFields(ctx, filter, orderBy, nil, pageNumber, rowsPerPage)
+}
+
+// QueryFields retrieves a list of existing users with only the specified
+// fields loaded. The ID is always loaded. If no fields are specified, every
+// field is loaded.
+func (c *Core) QueryFields(ctx context.Context, filter QueryFilter, orderBy order.List, fields []string, pageNumber int, rowsPerPage int) ([]User, error) {
+	orderBy = orderBy.WithTiebreaker(OrderByID)
+
+	users, err := c.storer.Query(ctx, filter, orderBy, fields, pageNumber, rowsPerPage)
 	if err != nil {
 		return nil, fmt.Errorf("query: %w", err)
 	}
@@ -101,11 +363,85 @@ func (c *Core) Query(ctx context.Context, filter QueryFilter, orderBy order.By,
 	return users, nil
 }
 
+// QueryKeyset retrieves a page of users positioned relative to the cursor,
+// along with the cursors to the pages on either side. A zero cursor returns
+// the first page. Unlike offset paging, the page isn't affected by rows
+// added or removed before it.
+func (c *Core) QueryKeyset(ctx context.Context, filter QueryFilter, orderBy order.List, cursor page.Cursor, rowsPerPage int) ([]User, page.Cursors, error) {
+	orderBy = orderBy.WithTiebreaker(OrderByID)
+
+	first := cursor.Order == ""
+
+	if !first && cursor.Order != orderBy.String() {
+		return nil, page.Cursors{}, page.ErrInvalidCursor
+	}
+
+	// One extra row is requested to learn if there is more data beyond
+	// this page.
+	users, err := c.storer.QueryKeyset(ctx, filter, orderBy, cursor, rowsPerPage+1)
+	if err != nil {
+		return nil, page.Cursors{}, fmt.Errorf("query: %w", err)
+	}
+
+	more := len(users) > rowsPerPage
+	if more {
+		switch cursor.Backward {
+		case true:
+			users = users[1:]
+		default:
+			users = users[:rowsPerPage]
+		}
+	}
+
+	if len(users) == 0 {
+		return users, page.Cursors{}, nil
+	}
+
+	newCursor := func(usr User, backward bool) *page.Cursor {
+		return &page.Cursor{
+			Order:    orderBy.String(),
+			Keys:     orderKeys(usr, orderBy),
+			Backward: backward,
+		}
+	}
+
+	var cursors page.Cursors
+
+	if more || cursor.Backward {
+		cursors.Next = newCursor(users[len(users)-1], false)
+	}
+
+	if !first && (more || !cursor.Backward) {
+		cursors.Prev = newCursor(users[0], true)
+	}
+
+	return users, cursors, nil
+}
+
+// Export passes every user matching the filter to the fn function in the
+// specified order without loading the full result into memory.
+func (c *Core) Export(ctx context.Context, filter QueryFilter, orderBy order.List, fn func(User) error) error {
+	orderBy = orderBy.WithTiebreaker(OrderByID)
+
+	if err := c.storer.QueryCursor(ctx, filter, orderBy, fn); err != nil {
+		return fmt.Errorf("query cursor: %w", err)
+	}
+
+	return nil
+}
+
 // Count returns the total number of users.
 func (c *Core) Count(ctx context.Context, filter QueryFilter) (int, error) {
 	return c.storer.Count(ctx, filter)
 }
 
+// EstimateCount returns the number of users the query planner expects the
+// filter to match. It's much cheaper than Count on large tables but can be
+// off, so it must not be treated as exact.
+func (c *Core) EstimateCount(ctx context.Context, filter QueryFilter) (int, error) {
+	return c.storer.EstimateCount(ctx, filter)
+}
+
 // QueryByID finds the user by the specified ID.
 func (c *Core) QueryByID(ctx context.Context, userID uuid.UUID) (User, error) {
 	user, err := c.storer.QueryByID(ctx, userID)
@@ -2,6 +2,7 @@ package user_test
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"net/mail"
 	"runtime/debug"
@@ -10,7 +11,10 @@ import (
 
 	"github.com/1core-dev/go-service/business/core/user"
 	"github.com/1core-dev/go-service/business/data/dbtest"
+	"github.com/1core-dev/go-service/business/data/order"
+	"github.com/1core-dev/go-service/business/data/page"
 	"github.com/1core-dev/go-service/pkg/docker"
+	"github.com/google/go-cmp/cmp"
 	"github.com/google/uuid"
 )
 
@@ -30,6 +34,10 @@ func TestMain(m *testing.M) {
 
 func Test_User(t *testing.T) {
 	t.Run("crud", crud)
+	t.Run("keyset", keyset)
+	t.Run("search", search)
+	t.Run("filter", queryFilter)
+	t.Run("import", importUsers)
 }
 
 // =============================================================================
@@ -92,4 +100,427 @@ func crud(t *testing.T) {
 		t.Errorf("GOT: %s", usr.Name)
 		t.Errorf("EXP: %s", nu.Name)
 	}
+	hists, err := api.User.QueryHistory(ctx, usr.ID, 1, 10)
+	if err != nil {
+		t.Fatalf("Should be able to query user history : %s.", err)
+	}
+
+	if len(hists) != 1 {
+		t.Fatalf("Should have a single history record : got %d.", len(hists))
+	}
+
+	if hists[0].Action != user.ActionCreate {
+		t.Error("Should have recorded the create action.")
+		t.Errorf("GOT: %s", hists[0].Action)
+		t.Errorf("EXP: %s", user.ActionCreate)
+	}
+
+	if _, exists := hists[0].Diff["password"]; !exists {
+		t.Error("Should have recorded the password change without the hash.")
+	}
+	// -------------------------------------------------------------------------
+
+	upd := user.UpdateUser{
+		Name: dbtest.StringPointer("Jack Kennedy"),
+	}
+
+	updUsr, err := api.User.Update(ctx, usr, upd)
+	if err != nil {
+		t.Fatalf("Should be able to update user : %s.", err)
+	}
+
+	if updUsr.Version != usr.Version+1 {
+		t.Error("Should have incremented the version.")
+		t.Errorf("GOT: %d", updUsr.Version)
+		t.Errorf("EXP: %d", usr.Version+1)
+	}
+
+	if _, err := api.User.Update(ctx, usr, upd); !errors.Is(err, user.ErrVersionConflict) {
+		t.Errorf("Should get a version conflict updating a stale user : %v.", err)
+	}
+
+	if err := api.User.Delete(ctx, usr); !errors.Is(err, user.ErrVersionConflict) {
+		t.Errorf("Should get a version conflict deleting a stale user : %v.", err)
+	}
+
+	if err := api.User.Delete(ctx, updUsr); err != nil {
+		t.Fatalf("Should be able to delete user : %s.", err)
+	}
+
+	if _, err := api.User.QueryByID(ctx, usr.ID); !errors.Is(err, user.ErrNotFound) {
+		t.Errorf("Should not be able to retrieve a deleted user : %v.", err)
+	}
+}
+
+func keyset(t *testing.T) {
+	test := dbtest.NewTest(t, c)
+	defer func() {
+		if r := recover(); r != nil {
+			t.Log(r)
+			t.Error(string(debug.Stack()))
+		}
+		test.Teardown()
+	}()
+
+	api := test.CoreAPIs
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	// -------------------------------------------------------------------------
+
+	const prefix = "Keyset"
+
+	var names []string
+	for i := range 5 {
+		name := fmt.Sprintf("%s %d", prefix, i)
+
+		nu := user.NewUser{
+			Name:            name,
+			Email:           mail.Address{Address: fmt.Sprintf("keyset%d@example.com", i)},
+			Roles:           []user.Role{user.RoleUser},
+			Password:        "42",
+			PasswordConfirm: "42",
+		}
+
+		if _, err := api.User.Create(ctx, nu); err != nil {
+			t.Fatalf("Should be able to create user : %s.", err)
+		}
+		names = append(names, name)
+	}
+
+	filter := user.QueryFilter{Name: dbtest.StringPointer(prefix)}
+	orderBy := order.NewList(order.NewBy(user.OrderByName, order.ASC))
+
+	// -------------------------------------------------------------------------
+
+	var got []string
+	var pages [][]user.User
+
+	var cursor page.Cursor
+	for {
+		users, cursors, err := api.User.QueryKeyset(ctx, filter, orderBy, cursor, 2)
+		if err != nil {
+			t.Fatalf("Should be able to query a page of users : %s.", err)
+		}
+
+		for _, usr := range users {
+			got = append(got, usr.Name)
+		}
+		pages = append(pages, users)
+
+		if cursors.Next == nil {
+			break
+		}
+		cursor = *cursors.Next
+	}
+
+	if diff := cmp.Diff(got, names); diff != "" {
+		t.Errorf("Should get every user once in order : %s.", diff)
+	}
+
+	if len(pages) != 3 {
+		t.Fatalf("Should get 3 pages : got %d.", len(pages))
+	}
+
+	// -------------------------------------------------------------------------
+
+	last := pages[len(pages)-1]
+
+	_, cursors, err := api.User.QueryKeyset(ctx, filter, orderBy, page.Cursor{}, 2)
+	if err != nil {
+		t.Fatalf("Should be able to query the first page : %s.", err)
+	}
+
+	if cursors.Prev != nil {
+		t.Error("Should not get a previous cursor on the first page.")
+	}
+
+	prev := page.Cursor{
+		Order:    cursors.Next.Order,
+		Keys:     []string{last[0].Name, last[0].ID.String()},
+		Backward: true,
+	}
+
+	users, _, err := api.User.QueryKeyset(ctx, filter, orderBy, prev, 2)
+	if err != nil {
+		t.Fatalf("Should be able to query the previous page : %s.", err)
+	}
+
+	if diff := cmp.Diff(users, pages[1]); diff != "" {
+		t.Errorf("Should get the second page going backward : %s.", diff)
+	}
+
+	// -------------------------------------------------------------------------
+
+	other := prev
+	other.Order = "email,ASC;user_id,ASC"
+
+	if _, _, err := api.User.QueryKeyset(ctx, filter, orderBy, other, 2); !errors.Is(err, page.ErrInvalidCursor) {
+		t.Errorf("Should reject a cursor issued for another ordering : %v.", err)
+	}
+}
+
+func search(t *testing.T) {
+	test := dbtest.NewTest(t, c)
+	defer func() {
+		if r := recover(); r != nil {
+			t.Log(r)
+			t.Error(string(debug.Stack()))
+		}
+		test.Teardown()
+	}()
+
+	api := test.CoreAPIs
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	// -------------------------------------------------------------------------
+
+	nu := user.NewUser{
+		Name:            "Jonathan Smithers",
+		Email:           mail.Address{Address: "jonathan@example.com"},
+		Roles:           []user.Role{user.RoleUser},
+		Department:      "Engineering",
+		Password:        "42",
+		PasswordConfirm: "42",
+	}
+
+	usr, err := api.User.Create(ctx, nu)
+	if err != nil {
+		t.Fatalf("Should be able to create user : %s.", err)
+	}
+
+	for _, query := range []string{"jonat", "smithers", "Jonathn Smithrs"} {
+		results, err := api.User.Search(ctx, query, 1, 10)
+		if err != nil {
+			t.Fatalf("Should be able to search for %q : %s.", query, err)
+		}
+
+		if len(results) == 0 || results[0].User.ID != usr.ID {
+			t.Errorf("Should find the user first searching for %q.", query)
+			continue
+		}
+
+		n, err := api.User.CountSearch(ctx, query)
+		if err != nil {
+			t.Fatalf("Should be able to count the search for %q : %s.", query, err)
+		}
+
+		if n < 1 {
+			t.Errorf("Should count the user searching for %q : got %d.", query, n)
+		}
+	}
+
+	results, err := api.User.Search(ctx, "jonat", 1, 10)
+	if err != nil {
+		t.Fatalf("Should be able to search : %s.", err)
+	}
+
+	if exp := user.HighlightStart + "Jonathan" + user.HighlightStop + " Smithers"; len(results) == 0 || results[0].NameHighlight != exp {
+		t.Errorf("Should highlight the matching name term : %v.", results)
+	}
+}
+
+func queryFilter(t *testing.T) {
+	test := dbtest.NewTest(t, c)
+	defer func() {
+		if r := recover(); r != nil {
+			t.Log(r)
+			t.Error(string(debug.Stack()))
+		}
+		test.Teardown()
+	}()
+
+	api := test.CoreAPIs
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	// -------------------------------------------------------------------------
+
+	start := time.Now()
+
+	newUsers := []user.NewUser{
+		{Name: "Filter Admin", Roles: []user.Role{user.RoleAdmin, user.RoleUser}, Department: "IT"},
+		{Name: "Filter User", Roles: []user.Role{user.RoleUser}, Department: "Sales"},
+		{Name: "Filter Other", Roles: []user.Role{user.RoleUser}, Department: "Marketing"},
+	}
+
+	usrs := make([]user.User, len(newUsers))
+	for i, nu := range newUsers {
+		nu.Email = mail.Address{Address: fmt.Sprintf("filter%d@example.com", i)}
+		nu.Password = "42"
+		nu.PasswordConfirm = "42"
+
+		usr, err := api.User.Create(ctx, nu)
+		if err != nil {
+			t.Fatalf("Should be able to create user : %s.", err)
+		}
+		usrs[i] = usr
+	}
+
+	disabled, err := api.User.Update(ctx, usrs[2], user.UpdateUser{Enabled: dbtest.BoolPointer(false)})
+	if err != nil {
+		t.Fatalf("Should be able to disable user : %s.", err)
+	}
+
+	// -------------------------------------------------------------------------
+
+	name := dbtest.StringPointer("Filter")
+	tm := func(t time.Time) *time.Time { return &t }
+
+	tests := []struct {
+		name   string
+		filter user.QueryFilter
+		exp    []user.User
+	}{
+		{"roles", user.QueryFilter{Name: name, Roles: []user.Role{user.RoleAdmin}}, usrs[:1]},
+		{"rolesAll", user.QueryFilter{Name: name, Roles: []user.Role{user.RoleAdmin, user.RoleUser}}, usrs[:1]},
+		{"departments", user.QueryFilter{Name: name, Departments: []string{"IT", "Sales"}}, usrs[:2]},
+		{"enabled", user.QueryFilter{Name: name, Enabled: dbtest.BoolPointer(false)}, []user.User{disabled}},
+		{"updated", user.QueryFilter{Name: name, StartUpdatedDate: tm(disabled.DateUpdated.Truncate(time.Microsecond))}, []user.User{disabled}},
+		{"updatedRange", user.QueryFilter{Name: name, StartUpdatedDate: tm(start), EndUpdatedDate: tm(time.Now())}, []user.User{usrs[0], usrs[1], disabled}},
+	}
+
+	orderBy := order.NewList(order.NewBy(user.OrderByName, order.ASC))
+
+	for _, tt := range tests {
+		if err := tt.filter.Validate(); err != nil {
+			t.Fatalf("%s: Should be a valid filter : %s.", tt.name, err)
+		}
+
+		got, err := api.User.Query(ctx, tt.filter, orderBy, 1, 10)
+		if err != nil {
+			t.Fatalf("%s: Should be able to query : %s.", tt.name, err)
+		}
+
+		gotIDs := make(map[uuid.UUID]bool)
+		for _, usr := range got {
+			gotIDs[usr.ID] = true
+		}
+
+		if len(got) != len(tt.exp) {
+			t.Errorf("%s: Should get %d users : got %d.", tt.name, len(tt.exp), len(got))
+		}
+
+		for _, usr := range tt.exp {
+			if !gotIDs[usr.ID] {
+				t.Errorf("%s: Should get user %q.", tt.name, usr.Name)
+			}
+		}
+	}
+
+	// -------------------------------------------------------------------------
+
+	invalid := []user.QueryFilter{
+		{StartCreatedDate: tm(time.Now()), EndCreatedDate: tm(start.Add(-time.Hour))},
+		{StartUpdatedDate: tm(time.Now()), EndUpdatedDate: tm(start.Add(-time.Hour))},
+		{Departments: []string{"IT", ""}},
+	}
+
+	for i, qf := range invalid {
+		if err := qf.Validate(); err == nil {
+			t.Errorf("Should reject invalid filter %d.", i)
+		}
+	}
+}
+
+func importUsers(t *testing.T) {
+	test := dbtest.NewTest(t, c)
+	defer func() {
+		if r := recover(); r != nil {
+			t.Log(r)
+			t.Error(string(debug.Stack()))
+		}
+		test.Teardown()
+	}()
+
+	api := test.CoreAPIs
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	existing := user.NewUser{
+		Name:            "John Doe",
+		Email:           mail.Address{Address: "john_doe@example.com"},
+		Roles:           []user.Role{user.RoleUser},
+		Department:      "Marketing",
+		Password:        "gophers",
+		PasswordConfirm: "gophers",
+	}
+
+	usr, err := api.User.Create(ctx, existing)
+	if err != nil {
+		t.Fatalf("Should be able to create user : %s.", err)
+	}
+
+	// -------------------------------------------------------------------------
+
+	updated := existing
+	updated.Name = "Johnny Doe"
+	updated.Password = "changed"
+	updated.PasswordConfirm = "changed"
+
+	created := user.NewUser{
+		Name:            "Jane Doe",
+		Email:           mail.Address{Address: "jane_doe@example.com"},
+		Roles:           []user.Role{user.RoleAdmin},
+		Password:        "gophers",
+		PasswordConfirm: "gophers",
+	}
+
+	usrs, err := api.User.Import(ctx, []user.NewUser{updated, created})
+	if err != nil {
+		t.Fatalf("Should be able to import users : %s.", err)
+	}
+
+	if len(usrs) != 2 {
+		t.Fatalf("Should get every imported user : got %d, exp 2.", len(usrs))
+	}
+
+	// -------------------------------------------------------------------------
+
+	hists, err := api.User.QueryHistory(ctx, usr.ID, 1, 10)
+	if err != nil {
+		t.Fatalf("Should be able to query user history : %s.", err)
+	}
+
+	if len(hists) != 2 || hists[0].Action != user.ActionImport {
+		t.Fatalf("Should have recorded the import of the existing user : %+v.", hists)
+	}
+
+	exp := map[string]user.Change{
+		"name": {Before: "John Doe", After: "Johnny Doe"},
+	}
+
+	if diff := cmp.Diff(hists[0].Diff, exp); diff != "" {
+		t.Errorf("Should only record the fields the import changed. Diff:\n%s", diff)
+	}
+
+	// -------------------------------------------------------------------------
+
+	var janeID uuid.UUID
+	for _, u := range usrs {
+		if u.Email.Address == created.Email.Address {
+			janeID = u.ID
+		}
+	}
+
+	hists, err = api.User.QueryHistory(ctx, janeID, 1, 10)
+	if err != nil {
+		t.Fatalf("Should be able to query user history : %s.", err)
+	}
+
+	if len(hists) != 1 || hists[0].Action != user.ActionImport {
+		t.Fatalf("Should have recorded the import of the new user : %+v.", hists)
+	}
+
+	for _, field := range []string{"name", "email", "roles", "enabled", "password"} {
+		change, exists := hists[0].Diff[field]
+		if !exists || change.Before != nil {
+			t.Errorf("Should record the %s of the new user without a before state : %+v.", field, change)
+		}
+	}
 }
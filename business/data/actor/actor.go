@@ -0,0 +1,21 @@
+// Package actor provides support for tracking who is performing an operation.
+package actor
+
+import (
+	"context"
+)
+
+type ctxKey int
+
+const key ctxKey = 1
+
+// Set stores the subject of the caller performing the operation.
+func Set(ctx context.Context, subject string) context.Context {
+	return context.WithValue(ctx, key, subject)
+}
+
+// Get retrieves the subject of the caller performing the operation.
+func Get(ctx context.Context) (string, bool) {
+	v, ok := ctx.Value(key).(string)
+	return v, ok
+}
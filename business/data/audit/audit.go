@@ -0,0 +1,245 @@
+// Package audit provides support for a tamper-evident, append-only audit log.
+// Every entry carries the SHA-256 hash of the previous entry so any edit made
+// to a record after the fact breaks the chain.
+package audit
+
+import (
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	db "github.com/1core-dev/go-service/business/data/dbsql/pgx"
+	"github.com/1core-dev/go-service/pkg/logger"
+	"github.com/jmoiron/sqlx"
+)
+
+// GenesisHash is the previous hash recorded on the first entry of the chain.
+const GenesisHash = "0000000000000000000000000000000000000000000000000000000000000000"
+
+// appendLockKey identifies the transaction level advisory lock taken to
+// append to the chain.
+const appendLockKey = 0x61756469746c6f67
+
+// verifyBatchSize is the number of entries read per round trip while
+// walking the chain.
+const verifyBatchSize = 1000
+
+// Entry represents a single record in the audit log.
+type Entry struct {
+	Seq         int64
+	Actor       string
+	Method      string
+	Route       string
+	Status      int
+	TraceID     string
+	DateCreated time.Time
+	PrevHash    string
+	Hash        string
+}
+
+// NewEntry contains information needed to append an entry to the log.
+type NewEntry struct {
+	Actor   string
+	Method  string
+	Route   string
+	Status  int
+	TraceID string
+}
+
+// ChainError describes the first entry found to break the chain.
+type ChainError struct {
+	Seq    int64
+	Reason string
+}
+
+// Error implements the error interface.
+func (ce *ChainError) Error() string {
+	return fmt.Sprintf("chain broken at seq[%d]: %s", ce.Seq, ce.Reason)
+}
+
+// IsChainError checks if an error of type ChainError exists.
+func IsChainError(err error) bool {
+	var ce *ChainError
+	return errors.As(err, &ce)
+}
+
+// =============================================================================
+
+// Log manages the set of APIs for audit log access.
+type Log struct {
+	log *logger.Logger
+	db  *sqlx.DB
+}
+
+// New constructs a Log for audit log access.
+func New(log *logger.Logger, db *sqlx.DB) *Log {
+	return &Log{
+		log: log,
+		db:  db,
+	}
+}
+
+// Append adds a new entry to the end of the chain. Writers are serialized
+// with an advisory lock so every entry chains off the latest one, while
+// readers of the table are never blocked.
+func (l *Log) Append(ctx context.Context, ne NewEntry) (Entry, error) {
+	tx, err := l.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return Entry{}, fmt.Errorf("begin: %w", err)
+	}
+
+	defer func() {
+		if err := tx.Rollback(); err != nil && !errors.Is(err, sql.ErrTxDone) {
+			l.log.Info(ctx, "audit: rollback", "ERROR", err)
+		}
+	}()
+
+	if _, err := tx.ExecContext(ctx, `SELECT pg_advisory_xact_lock($1)`, int64(appendLockKey)); err != nil {
+		return Entry{}, fmt.Errorf("lock: %w", err)
+	}
+
+	const qLast = `
+	SELECT
+		seq, actor, method, route, status, trace_id, date_created, prev_hash, hash
+	FROM
+		audit_log
+	ORDER BY
+		seq DESC
+	LIMIT 1`
+
+	last := dbEntry{Hash: GenesisHash}
+	if err := db.NamedQueryStruct(ctx, l.log, tx, qLast, struct{}{}, &last); err != nil {
+		if !errors.Is(err, db.ErrDBNotFound) {
+			return Entry{}, fmt.Errorf("namedquerystruct: %w", err)
+		}
+	}
+
+	// The database stores timestamps with microsecond precision so the time
+	// is truncated before hashing to keep the hash reproducible.
+	e := Entry{
+		Seq:         last.Seq + 1,
+		Actor:       ne.Actor,
+		Method:      ne.Method,
+		Route:       ne.Route,
+		Status:      ne.Status,
+		TraceID:     ne.TraceID,
+		DateCreated: time.Now().UTC().Truncate(time.Microsecond),
+		PrevHash:    last.Hash,
+	}
+	e.Hash = Hash(e)
+
+	const qInsert = `
+	INSERT INTO audit_log
+		(seq, actor, method, route, status, trace_id, date_created, prev_hash, hash)
+	VALUES
+		(:seq, :actor, :method, :route, :status, :trace_id, :date_created, :prev_hash, :hash)`
+
+	if err := db.NamedExecContext(ctx, l.log, tx, qInsert, toDBEntry(e)); err != nil {
+		return Entry{}, fmt.Errorf("namedexeccontext: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return Entry{}, fmt.Errorf("commit: %w", err)
+	}
+
+	return e, nil
+}
+
+// Verify walks the entire chain from the first entry and checks every link.
+// It returns the number of entries verified. If the chain is broken, a
+// ChainError describing the first break is returned.
+func (l *Log) Verify(ctx context.Context) (int, error) {
+	const q = `
+	SELECT
+		seq, actor, method, route, status, trace_id, date_created, prev_hash, hash
+	FROM
+		audit_log
+	WHERE
+		seq > :seq
+	ORDER BY
+		seq
+	LIMIT :limit`
+
+	prev := Entry{Hash: GenesisHash}
+	var verified int
+
+	for {
+		data := map[string]any{
+			"seq":   prev.Seq,
+			"limit": verifyBatchSize,
+		}
+
+		var dbEntries []dbEntry
+		if err := db.NamedQuerySlice(ctx, l.log, l.db, q, data, &dbEntries); err != nil {
+			return verified, fmt.Errorf("namedqueryslice: %w", err)
+		}
+
+		for _, dbE := range dbEntries {
+			e := toEntry(dbE)
+
+			if err := verifyLink(prev, e); err != nil {
+				return verified, err
+			}
+
+			prev = e
+			verified++
+		}
+
+		if len(dbEntries) < verifyBatchSize {
+			return verified, nil
+		}
+	}
+}
+
+// =============================================================================
+
+// Hash calculates the SHA-256 hash of the entry. The hash covers every field
+// of the entry, including the previous hash, except the hash itself.
+func Hash(e Entry) string {
+	doc := struct {
+		Seq         int64  `json:"seq"`
+		Actor       string `json:"actor"`
+		Method      string `json:"method"`
+		Route       string `json:"route"`
+		Status      int    `json:"status"`
+		TraceID     string `json:"traceID"`
+		DateCreated string `json:"dateCreated"`
+		PrevHash    string `json:"prevHash"`
+	}{
+		Seq:         e.Seq,
+		Actor:       e.Actor,
+		Method:      e.Method,
+		Route:       e.Route,
+		Status:      e.Status,
+		TraceID:     e.TraceID,
+		DateCreated: e.DateCreated.UTC().Format(time.RFC3339Nano),
+		PrevHash:    e.PrevHash,
+	}
+
+	// Marshaling a struct is deterministic since the field order is fixed.
+	data, _ := json.Marshal(doc)
+
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// verifyLink checks the entry is the direct successor of the previous entry.
+func verifyLink(prev Entry, e Entry) error {
+	switch {
+	case e.Seq != prev.Seq+1:
+		return &ChainError{Seq: e.Seq, Reason: fmt.Sprintf("missing entry, expected seq[%d]", prev.Seq+1)}
+
+	case e.PrevHash != prev.Hash:
+		return &ChainError{Seq: e.Seq, Reason: "previous hash does not match the previous entry"}
+
+	case e.Hash != Hash(e):
+		return &ChainError{Seq: e.Seq, Reason: "hash does not match the entry contents"}
+	}
+
+	return nil
+}
@@ -0,0 +1,216 @@
+package audit_test
+
+import (
+	"context"
+	"fmt"
+	"runtime/debug"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/1core-dev/go-service/business/data/audit"
+	"github.com/1core-dev/go-service/business/data/dbtest"
+)
+
+func Test_Hash(t *testing.T) {
+	e := audit.Entry{
+		Seq:         1,
+		Actor:       "5cf37266-3473-4006-984f-9325122678b7",
+		Method:      "POST",
+		Route:       "/v1/users",
+		Status:      201,
+		TraceID:     "4bf92f3577b34da6a3ce929d0e0e4736",
+		DateCreated: time.Date(2024, 1, 2, 3, 4, 5, 6000, time.UTC),
+		PrevHash:    audit.GenesisHash,
+	}
+
+	hash := audit.Hash(e)
+	if len(hash) != 64 {
+		t.Fatalf("Should get a hex encoded SHA-256 hash : %q.", hash)
+	}
+
+	if audit.Hash(e) != hash {
+		t.Errorf("Should get the same hash for the same entry.")
+	}
+
+	e.Hash = "ignored"
+	if audit.Hash(e) != hash {
+		t.Errorf("Should not include the hash in the hash.")
+	}
+
+	e.DateCreated = e.DateCreated.In(time.FixedZone("EST", -5*60*60))
+	if audit.Hash(e) != hash {
+		t.Errorf("Should get the same hash for the same time in another zone.")
+	}
+
+	changes := map[string]func(*audit.Entry){
+		"seq":      func(e *audit.Entry) { e.Seq++ },
+		"actor":    func(e *audit.Entry) { e.Actor = "45b5fbd3-755f-4379-8f07-a58d4a30fa2f" },
+		"method":   func(e *audit.Entry) { e.Method = "PUT" },
+		"route":    func(e *audit.Entry) { e.Route = "/v1/users/x" },
+		"status":   func(e *audit.Entry) { e.Status = 200 },
+		"traceID":  func(e *audit.Entry) { e.TraceID = "" },
+		"date":     func(e *audit.Entry) { e.DateCreated = e.DateCreated.Add(time.Microsecond) },
+		"prevHash": func(e *audit.Entry) { e.PrevHash = hash },
+	}
+
+	for name, change := range changes {
+		e2 := e
+		change(&e2)
+
+		if audit.Hash(e2) == hash {
+			t.Errorf("%s: Should get a different hash when the field changes.", name)
+		}
+	}
+}
+
+// =============================================================================
+
+func Test_Audit(t *testing.T) {
+	c, err := dbtest.StartDB()
+	if err != nil {
+		t.Skip(err)
+	}
+	defer dbtest.StopDB(c)
+
+	test := dbtest.NewTest(t, c)
+	defer func() {
+		if r := recover(); r != nil {
+			t.Log(r)
+			t.Error(string(debug.Stack()))
+		}
+		test.Teardown()
+	}()
+
+	ctx := context.Background()
+	al := audit.New(test.Log, test.DB)
+
+	// -------------------------------------------------------------------------
+
+	entries := make([]audit.Entry, 3)
+	for i := range entries {
+		e, err := al.Append(ctx, audit.NewEntry{
+			Actor:   "5cf37266-3473-4006-984f-9325122678b7",
+			Method:  "POST",
+			Route:   "/v1/users",
+			Status:  201,
+			TraceID: fmt.Sprintf("trace-%d", i),
+		})
+		if err != nil {
+			t.Fatalf("Should be able to append an entry : %s.", err)
+		}
+		entries[i] = e
+	}
+
+	prevHash := audit.GenesisHash
+	for i, e := range entries {
+		if e.Seq != int64(i+1) {
+			t.Errorf("Should get consecutive sequence numbers : got %d, exp %d.", e.Seq, i+1)
+		}
+
+		if e.PrevHash != prevHash {
+			t.Errorf("Should chain seq[%d] off the previous entry.", e.Seq)
+		}
+
+		if e.Hash != audit.Hash(e) {
+			t.Errorf("Should get the hash of seq[%d] over its contents.", e.Seq)
+		}
+
+		prevHash = e.Hash
+	}
+
+	// -------------------------------------------------------------------------
+
+	const appends = 20
+
+	var wg sync.WaitGroup
+	wg.Add(appends)
+
+	for i := range appends {
+		go func() {
+			defer wg.Done()
+
+			if _, err := al.Append(ctx, audit.NewEntry{Method: "GET", Route: "/v1/users", Status: 200, TraceID: fmt.Sprintf("concurrent-%d", i)}); err != nil {
+				t.Errorf("Should be able to append concurrently : %s.", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	total := len(entries) + appends
+
+	verified, err := al.Verify(ctx)
+	if err != nil {
+		t.Fatalf("Should be able to verify the chain : %s.", err)
+	}
+
+	if verified != total {
+		t.Fatalf("Should verify every entry : got %d, exp %d.", verified, total)
+	}
+
+	// -------------------------------------------------------------------------
+
+	// The table is append-only, so the triggers are disabled to simulate
+	// someone tampering with the rows directly.
+	tamper := func(q string) {
+		t.Helper()
+
+		tx, err := test.DB.BeginTxx(ctx, nil)
+		if err != nil {
+			t.Fatalf("Should be able to begin a transaction : %s.", err)
+		}
+		defer tx.Rollback()
+
+		for _, stmt := range []string{"ALTER TABLE audit_log DISABLE TRIGGER USER", q, "ALTER TABLE audit_log ENABLE TRIGGER USER"} {
+			if _, err := tx.ExecContext(ctx, stmt); err != nil {
+				t.Fatalf("Should be able to execute %q : %s.", stmt, err)
+			}
+		}
+
+		if err := tx.Commit(); err != nil {
+			t.Fatalf("Should be able to commit : %s.", err)
+		}
+	}
+
+	if _, err := test.DB.ExecContext(ctx, "UPDATE audit_log SET status = 500 WHERE seq = 2"); err == nil {
+		t.Fatalf("Should not be able to modify an entry.")
+	}
+
+	tamper("UPDATE audit_log SET status = 500 WHERE seq = 2")
+	verifyBroken(t, al, 2, 1)
+
+	tamper("UPDATE audit_log SET status = 201 WHERE seq = 2")
+	if _, err := al.Verify(ctx); err != nil {
+		t.Fatalf("Should verify the chain once restored : %s.", err)
+	}
+
+	tamper("UPDATE audit_log SET hash = '" + audit.GenesisHash + "' WHERE seq = 3")
+	verifyBroken(t, al, 3, 2)
+
+	tamper(fmt.Sprintf("UPDATE audit_log SET hash = '%s' WHERE seq = 3", entries[2].Hash))
+	if _, err := al.Verify(ctx); err != nil {
+		t.Fatalf("Should verify the chain once restored : %s.", err)
+	}
+
+	tamper("DELETE FROM audit_log WHERE seq = 2")
+	verifyBroken(t, al, 3, 1)
+}
+
+func verifyBroken(t *testing.T, al *audit.Log, seq int64, verified int) {
+	t.Helper()
+
+	n, err := al.Verify(context.Background())
+
+	ce, ok := err.(*audit.ChainError)
+	if !ok || !audit.IsChainError(err) {
+		t.Fatalf("Should get a chain error : %v.", err)
+	}
+
+	if ce.Seq != seq {
+		t.Errorf("Should report the first broken entry : got seq[%d], exp seq[%d].", ce.Seq, seq)
+	}
+
+	if n != verified {
+		t.Errorf("Should report the entries verified before the break : got %d, exp %d.", n, verified)
+	}
+}
@@ -0,0 +1,47 @@
+package audit
+
+import (
+	"time"
+)
+
+// dbEntry represent the structure we need for moving data
+// between the app and the database.
+type dbEntry struct {
+	Seq         int64     `db:"seq"`
+	Actor       string    `db:"actor"`
+	Method      string    `db:"method"`
+	Route       string    `db:"route"`
+	Status      int       `db:"status"`
+	TraceID     string    `db:"trace_id"`
+	DateCreated time.Time `db:"date_created"`
+	PrevHash    string    `db:"prev_hash"`
+	Hash        string    `db:"hash"`
+}
+
+func toDBEntry(e Entry) dbEntry {
+	return dbEntry{
+		Seq:         e.Seq,
+		Actor:       e.Actor,
+		Method:      e.Method,
+		Route:       e.Route,
+		Status:      e.Status,
+		TraceID:     e.TraceID,
+		DateCreated: e.DateCreated.UTC(),
+		PrevHash:    e.PrevHash,
+		Hash:        e.Hash,
+	}
+}
+
+func toEntry(dbE dbEntry) Entry {
+	return Entry{
+		Seq:         dbE.Seq,
+		Actor:       dbE.Actor,
+		Method:      dbE.Method,
+		Route:       dbE.Route,
+		Status:      dbE.Status,
+		TraceID:     dbE.TraceID,
+		DateCreated: dbE.DateCreated.UTC(),
+		PrevHash:    dbE.PrevHash,
+		Hash:        dbE.Hash,
+	}
+}
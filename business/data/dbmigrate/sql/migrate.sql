@@ -12,4 +12,67 @@
  	date_updated  TIMESTAMP   NOT NULL,
  
  	PRIMARY KEY (user_id)
- );
\ No newline at end of file
+ );
+
+-- Version: 1.02
+-- Description: Create table user_history
+CREATE TABLE user_history (
+	history_id   UUID      NOT NULL,
+	user_id      UUID      NOT NULL,
+	action       TEXT      NOT NULL,
+	diff         JSONB     NOT NULL,
+	actor        TEXT      NOT NULL,
+	trace_id     TEXT      NOT NULL,
+	date_created TIMESTAMP NOT NULL,
+
+	PRIMARY KEY (history_id)
+);
+
+CREATE INDEX user_history_user_id_idx ON user_history (user_id, date_created);
+
+-- Version: 1.03
+-- Description: Create append-only table audit_log
+CREATE TABLE audit_log (
+	seq          BIGINT    NOT NULL,
+	actor        TEXT      NOT NULL,
+	method       TEXT      NOT NULL,
+	route        TEXT      NOT NULL,
+	status       INT       NOT NULL,
+	trace_id     TEXT      NOT NULL,
+	date_created TIMESTAMP NOT NULL,
+	prev_hash    TEXT      NOT NULL,
+	hash         TEXT      NOT NULL,
+
+	PRIMARY KEY (seq)
+);
+
+CREATE FUNCTION audit_log_append_only() RETURNS TRIGGER AS $$
+BEGIN
+	RAISE EXCEPTION 'audit_log is append-only';
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER audit_log_no_modify
+	BEFORE UPDATE OR DELETE ON audit_log
+	FOR EACH ROW EXECUTE FUNCTION audit_log_append_only();
+
+CREATE TRIGGER audit_log_no_truncate
+	BEFORE TRUNCATE ON audit_log
+	FOR EACH STATEMENT EXECUTE FUNCTION audit_log_append_only();
+
+-- Version: 1.04
+-- Description: Add version column to users
+ALTER TABLE users ADD COLUMN version INT NOT NULL DEFAULT 1;
+-- Version: 1.05
+-- Description: Add full-text and trigram search for users
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
+ALTER TABLE users ADD COLUMN search TSVECTOR GENERATED ALWAYS AS (
+	setweight(to_tsvector('simple', coalesce(name, '')), 'A') ||
+	setweight(to_tsvector('simple', coalesce(email, '')), 'B') ||
+	setweight(to_tsvector('simple', coalesce(department, '')), 'C')
+) STORED;
+
+CREATE INDEX users_search_idx ON users USING GIN (search);
+CREATE INDEX users_name_trgm_idx ON users USING GIN (name gin_trgm_ops);
+CREATE INDEX users_email_trgm_idx ON users USING GIN (email gin_trgm_ops);
@@ -0,0 +1,141 @@
+package sqldb_test
+
+import (
+	"context"
+	"errors"
+	"runtime/debug"
+	"strconv"
+	"testing"
+
+	db "github.com/1core-dev/go-service/business/data/dbsql/pgx"
+	"github.com/1core-dev/go-service/business/data/dbtest"
+)
+
+type number struct {
+	N     int    `db:"n"`
+	Label string `db:"label"`
+}
+
+func Test_NamedQueryCursor(t *testing.T) {
+	c, err := dbtest.StartDB()
+	if err != nil {
+		t.Skip(err)
+	}
+	defer dbtest.StopDB(c)
+
+	test := dbtest.NewTest(t, c)
+	defer func() {
+		if r := recover(); r != nil {
+			t.Log(r)
+			t.Error(string(debug.Stack()))
+		}
+		test.Teardown()
+	}()
+
+	ctx := context.Background()
+
+	const q = `
+	SELECT
+		n, 'row ' || n AS label
+	FROM
+		generate_series(1, :total) AS n
+	ORDER BY
+		n`
+
+	// -------------------------------------------------------------------------
+
+	tests := []struct {
+		name      string
+		total     int
+		fetchSize int
+	}{
+		{name: "batches", total: 25, fetchSize: 10},
+		{name: "exact", total: 20, fetchSize: 10},
+		{name: "single", total: 3, fetchSize: 10},
+		{name: "empty", total: 0, fetchSize: 10},
+	}
+
+	for _, tt := range tests {
+		data := struct {
+			Total int `db:"total"`
+		}{
+			Total: tt.total,
+		}
+
+		var got []number
+		f := func(n number) error {
+			got = append(got, n)
+			return nil
+		}
+
+		if err := db.NamedQueryCursor(ctx, test.Log, test.DB, q, data, tt.fetchSize, f); err != nil {
+			t.Errorf("%s: Should be able to read the rows : %s.", tt.name, err)
+			continue
+		}
+
+		if len(got) != tt.total {
+			t.Errorf("%s: Should get every row : got %d, exp %d.", tt.name, len(got), tt.total)
+			continue
+		}
+
+		for i, n := range got {
+			if n.N != i+1 || n.Label != "row "+strconv.Itoa(i+1) {
+				t.Errorf("%s: Should get the rows in order : got %+v at %d.", tt.name, n, i)
+				break
+			}
+		}
+	}
+
+	// -------------------------------------------------------------------------
+
+	errStop := errors.New("stop")
+
+	var calls int
+	f := func(n number) error {
+		calls++
+		if n.N == 15 {
+			return errStop
+		}
+		return nil
+	}
+
+	data := struct {
+		Total int `db:"total"`
+	}{
+		Total: 100,
+	}
+
+	if err := db.NamedQueryCursor(ctx, test.Log, test.DB, q, data, 10, f); !errors.Is(err, errStop) {
+		t.Errorf("Should get the error returned by the function : %v.", err)
+	}
+
+	if calls != 15 {
+		t.Errorf("Should stop at the first error : got %d calls, exp 15.", calls)
+	}
+
+	// -------------------------------------------------------------------------
+
+	tx, err := test.DB.BeginTxx(ctx, nil)
+	if err != nil {
+		t.Fatalf("Should be able to begin a transaction : %s.", err)
+	}
+	defer tx.Rollback()
+
+	var rows int
+	f = func(n number) error {
+		rows++
+		return nil
+	}
+
+	if err := db.NamedQueryCursor(ctx, test.Log, tx, q, data, 30, f); err != nil {
+		t.Fatalf("Should be able to read the rows under a transaction : %s.", err)
+	}
+
+	if rows != 100 {
+		t.Errorf("Should get every row under a transaction : got %d, exp 100.", rows)
+	}
+
+	if err := tx.Commit(); err != nil {
+		t.Errorf("Should be able to use the transaction after the cursor : %s.", err)
+	}
+}
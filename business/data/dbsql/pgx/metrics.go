@@ -0,0 +1,135 @@
+package sqldb
+
+import (
+	"context"
+	"errors"
+	"runtime"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/1core-dev/go-service/pkg/logger"
+	"github.com/1core-dev/go-service/pkg/web"
+	"github.com/jmoiron/sqlx"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+)
+
+// Set of metrics recorded for every query run through the helpers. Queries
+// are named after the function that called the helper, which keeps the
+// number of label values bounded.
+var (
+	queryDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "db_query_duration_seconds",
+		Help:    "Duration of the database queries.",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"query"})
+
+	queryErrors = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "db_query_errors_total",
+		Help: "Number of database queries that failed.",
+	}, []string{"query"})
+)
+
+// Collectors returns the collectors for the query metrics along with one
+// reporting the connection pool statistics of the database each time the
+// metrics are gathered.
+func Collectors(db *sqlx.DB, dbName string) []prometheus.Collector {
+	return []prometheus.Collector{
+		queryDuration,
+		queryErrors,
+		collectors.NewDBStatsCollector(db.DB, dbName),
+	}
+}
+
+// =============================================================================
+
+// track starts a span and a timer for the query. The returned function must
+// be called with the outcome of the query to record the span, the metrics
+// and the slow query log. Only the statement with its named parameters is
+// recorded, never the values.
+func track(ctx context.Context, log *logger.Logger, spanName string, query string) (context.Context, func(error)) {
+	name := queryName()
+	statement := strings.Join(strings.Fields(query), " ")
+
+	ctx, span := web.AddSpan(ctx, spanName,
+		attribute.String("db.system", "postgresql"),
+		attribute.String("db.operation.name", name),
+		attribute.String("db.statement", statement),
+	)
+
+	start := time.Now()
+
+	done := func(err error) {
+		d := time.Since(start)
+
+		queryDuration.WithLabelValues(name).Observe(d.Seconds())
+
+		if err != nil && !errors.Is(err, ErrDBNotFound) {
+			queryErrors.WithLabelValues(name).Inc()
+
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		}
+
+		if threshold := time.Duration(slowQueryThreshold.Load()); threshold > 0 && d > threshold {
+			log.Warn(ctx, "slow query", "query", name, "duration", d, "threshold", threshold, "statement", statement)
+		}
+
+		span.End()
+	}
+
+	return ctx, done
+}
+
+// queryPkg is the prefix of the functions of this package on the stack.
+const queryPkg = "github.com/1core-dev/go-service/business/data/dbsql/pgx."
+
+// queryNames caches the name of the query for each program counter on the
+// stack so the symbols are only resolved once per call site. An empty name
+// marks a program counter inside of this package.
+var queryNames sync.Map
+
+// queryName returns the name of the first function on the stack outside of
+// this package, like userdb.(*Store).QueryByID.
+func queryName() string {
+	var pcs [16]uintptr
+	n := runtime.Callers(2, pcs[:])
+
+	for _, pc := range pcs[:n] {
+		v, ok := queryNames.Load(pc)
+		if !ok {
+			v, _ = queryNames.LoadOrStore(pc, funcName(pc))
+		}
+
+		if name := v.(string); name != "" {
+			return name
+		}
+	}
+
+	return "unknown"
+}
+
+// funcName returns the short name of the first function outside of this
+// package for the program counter, which can stand for several functions
+// once they are inlined. It returns an empty string if there is none.
+func funcName(pc uintptr) string {
+	frames := runtime.CallersFrames([]uintptr{pc})
+
+	for {
+		frame, more := frames.Next()
+		if frame.Function != "" && !strings.HasPrefix(frame.Function, queryPkg) {
+			name := frame.Function
+			if i := strings.LastIndex(name, "/"); i >= 0 {
+				name = name[i+1:]
+			}
+			return name
+		}
+
+		if !more {
+			return ""
+		}
+	}
+}
@@ -0,0 +1,159 @@
+package sqldb
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/1core-dev/go-service/pkg/logger"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+)
+
+// The tests run inside of this package so the first function on the stack
+// outside of it is the test runner.
+const testQueryName = "testing.tRunner"
+
+func Test_Metrics(t *testing.T) {
+	t.Run("queryName", queryNameCaller)
+	t.Run("track", trackQuery)
+	t.Run("slow", trackSlow)
+}
+
+// =============================================================================
+
+func queryErrorCount(t *testing.T, name string) float64 {
+	t.Helper()
+
+	var m dto.Metric
+	if err := queryErrors.WithLabelValues(name).Write(&m); err != nil {
+		t.Fatalf("Should be able to read the error count : %s.", err)
+	}
+
+	return m.GetCounter().GetValue()
+}
+
+func queryCount(t *testing.T, name string) uint64 {
+	t.Helper()
+
+	var m dto.Metric
+	if err := queryDuration.WithLabelValues(name).(prometheus.Metric).Write(&m); err != nil {
+		t.Fatalf("Should be able to read the durations : %s.", err)
+	}
+
+	return m.GetHistogram().GetSampleCount()
+}
+
+// =============================================================================
+
+func queryNameCaller(t *testing.T) {
+	if name := queryName(); name != testQueryName {
+		t.Errorf("Should get the first function outside of the package : got %q, exp %q.", name, testQueryName)
+	}
+
+	// Through a helper of the package, like the exported functions do.
+	helper := func() string { return queryName() }
+	if name := helper(); name != testQueryName {
+		t.Errorf("Should skip the functions of the package : got %q, exp %q.", name, testQueryName)
+	}
+
+	if name := queryName(); name != testQueryName {
+		t.Errorf("Should get the same name from the cache : got %q, exp %q.", name, testQueryName)
+	}
+
+	// -------------------------------------------------------------------------
+
+	var pcs [2]uintptr
+	runtime.Callers(1, pcs[:])
+
+	if name := funcName(pcs[0]); name != "" {
+		t.Errorf("Should not name the functions of the package : %q.", name)
+	}
+
+	if name := funcName(pcs[1]); name != testQueryName {
+		t.Errorf("Should get the short name of the function : got %q, exp %q.", name, testQueryName)
+	}
+
+	var cached bool
+	queryNames.Range(func(_, v any) bool {
+		cached = v.(string) == testQueryName
+		return !cached
+	})
+
+	if !cached {
+		t.Errorf("Should cache the names of the call sites.")
+	}
+}
+
+func trackQuery(t *testing.T) {
+	var buf bytes.Buffer
+	log := logger.New(&buf, logger.LevelInfo, "TEST", nil)
+
+	errCount := queryErrorCount(t, testQueryName)
+	count := queryCount(t, testQueryName)
+
+	tests := []struct {
+		name   string
+		err    error
+		errInc float64
+	}{
+		{name: "success", err: nil, errInc: 0},
+		{name: "notFound", err: ErrDBNotFound, errInc: 0},
+		{name: "failed", err: errors.New("failed"), errInc: 1},
+	}
+
+	for _, tt := range tests {
+		_, done := track(context.Background(), log, "test.query", "SELECT 1")
+		done(tt.err)
+
+		count++
+		if got := queryCount(t, testQueryName); got != count {
+			t.Errorf("%s: Should record the duration of the query : got %d, exp %d.", tt.name, got, count)
+		}
+
+		errCount += tt.errInc
+		if got := queryErrorCount(t, testQueryName); got != errCount {
+			t.Errorf("%s: Should get the expected error count : got %v, exp %v.", tt.name, got, errCount)
+		}
+	}
+
+	if buf.Len() != 0 {
+		t.Errorf("Should not log the queries without a threshold : %s.", buf.String())
+	}
+}
+
+func trackSlow(t *testing.T) {
+	defer slowQueryThreshold.Store(slowQueryThreshold.Load())
+
+	var buf bytes.Buffer
+	log := logger.New(&buf, logger.LevelInfo, "TEST", nil)
+
+	slowQueryThreshold.Store(int64(time.Hour))
+
+	_, done := track(context.Background(), log, "test.query", "SELECT 1")
+	done(nil)
+
+	if buf.Len() != 0 {
+		t.Errorf("Should not log the queries under the threshold : %s.", buf.String())
+	}
+
+	// -------------------------------------------------------------------------
+
+	slowQueryThreshold.Store(int64(time.Millisecond))
+
+	_, done = track(context.Background(), log, "test.query", "SELECT *\n\tFROM users")
+	time.Sleep(2 * time.Millisecond)
+	done(nil)
+
+	out := buf.String()
+
+	for _, s := range []string{`"msg":"slow query"`, `"query":"` + testQueryName + `"`, `"statement":"SELECT * FROM users"`, `"threshold":1000000`} {
+		if !strings.Contains(out, s) {
+			t.Errorf("Should log %s : %s.", s, out)
+		}
+	}
+}
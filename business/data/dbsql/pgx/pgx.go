@@ -8,6 +8,7 @@ import (
 	"fmt"
 	"net/url"
 	"strings"
+	"sync/atomic"
 	"time"
 
 	"github.com/1core-dev/go-service/pkg/logger"
@@ -30,6 +31,17 @@ var (
 	ErrUndefinedTable    = errors.New("undefined table")
 )
 
+// Set of logging settings for the queries run through the helpers. The
+// helpers accept any sqlx.ExtContext, including transactions, so the settings
+// can't be kept with a database and are shared by the whole process instead.
+// They are set by every call to Open, the last one wins, see Config.
+var (
+	slowQueryThreshold atomic.Int64
+	queryLogLevel      atomic.Int64
+	queryLogSampling   atomic.Int64
+	queryLogCount      atomic.Uint64
+)
+
 // Config is the required properties to use the database.
 type Config struct {
 	User         string
@@ -40,9 +52,21 @@ type Config struct {
 	MaxIdleConns int
 	MaxOpenConns int
 	DisableTLS   bool
+
+	// SlowQueryThreshold is the duration above which queries are logged as
+	// slow. Zero disables the log. These settings apply to every database of
+	// the process and are replaced each time Open is called.
+	SlowQueryThreshold time.Duration
+
+	// QueryLogLevel is the level queries are logged at and QueryLogSampling
+	// logs only one in every n queries. Values of 0 and 1 log every query.
+	QueryLogLevel    logger.Level
+	QueryLogSampling int
 }
 
 // Open knows how to open a database connection based on the configuration.
+// It also sets the query logging settings of the process, which apply to
+// the databases opened before as well.
 func Open(cfg Config) (*sqlx.DB, error) {
 	sslMode := "require"
 	if cfg.DisableTLS {
@@ -71,6 +95,10 @@ func Open(cfg Config) (*sqlx.DB, error) {
 	db.SetMaxIdleConns(cfg.MaxIdleConns)
 	db.SetMaxOpenConns(cfg.MaxOpenConns)
 
+	slowQueryThreshold.Store(int64(cfg.SlowQueryThreshold))
+	queryLogLevel.Store(int64(cfg.QueryLogLevel))
+	queryLogSampling.Store(int64(cfg.QueryLogSampling))
+
 	return db, nil
 }
 
@@ -108,13 +136,14 @@ func StatusCheck(ctx context.Context, db *sqlx.DB) error {
 
 // NamedExecContext is a helper function to execute a CUD operation with
 // logging and tracing where field replacement is necessary.
-func NamedExecContext(ctx context.Context, log *logger.Logger, db sqlx.ExtContext, query string, data any) error {
-	q := queryString(query, data)
+func NamedExecContext(ctx context.Context, log *logger.Logger, db sqlx.ExtContext, query string, data any) (err error) {
+	ctx, done := track(ctx, log, "business.data.dbsql.exec", query)
+	defer func() { done(err) }()
 
 	if _, ok := data.(struct{}); ok {
-		log.Infoc(ctx, 5, "database.NamedExecContext", "query", q)
+		logQuery(ctx, log, 6, "database.NamedExecContext", query, data)
 	} else {
-		log.Infoc(ctx, 4, "database.NamedExecContext", "query", q)
+		logQuery(ctx, log, 5, "database.NamedExecContext", query, data)
 	}
 
 	if _, err := sqlx.NamedExecContext(ctx, db, query, data); err != nil {
@@ -138,13 +167,13 @@ func NamedQueryStruct(ctx context.Context, log *logger.Logger, db sqlx.ExtContex
 	return namedQueryStruct(ctx, log, db, query, data, dest, false)
 }
 
-func namedQueryStruct(ctx context.Context, log *logger.Logger, db sqlx.ExtContext, query string, data any, dest any, withIn bool) error {
-	q := queryString(query, data)
+func namedQueryStruct(ctx context.Context, log *logger.Logger, db sqlx.ExtContext, query string, data any, dest any, withIn bool) (err error) {
+	ctx, done := track(ctx, log, "business.data.dbsql.querystruct", query)
+	defer func() { done(err) }()
 
-	log.Infoc(ctx, 5, "database.NamedQueryStruct", "query", q)
+	logQuery(ctx, log, 6, "database.NamedQueryStruct", query, data)
 
 	var rows *sqlx.Rows
-	var err error
 
 	switch withIn {
 	case true:
@@ -168,14 +197,25 @@ func namedQueryStruct(ctx context.Context, log *logger.Logger, db sqlx.ExtContex
 	}
 
 	if err != nil {
-		if pqerr, ok := err.(*pgconn.PgError); ok && pqerr.Code == undefinedTable {
-			return ErrUndefinedTable
+		if pqerr, ok := err.(*pgconn.PgError); ok {
+			switch pqerr.Code {
+			case undefinedTable:
+				return ErrUndefinedTable
+			case uniqueViolation:
+				return ErrDBDuplicatedEntry
+			}
 		}
 		return err
 	}
 	defer rows.Close()
 
 	if !rows.Next() {
+		if err := rows.Err(); err != nil {
+			if pqerr, ok := err.(*pgconn.PgError); ok && pqerr.Code == uniqueViolation {
+				return ErrDBDuplicatedEntry
+			}
+			return err
+		}
 		return ErrDBNotFound
 	}
 
@@ -193,13 +233,13 @@ func NamedQuerySlice[T any](ctx context.Context, log *logger.Logger, db sqlx.Ext
 	return namedQuerySlice(ctx, log, db, query, data, dest, false)
 }
 
-func namedQuerySlice[T any](ctx context.Context, log *logger.Logger, db sqlx.ExtContext, query string, data any, dest *[]T, withIn bool) error {
-	q := queryString(query, data)
+func namedQuerySlice[T any](ctx context.Context, log *logger.Logger, db sqlx.ExtContext, query string, data any, dest *[]T, withIn bool) (err error) {
+	ctx, done := track(ctx, log, "business.data.dbsql.queryslice", query)
+	defer func() { done(err) }()
 
-	log.Infoc(ctx, 5, "database.NamedQuerySlice", "query", q)
+	logQuery(ctx, log, 6, "database.NamedQuerySlice", query, data)
 
 	var rows *sqlx.Rows
-	var err error
 
 	switch withIn {
 	case true:
@@ -238,35 +278,178 @@ func namedQuerySlice[T any](ctx context.Context, log *logger.Logger, db sqlx.Ext
 		}
 		slice = append(slice, *v)
 	}
+
+	if err := rows.Err(); err != nil {
+		if pqerr, ok := err.(*pgconn.PgError); ok && pqerr.Code == uniqueViolation {
+			return ErrDBDuplicatedEntry
+		}
+		return err
+	}
+
 	*dest = slice
 
 	return nil
 }
 
+// NamedQueryCursor is a helper function for executing queries whose result
+// is too large to hold in memory. The rows are read through a server-side
+// cursor in batches of fetchSize and handed to the fn function one at a
+// time. Processing stops at the first error returned by fn.
+func NamedQueryCursor[T any](ctx context.Context, log *logger.Logger, db sqlx.ExtContext, query string, data any, fetchSize int, fn func(T) error) (err error) {
+	const cursor = "query_cursor"
+
+	ctx, done := track(ctx, log, "business.data.dbsql.querycursor", query)
+	defer func() { done(err) }()
+
+	logQuery(ctx, log, 6, "database.NamedQueryCursor", query, data)
+
+	named, args, err := sqlx.Named(query, data)
+	if err != nil {
+		return err
+	}
+
+	// A cursor only lives inside of a transaction, so one is started unless
+	// the caller is already executing under one.
+	commit := func() error { return nil }
+	if sqlxDB, ok := db.(*sqlx.DB); ok {
+		tx, err := sqlxDB.BeginTxx(ctx, &sql.TxOptions{ReadOnly: true})
+		if err != nil {
+			return fmt.Errorf("begin: %w", err)
+		}
+		defer tx.Rollback()
+
+		db = tx
+		commit = tx.Commit
+	}
+
+	declare := "DECLARE " + cursor + " NO SCROLL CURSOR FOR " + db.Rebind(named)
+	if _, err := db.ExecContext(ctx, declare, args...); err != nil {
+		if pqerr, ok := err.(*pgconn.PgError); ok && pqerr.Code == undefinedTable {
+			return ErrUndefinedTable
+		}
+		return err
+	}
+
+	fetch := fmt.Sprintf("FETCH FORWARD %d FROM %s", fetchSize, cursor)
+
+	for {
+		n, err := fetchCursor(ctx, db, fetch, fn)
+		if err != nil {
+			return err
+		}
+
+		if n < fetchSize {
+			break
+		}
+	}
+
+	if _, err := db.ExecContext(ctx, "CLOSE "+cursor); err != nil {
+		return fmt.Errorf("close cursor: %w", err)
+	}
+
+	return commit()
+}
+
+// fetchCursor reads the next batch of rows from the cursor and returns the
+// number of rows that were read.
+func fetchCursor[T any](ctx context.Context, db sqlx.ExtContext, fetch string, fn func(T) error) (int, error) {
+	rows, err := db.QueryxContext(ctx, fetch)
+	if err != nil {
+		return 0, err
+	}
+	defer rows.Close()
+
+	var n int
+	for rows.Next() {
+		v := new(T)
+		if err := rows.StructScan(v); err != nil {
+			return n, err
+		}
+
+		if err := fn(*v); err != nil {
+			return n, err
+		}
+		n++
+	}
+
+	return n, rows.Err()
+}
+
 // =============================================================================
 
+// logQuery logs the query with its redacted values at the configured level
+// for the sampled queries.
+func logQuery(ctx context.Context, log *logger.Logger, caller int, msg string, query string, data any) {
+	if n := queryLogSampling.Load(); n > 1 && queryLogCount.Add(1)%uint64(n) != 0 {
+		return
+	}
+
+	q := queryString(query, data)
+
+	switch logger.Level(queryLogLevel.Load()) {
+	case logger.LevelDebug:
+		log.Debugc(ctx, caller, msg, "query", q)
+	case logger.LevelWarn:
+		log.Warnc(ctx, caller, msg, "query", q)
+	case logger.LevelError:
+		log.Errorc(ctx, caller, msg, "query", q)
+	default:
+		log.Infoc(ctx, caller, msg, "query", q)
+	}
+}
+
 // queryString provides a pretty print version of the query and parameters.
+// The values of sensitive parameters are redacted, see redactNames.
 func queryString(query string, args any) string {
+	names := paramNames(query)
+
 	query, params, err := sqlx.Named(query, args)
 	if err != nil {
 		return err.Error()
 	}
 
-	for _, param := range params {
-		var value string
+	redact := redactNames(args)
+
+	var b strings.Builder
+	for i, param := range params {
+		n := strings.IndexByte(query, '?')
+		if n < 0 {
+			break
+		}
+
+		b.WriteString(query[:n])
+		query = query[n+1:]
+
+		if len(names) == 0 || redactParam(redact, paramName(names, i, len(params))) {
+			b.WriteString(redacted)
+			continue
+		}
+
 		switch v := param.(type) {
 		case string:
-			value = fmt.Sprintf("'%s'", v)
+			fmt.Fprintf(&b, "'%s'", v)
 		case []byte:
-			value = fmt.Sprintf("'%s'", string(v))
+			fmt.Fprintf(&b, "'%s'", string(v))
 		default:
-			value = fmt.Sprintf("%v", v)
+			fmt.Fprintf(&b, "%v", v)
 		}
-		query = strings.Replace(query, "?", value, 1)
 	}
+	b.WriteString(query)
 
-	query = strings.ReplaceAll(query, "\t", "")
+	query = strings.ReplaceAll(b.String(), "\t", "")
 	query = strings.ReplaceAll(query, "\n", " ")
 
 	return strings.Trim(query, " ")
 }
+
+// paramName returns the name of the i-th of n bound parameters. Bulk
+// queries repeat the names of a row for every element, so the names are
+// cycled through. When the names can't be matched to the parameters an
+// empty name is returned, which is always redacted.
+func paramName(names []string, i int, n int) string {
+	if n%len(names) != 0 {
+		return ""
+	}
+
+	return names[i%len(names)]
+}
@@ -0,0 +1,123 @@
+package sqldb
+
+import (
+	"reflect"
+	"strings"
+	"unicode"
+)
+
+// redacted replaces the value of a sensitive parameter in a logged query.
+const redacted = "'[REDACTED]'"
+
+// redactColumns lists the columns whose values are never logged, whatever
+// the type of the data they come from. An empty name is used for parameters
+// that can't be named. Generated parameters are named after the column they
+// bind to, like email_filter_0, so they are redacted as well, see redactParam.
+var redactColumns = map[string]bool{
+	"":              true,
+	"name":          true,
+	"password":      true,
+	"password_hash": true,
+	"email":         true,
+	"token":         true,
+}
+
+// redactNames returns the names of the parameters in the data that must be
+// redacted. Along with the columns that are always redacted, struct fields
+// tagged with `log:"redact"` are redacted under the name of their db tag.
+// The element type is used for slices of structs.
+func redactNames(data any) map[string]bool {
+	names := make(map[string]bool, len(redactColumns))
+	for name := range redactColumns {
+		names[name] = true
+	}
+
+	t := reflect.TypeOf(data)
+	for t != nil && (t.Kind() == reflect.Pointer || t.Kind() == reflect.Slice || t.Kind() == reflect.Array) {
+		t = t.Elem()
+	}
+
+	if t != nil && t.Kind() == reflect.Struct {
+		redactFields(t, names)
+	}
+
+	return names
+}
+
+// redactParam reports whether the value of the parameter must be redacted.
+// A parameter is redacted when its name is one of the redacted names or
+// starts with one followed by an underscore.
+func redactParam(names map[string]bool, param string) bool {
+	if names[param] {
+		return true
+	}
+
+	for i := 1; i < len(param); i++ {
+		if param[i] == '_' && names[param[:i]] {
+			return true
+		}
+	}
+
+	return false
+}
+
+func redactFields(t reflect.Type, names map[string]bool) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+
+		name, _, _ := strings.Cut(field.Tag.Get("db"), ",")
+
+		ft := field.Type
+		if ft.Kind() == reflect.Pointer {
+			ft = ft.Elem()
+		}
+
+		if field.Anonymous && name == "" && ft.Kind() == reflect.Struct {
+			redactFields(ft, names)
+			continue
+		}
+
+		if name == "" {
+			name = strings.ToLower(field.Name)
+		}
+
+		if field.Tag.Get("log") == "redact" {
+			names[name] = true
+		}
+	}
+}
+
+// paramNames returns the names of the parameters in the query in the order
+// they are bound. It follows the rules sqlx uses to compile named queries,
+// where :: is an escaped colon rather than a parameter.
+func paramNames(query string) []string {
+	var names []string
+
+	for i := 0; i < len(query); i++ {
+		if query[i] != ':' {
+			continue
+		}
+
+		if i+1 < len(query) && query[i+1] == ':' {
+			i++
+			continue
+		}
+
+		j := i + 1
+		for j < len(query) && isNameByte(query[j]) {
+			j++
+		}
+
+		if j > i+1 {
+			names = append(names, query[i+1:j])
+		}
+
+		i = j - 1
+	}
+
+	return names
+}
+
+func isNameByte(b byte) bool {
+	return b == '_' || b == '.' || unicode.IsLetter(rune(b)) || unicode.IsDigit(rune(b))
+}
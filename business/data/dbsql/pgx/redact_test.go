@@ -0,0 +1,205 @@
+package sqldb
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/1core-dev/go-service/business/data/filter"
+)
+
+type testUser struct {
+	ID           string `db:"user_id"`
+	Name         string `db:"name"`
+	Email        string `db:"email"`
+	PasswordHash []byte `db:"password_hash"`
+	Secret       string `db:"secret" log:"redact"`
+}
+
+type testAudited struct {
+	testUser
+	Actor string `db:"actor"`
+}
+
+func Test_Redact(t *testing.T) {
+	t.Run("struct", redactStruct)
+	t.Run("map", redactMap)
+	t.Run("bulk", redactBulk)
+	t.Run("embedded", redactEmbedded)
+	t.Run("syntax", redactSyntax)
+	t.Run("generated", redactGenerated)
+}
+
+// =============================================================================
+
+const (
+	name   = "Kennedy"
+	email  = "bill@example.com"
+	hash   = "$2a$10$abcdefghijklmnopqrstuv"
+	secret = "s3cr3t"
+)
+
+var usr = testUser{
+	ID:           "45b5fbd3",
+	Name:         "Bill Kennedy",
+	Email:        email,
+	PasswordHash: []byte(hash),
+	Secret:       secret,
+}
+
+func redactStruct(t *testing.T) {
+	const q = `
+	INSERT INTO users
+		(user_id, name, email, password_hash, secret)
+	VALUES
+		(:user_id, :name, :email, :password_hash, :secret)`
+
+	got := queryString(q, usr)
+	exp := "INSERT INTO users (user_id, name, email, password_hash, secret) VALUES ('45b5fbd3', '[REDACTED]', '[REDACTED]', '[REDACTED]', '[REDACTED]')"
+
+	if got != exp {
+		t.Errorf("Should redact the sensitive values.")
+		t.Errorf("GOT: %s", got)
+		t.Errorf("EXP: %s", exp)
+	}
+
+	assertNoSecrets(t, got)
+}
+
+func redactMap(t *testing.T) {
+	data := map[string]any{
+		"email":      email,
+		"password":   secret,
+		"name":       name,
+		"department": "IT",
+	}
+
+	got := queryString(`SELECT * FROM users WHERE email = :email AND name = :name AND password = :password AND department = :department`, data)
+	exp := "SELECT * FROM users WHERE email = '[REDACTED]' AND name = '[REDACTED]' AND password = '[REDACTED]' AND department = 'IT'"
+
+	if got != exp {
+		t.Errorf("Should redact the sensitive values by column name.")
+		t.Errorf("GOT: %s", got)
+		t.Errorf("EXP: %s", exp)
+	}
+
+	assertNoSecrets(t, got)
+}
+
+func redactBulk(t *testing.T) {
+	usr2 := usr
+	usr2.ID = "5cf37266"
+	usr2.Name = "Ale " + name
+
+	got := queryString(`INSERT INTO users (user_id, name, email, secret) VALUES (:user_id, :name, :email, :secret)`, []testUser{usr, usr2})
+	exp := "INSERT INTO users (user_id, name, email, secret) VALUES ('45b5fbd3', '[REDACTED]', '[REDACTED]', '[REDACTED]'),('5cf37266', '[REDACTED]', '[REDACTED]', '[REDACTED]')"
+
+	if got != exp {
+		t.Errorf("Should redact the sensitive values of every row.")
+		t.Errorf("GOT: %s", got)
+		t.Errorf("EXP: %s", exp)
+	}
+
+	assertNoSecrets(t, got)
+}
+
+func redactEmbedded(t *testing.T) {
+	data := testAudited{
+		testUser: usr,
+		Actor:    "admin",
+	}
+
+	got := queryString(`UPDATE users SET secret = :secret, name = :name WHERE actor = :actor`, data)
+	exp := "UPDATE users SET secret = '[REDACTED]', name = '[REDACTED]' WHERE actor = 'admin'"
+
+	if got != exp {
+		t.Errorf("Should redact the tagged fields of embedded structs.")
+		t.Errorf("GOT: %s", got)
+		t.Errorf("EXP: %s", exp)
+	}
+
+	assertNoSecrets(t, got)
+}
+
+func redactSyntax(t *testing.T) {
+	data := map[string]any{
+		"department": "who?",
+		"email":      email,
+	}
+
+	got := queryString(`SELECT name::::text FROM users WHERE department = :department AND email = :email`, data)
+	exp := "SELECT name::text FROM users WHERE department = 'who?' AND email = '[REDACTED]'"
+
+	if got != exp {
+		t.Errorf("Should keep casts and values containing placeholders intact.")
+		t.Errorf("GOT: %s", got)
+		t.Errorf("EXP: %s", exp)
+	}
+
+	assertNoSecrets(t, got)
+}
+
+func redactGenerated(t *testing.T) {
+	schema := filter.Schema{"name": filter.String, "email": filter.String, "department": filter.String}
+	columns := map[string]string{"name": "name", "email": "email", "department": "department"}
+
+	expr, err := filter.Parse(`email eq "`+email+`" or name contains "`+name+`" or department eq "IT"`, schema)
+	if err != nil {
+		t.Fatalf("Should be able to parse the filter : %s.", err)
+	}
+
+	data := make(map[string]any)
+
+	where, err := filter.SQL(expr, columns, data)
+	if err != nil {
+		t.Fatalf("Should be able to compile the filter : %s.", err)
+	}
+
+	// The keyset paging parameters are named after the ordered field.
+	data["email_cursor_0"] = email
+	data["user_id_cursor_1"] = "45b5fbd3"
+
+	got := queryString(`SELECT * FROM users WHERE `+where+` AND (email > :email_cursor_0 OR user_id > :user_id_cursor_1)`, data)
+	exp := "SELECT * FROM users WHERE ((email = '[REDACTED]' OR name ILIKE '[REDACTED]') OR department = 'IT') AND (email > '[REDACTED]' OR user_id > '45b5fbd3')"
+
+	if got != exp {
+		t.Errorf("Should redact the generated parameters of the redacted columns.")
+		t.Errorf("GOT: %s", got)
+		t.Errorf("EXP: %s", exp)
+	}
+
+	assertNoSecrets(t, got)
+
+	// -------------------------------------------------------------------------
+
+	names := map[string]bool{"email": true, "password": true}
+
+	tests := []struct {
+		param string
+		exp   bool
+	}{
+		{param: "email", exp: true},
+		{param: "email_filter_3", exp: true},
+		{param: "email_cursor_0", exp: true},
+		{param: "password_hash", exp: true},
+		{param: "emails", exp: false},
+		{param: "user_email", exp: false},
+		{param: "_email", exp: false},
+		{param: "department_filter_0", exp: false},
+	}
+
+	for _, tt := range tests {
+		if got := redactParam(names, tt.param); got != tt.exp {
+			t.Errorf("%s: Should get the expected redaction : got %t, exp %t.", tt.param, got, tt.exp)
+		}
+	}
+}
+
+func assertNoSecrets(t *testing.T, query string) {
+	t.Helper()
+
+	for _, s := range []string{email, hash, secret, name} {
+		if strings.Contains(query, s) {
+			t.Errorf("Should never log the value %q : %s", s, query)
+		}
+	}
+}
@@ -1,6 +1,7 @@
 package sqldb
 
 import (
+	"context"
 	"fmt"
 
 	"github.com/1core-dev/go-service/business/data/transaction"
@@ -36,3 +37,30 @@ func GetExtContext(tx transaction.Transaction) (sqlx.ExtContext, error) {
 
 	return ec, nil
 }
+
+// ExecuteUnderSavepoint executes the function inside a savepoint of the
+// specified transaction. If the function fails, the transaction is rolled
+// back to the savepoint so it remains usable for further work.
+func ExecuteUnderSavepoint(ctx context.Context, tx transaction.Transaction, name string, f func() error) error {
+	ec, err := GetExtContext(tx)
+	if err != nil {
+		return err
+	}
+
+	if _, err := ec.ExecContext(ctx, "SAVEPOINT "+name); err != nil {
+		return fmt.Errorf("savepoint %s: %w", name, err)
+	}
+
+	if err := f(); err != nil {
+		if _, rbErr := ec.ExecContext(ctx, "ROLLBACK TO SAVEPOINT "+name); rbErr != nil {
+			return fmt.Errorf("rollback to savepoint %s: %w: %w", name, rbErr, err)
+		}
+		return err
+	}
+
+	if _, err := ec.ExecContext(ctx, "RELEASE SAVEPOINT "+name); err != nil {
+		return fmt.Errorf("release savepoint %s: %w", name, err)
+	}
+
+	return nil
+}
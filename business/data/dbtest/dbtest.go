@@ -226,6 +226,13 @@ func FloatPointer(f float64) *float64 {
 	return &f
 }
 
+// BoolPointer is a helper to get a *bool from a bool. It is in the tests
+// package because we normally don't want to deal with pointers to basic types
+// but it's useful in some tests.
+func BoolPointer(b bool) *bool {
+	return &b
+}
+
 // =============================================================================
 
 // CoreAPIs represents all the core api's needed for testing.
@@ -233,8 +240,8 @@ type CoreAPIs struct {
 	User *user.Core
 }
 
-func newCoreAPIs(log *logger.Logger, db *sqlx.DB) CoreAPIs {
-	usrCore := user.NewCore(log, userdb.NewStore(log, db))
+func newCoreAPIs(log *logger.Logger, sqlxDB *sqlx.DB) CoreAPIs {
+	usrCore := user.NewCore(log, db.NewBeginner(sqlxDB), userdb.NewStore(log, sqlxDB))
 
 	return CoreAPIs{
 		User: usrCore,
@@ -0,0 +1,198 @@
+// Package filter provides support for filtering data with expressions like
+// `department eq "IT" and (enabled eq true or roles has "ADMIN")`.
+package filter
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Set of limits that protect the parser from abusive expressions.
+const (
+	maxLength = 1024
+	maxDepth  = 8
+	maxTerms  = 32
+)
+
+// Type represents the type of a field that can be filtered on.
+type Type int
+
+// Set of field types that can be described in a schema.
+const (
+	String Type = iota + 1
+	Number
+	Bool
+	Time
+	UUID
+	StringArray
+)
+
+var typeNames = map[Type]string{
+	String:      "string",
+	Number:      "number",
+	Bool:        "bool",
+	Time:        "time",
+	UUID:        "uuid",
+	StringArray: "string array",
+}
+
+// String implements the fmt.Stringer interface.
+func (t Type) String() string {
+	return typeNames[t]
+}
+
+// Schema describes the fields a domain allows to be filtered on and their
+// types. Field names not in the schema are rejected.
+type Schema map[string]Type
+
+// =============================================================================
+
+// Op represents a comparison operator.
+type Op string
+
+// Set of comparison operators supported in an expression.
+const (
+	OpEq         Op = "eq"
+	OpNe         Op = "ne"
+	OpGt         Op = "gt"
+	OpGe         Op = "ge"
+	OpLt         Op = "lt"
+	OpLe         Op = "le"
+	OpContains   Op = "contains"
+	OpStartsWith Op = "startswith"
+	OpHas        Op = "has"
+)
+
+// operators lists the field types each operator can be applied to.
+var operators = map[Op][]Type{
+	OpEq:         {String, Number, Bool, Time, UUID},
+	OpNe:         {String, Number, Bool, Time, UUID},
+	OpGt:         {String, Number, Time},
+	OpGe:         {String, Number, Time},
+	OpLt:         {String, Number, Time},
+	OpLe:         {String, Number, Time},
+	OpContains:   {String},
+	OpStartsWith: {String},
+	OpHas:        {StringArray},
+}
+
+// =============================================================================
+
+// Expr represents a parsed filter expression.
+type Expr interface {
+	expr()
+}
+
+// And matches when both expressions match.
+type And struct {
+	Left  Expr
+	Right Expr
+}
+
+// Or matches when either expression matches.
+type Or struct {
+	Left  Expr
+	Right Expr
+}
+
+// Not matches when the expression doesn't match.
+type Not struct {
+	Expr Expr
+}
+
+// Condition compares a field to a value. The value has already been
+// converted to the Go type matching the field type in the schema: string,
+// float64, bool, time.Time or uuid.UUID. For the has operator it's the
+// string expected to be an element of the array.
+type Condition struct {
+	Field string
+	Op    Op
+	Value any
+}
+
+func (And) expr()       {}
+func (Or) expr()        {}
+func (Not) expr()       {}
+func (Condition) expr() {}
+
+// =============================================================================
+
+// newCondition validates the operator and value against the type of the
+// field and converts the value.
+func newCondition(field string, typ Type, op Op, lit token) (Condition, error) {
+	types, exists := operators[op]
+	if !exists {
+		return Condition{}, fmt.Errorf("position %d: unknown operator %q", lit.pos, op)
+	}
+
+	var allowed bool
+	for _, t := range types {
+		if t == typ {
+			allowed = true
+			break
+		}
+	}
+
+	if !allowed {
+		return Condition{}, fmt.Errorf("position %d: operator %q can't be used with %s field %q", lit.pos, op, typ, field)
+	}
+
+	value, err := convert(typ, op, lit)
+	if err != nil {
+		return Condition{}, fmt.Errorf("position %d: field %q: %w", lit.pos, field, err)
+	}
+
+	cond := Condition{
+		Field: field,
+		Op:    op,
+		Value: value,
+	}
+
+	return cond, nil
+}
+
+func convert(typ Type, op Op, lit token) (any, error) {
+	switch typ {
+	case String, StringArray:
+		if lit.kind != tokenString {
+			return nil, fmt.Errorf("expected a string value")
+		}
+		return lit.value, nil
+
+	case Number:
+		if lit.kind != tokenNumber {
+			return nil, fmt.Errorf("expected a number value")
+		}
+		return lit.number, nil
+
+	case Bool:
+		if lit.kind != tokenBool {
+			return nil, fmt.Errorf("expected true or false")
+		}
+		return lit.value == "true", nil
+
+	case Time:
+		if lit.kind != tokenString {
+			return nil, fmt.Errorf("expected an RFC3339 time string")
+		}
+		t, err := time.Parse(time.RFC3339, lit.value)
+		if err != nil {
+			return nil, fmt.Errorf("expected an RFC3339 time string")
+		}
+		return t.UTC(), nil
+
+	case UUID:
+		if lit.kind != tokenString {
+			return nil, fmt.Errorf("expected a uuid string")
+		}
+		id, err := uuid.Parse(lit.value)
+		if err != nil {
+			return nil, fmt.Errorf("expected a uuid string")
+		}
+		return id, nil
+	}
+
+	return nil, fmt.Errorf("unsupported type for operator %q", op)
+}
@@ -0,0 +1,162 @@
+package filter_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/1core-dev/go-service/business/data/filter"
+)
+
+var schema = filter.Schema{
+	"user_id":      filter.UUID,
+	"name":         filter.String,
+	"department":   filter.String,
+	"enabled":      filter.Bool,
+	"roles":        filter.StringArray,
+	"date_created": filter.Time,
+}
+
+var columns = map[string]string{
+	"user_id":      "user_id",
+	"name":         "name",
+	"department":   "department",
+	"enabled":      "enabled",
+	"roles":        "roles",
+	"date_created": "date_created",
+}
+
+func Test_Filter(t *testing.T) {
+	t.Run("compile", compile)
+	t.Run("invalid", invalid)
+	t.Run("injection", injection)
+}
+
+// =============================================================================
+
+func compile(t *testing.T) {
+	tests := []struct {
+		expr string
+		sql  string
+		data map[string]any
+	}{
+		{
+			expr: `department eq "IT" and (enabled eq true or roles has "ADMIN")`,
+			sql:  `(department = :department_filter_0 AND (enabled = :enabled_filter_1 OR :roles_filter_2 = ANY(roles)))`,
+			data: map[string]any{"department_filter_0": "IT", "enabled_filter_1": true, "roles_filter_2": "ADMIN"},
+		},
+		{
+			expr: `NOT name contains "50%_off" OR name startswith "Jo"`,
+			sql:  `(NOT (name ILIKE :name_filter_0) OR name ILIKE :name_filter_1)`,
+			data: map[string]any{"name_filter_0": `%50\%\_off%`, "name_filter_1": "Jo%"},
+		},
+		{
+			expr: `name eq "a" and name ne "b" or name eq "c"`,
+			sql:  `((name = :name_filter_0 AND name <> :name_filter_1) OR name = :name_filter_2)`,
+			data: map[string]any{"name_filter_0": "a", "name_filter_1": "b", "name_filter_2": "c"},
+		},
+	}
+
+	for _, tt := range tests {
+		expr, err := filter.Parse(tt.expr, schema)
+		if err != nil {
+			t.Fatalf("Should be able to parse %q : %s.", tt.expr, err)
+		}
+
+		data := map[string]any{}
+		sql, err := filter.SQL(expr, columns, data)
+		if err != nil {
+			t.Fatalf("Should be able to compile %q : %s.", tt.expr, err)
+		}
+
+		if sql != tt.sql {
+			t.Errorf("Should get the expected sql for %q.", tt.expr)
+			t.Errorf("GOT: %s", sql)
+			t.Errorf("EXP: %s", tt.sql)
+		}
+
+		for k, v := range tt.data {
+			if data[k] != v {
+				t.Errorf("Should get the expected value for %s in %q : got %v, exp %v.", k, tt.expr, data[k], v)
+			}
+		}
+	}
+}
+
+func invalid(t *testing.T) {
+	tests := []string{
+		``,
+		`password_hash eq "x"`,
+		`enabled eq "true"`,
+		`enabled gt true`,
+		`roles eq "ADMIN"`,
+		`name has "x"`,
+		`name like "x"`,
+		`user_id eq "not-a-uuid"`,
+		`date_created gt "yesterday"`,
+		`name eq "x" and`,
+		`(name eq "x"`,
+		`name eq "x")`,
+		`name eq "unterminated`,
+		`name eq "bad \n escape"`,
+		strings.Repeat("(", 20) + `name eq "x"` + strings.Repeat(")", 20),
+		strings.Repeat(`name eq "x" or `, 40) + `name eq "x"`,
+	}
+
+	for _, tt := range tests {
+		if _, err := filter.Parse(tt, schema); err == nil {
+			t.Errorf("Should reject the expression %q.", tt)
+		}
+	}
+}
+
+func injection(t *testing.T) {
+	rejected := []string{
+		`name eq "x"; DROP TABLE users; --`,
+		`name eq 'x' or 1=1`,
+		`name eq "x" or 1 = 1`,
+		`name = "x"`,
+		`name eq "x" -- comment`,
+		`name eq "x" /* comment */`,
+		`"name" eq "x"`,
+		`name eq "x" union select password_hash from users`,
+		`name) or (1 eq 1`,
+		`name eq x`,
+		`name eq "x" or enabled eq true; select pg_sleep(10)`,
+	}
+
+	for _, tt := range rejected {
+		if _, err := filter.Parse(tt, schema); err == nil {
+			t.Errorf("Should reject the injection attempt %q.", tt)
+		}
+	}
+
+	// Values that look like sql must only ever reach the data map.
+	values := []string{
+		`x' OR '1'='1`,
+		`"; DROP TABLE users; --`,
+		`:name_filter_1`,
+	}
+
+	for _, value := range values {
+		quoted := `"` + strings.ReplaceAll(value, `"`, `\"`) + `"`
+
+		expr, err := filter.Parse(`name eq `+quoted, schema)
+		if err != nil {
+			t.Fatalf("Should be able to parse the value %q : %s.", value, err)
+		}
+
+		data := map[string]any{}
+		sql, err := filter.SQL(expr, columns, data)
+		if err != nil {
+			t.Fatalf("Should be able to compile the value %q : %s.", value, err)
+		}
+
+		if sql != "name = :name_filter_0" {
+			t.Errorf("Should not write the value %q into the sql : %s.", value, sql)
+		}
+
+		if data["name_filter_0"] != value {
+			t.Errorf("Should pass the value %q as a parameter : got %v.", value, data["name_filter_0"])
+		}
+	}
+}
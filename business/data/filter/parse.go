@@ -0,0 +1,303 @@
+package filter
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// Parse parses the filter expression and validates every field, operator
+// and value against the schema. The grammar is:
+//
+//	expr      = term { "or" term }
+//	term      = factor { "and" factor }
+//	factor    = "not" factor | "(" expr ")" | condition
+//	condition = field operator value
+//	value     = string | number | "true" | "false"
+//
+// Strings are double quoted and support the \" and \\ escapes. Keywords and
+// operators are case insensitive.
+func Parse(input string, schema Schema) (Expr, error) {
+	if len(input) > maxLength {
+		return nil, fmt.Errorf("expression longer than %d bytes", maxLength)
+	}
+
+	tokens, err := lex(input)
+	if err != nil {
+		return nil, err
+	}
+
+	p := parser{
+		tokens: tokens,
+		schema: schema,
+	}
+
+	expr, err := p.parseOr(0)
+	if err != nil {
+		return nil, err
+	}
+
+	if tok := p.peek(); tok.kind != tokenEOF {
+		return nil, fmt.Errorf("position %d: unexpected %q", tok.pos, tok.value)
+	}
+
+	return expr, nil
+}
+
+// =============================================================================
+
+type parser struct {
+	tokens []token
+	pos    int
+	terms  int
+	schema Schema
+}
+
+func (p *parser) peek() token {
+	return p.tokens[p.pos]
+}
+
+func (p *parser) next() token {
+	tok := p.tokens[p.pos]
+	if tok.kind != tokenEOF {
+		p.pos++
+	}
+	return tok
+}
+
+func (p *parser) keyword(word string) bool {
+	tok := p.peek()
+	if tok.kind == tokenIdent && strings.EqualFold(tok.value, word) {
+		p.next()
+		return true
+	}
+	return false
+}
+
+func (p *parser) parseOr(depth int) (Expr, error) {
+	left, err := p.parseAnd(depth)
+	if err != nil {
+		return nil, err
+	}
+
+	for p.keyword("or") {
+		right, err := p.parseAnd(depth)
+		if err != nil {
+			return nil, err
+		}
+		left = Or{Left: left, Right: right}
+	}
+
+	return left, nil
+}
+
+func (p *parser) parseAnd(depth int) (Expr, error) {
+	left, err := p.parseFactor(depth)
+	if err != nil {
+		return nil, err
+	}
+
+	for p.keyword("and") {
+		right, err := p.parseFactor(depth)
+		if err != nil {
+			return nil, err
+		}
+		left = And{Left: left, Right: right}
+	}
+
+	return left, nil
+}
+
+func (p *parser) parseFactor(depth int) (Expr, error) {
+	if depth > maxDepth {
+		return nil, fmt.Errorf("position %d: expression nested deeper than %d levels", p.peek().pos, maxDepth)
+	}
+
+	if p.keyword("not") {
+		expr, err := p.parseFactor(depth + 1)
+		if err != nil {
+			return nil, err
+		}
+		return Not{Expr: expr}, nil
+	}
+
+	if tok := p.peek(); tok.kind == tokenLParen {
+		p.next()
+
+		expr, err := p.parseOr(depth + 1)
+		if err != nil {
+			return nil, err
+		}
+
+		if tok := p.next(); tok.kind != tokenRParen {
+			return nil, fmt.Errorf("position %d: expected \")\"", tok.pos)
+		}
+
+		return expr, nil
+	}
+
+	return p.parseCondition()
+}
+
+func (p *parser) parseCondition() (Expr, error) {
+	p.terms++
+	if p.terms > maxTerms {
+		return nil, fmt.Errorf("position %d: expression has more than %d conditions", p.peek().pos, maxTerms)
+	}
+
+	field := p.next()
+	if field.kind != tokenIdent {
+		return nil, fmt.Errorf("position %d: expected a field name", field.pos)
+	}
+
+	typ, exists := p.schema[field.value]
+	if !exists {
+		return nil, fmt.Errorf("position %d: unknown field %q", field.pos, field.value)
+	}
+
+	op := p.next()
+	if op.kind != tokenIdent {
+		return nil, fmt.Errorf("position %d: expected an operator", op.pos)
+	}
+
+	value := p.next()
+	switch value.kind {
+	case tokenString, tokenNumber, tokenBool:
+	default:
+		return nil, fmt.Errorf("position %d: expected a value", value.pos)
+	}
+
+	return newCondition(field.value, typ, Op(strings.ToLower(op.value)), value)
+}
+
+// =============================================================================
+
+type tokenKind int
+
+const (
+	tokenEOF tokenKind = iota
+	tokenIdent
+	tokenString
+	tokenNumber
+	tokenBool
+	tokenLParen
+	tokenRParen
+)
+
+type token struct {
+	kind   tokenKind
+	value  string
+	number float64
+	pos    int
+}
+
+// lex splits the input into tokens. Only the characters of the grammar are
+// accepted, anything else is rejected.
+func lex(input string) ([]token, error) {
+	if !utf8.ValidString(input) {
+		return nil, errors.New("expression is not valid utf-8")
+	}
+
+	var tokens []token
+
+	for i := 0; i < len(input); {
+		c := input[i]
+
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			i++
+
+		case c == '(':
+			tokens = append(tokens, token{kind: tokenLParen, value: "(", pos: i})
+			i++
+
+		case c == ')':
+			tokens = append(tokens, token{kind: tokenRParen, value: ")", pos: i})
+			i++
+
+		case c == '"':
+			value, n, err := lexString(input[i:])
+			if err != nil {
+				return nil, fmt.Errorf("position %d: %w", i, err)
+			}
+			tokens = append(tokens, token{kind: tokenString, value: value, pos: i})
+			i += n
+
+		case c == '-' || isDigit(c):
+			start := i
+			i++
+			for i < len(input) && (isDigit(input[i]) || input[i] == '.') {
+				i++
+			}
+
+			number, err := strconv.ParseFloat(input[start:i], 64)
+			if err != nil {
+				return nil, fmt.Errorf("position %d: invalid number %q", start, input[start:i])
+			}
+			tokens = append(tokens, token{kind: tokenNumber, value: input[start:i], number: number, pos: start})
+
+		case isLetter(c):
+			start := i
+			for i < len(input) && (isLetter(input[i]) || isDigit(input[i])) {
+				i++
+			}
+
+			word := input[start:i]
+			switch strings.ToLower(word) {
+			case "true", "false":
+				tokens = append(tokens, token{kind: tokenBool, value: strings.ToLower(word), pos: start})
+			default:
+				tokens = append(tokens, token{kind: tokenIdent, value: word, pos: start})
+			}
+
+		default:
+			r, _ := utf8.DecodeRuneInString(input[i:])
+			return nil, fmt.Errorf("position %d: unexpected character %q", i, r)
+		}
+	}
+
+	tokens = append(tokens, token{kind: tokenEOF, pos: len(input)})
+
+	return tokens, nil
+}
+
+// lexString reads a double quoted string from the start of the input and
+// returns the unescaped value and the number of bytes consumed.
+func lexString(input string) (string, int, error) {
+	var b strings.Builder
+
+	for i := 1; i < len(input); i++ {
+		switch input[i] {
+		case '"':
+			return b.String(), i + 1, nil
+
+		case '\\':
+			i++
+			if i == len(input) {
+				return "", 0, errors.New("unterminated string")
+			}
+
+			switch input[i] {
+			case '"', '\\':
+				b.WriteByte(input[i])
+			default:
+				return "", 0, fmt.Errorf("invalid escape \\%c", input[i])
+			}
+
+		default:
+			b.WriteByte(input[i])
+		}
+	}
+
+	return "", 0, errors.New("unterminated string")
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+func isLetter(c byte) bool {
+	return c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
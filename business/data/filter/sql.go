@@ -0,0 +1,118 @@
+package filter
+
+import (
+	"fmt"
+	"strings"
+)
+
+var sqlOperators = map[Op]string{
+	OpEq: "=",
+	OpNe: "<>",
+	OpGt: ">",
+	OpGe: ">=",
+	OpLt: "<",
+	OpLe: "<=",
+}
+
+// SQL compiles the expression into a parameterized postgres condition. The
+// fields are replaced by the columns they map to and every value is added to
+// the data map under a generated name for use with named queries, so no
+// value from the expression is ever written into the query itself. The names
+// start with the field, like email_filter_0, so the values can be redacted
+// when the query is logged.
+func SQL(expr Expr, columns map[string]string, data map[string]any) (string, error) {
+	c := compiler{
+		columns: columns,
+		data:    data,
+	}
+
+	return c.compile(expr)
+}
+
+type compiler struct {
+	columns map[string]string
+	data    map[string]any
+	n       int
+}
+
+func (c *compiler) compile(expr Expr) (string, error) {
+	switch e := expr.(type) {
+	case And:
+		return c.binary(e.Left, "AND", e.Right)
+
+	case Or:
+		return c.binary(e.Left, "OR", e.Right)
+
+	case Not:
+		s, err := c.compile(e.Expr)
+		if err != nil {
+			return "", err
+		}
+		return "NOT (" + s + ")", nil
+
+	case Condition:
+		return c.condition(e)
+	}
+
+	return "", fmt.Errorf("unknown expression %T", expr)
+}
+
+func (c *compiler) binary(left Expr, op string, right Expr) (string, error) {
+	l, err := c.compile(left)
+	if err != nil {
+		return "", err
+	}
+
+	r, err := c.compile(right)
+	if err != nil {
+		return "", err
+	}
+
+	return "(" + l + " " + op + " " + r + ")", nil
+}
+
+func (c *compiler) condition(cond Condition) (string, error) {
+	column, exists := c.columns[cond.Field]
+	if !exists {
+		return "", fmt.Errorf("field %q has no column", cond.Field)
+	}
+
+	switch cond.Op {
+	case OpContains:
+		return column + " ILIKE :" + c.param(cond.Field, "%"+escapeLike(cond.Value.(string))+"%"), nil
+
+	case OpStartsWith:
+		return column + " ILIKE :" + c.param(cond.Field, escapeLike(cond.Value.(string))+"%"), nil
+
+	case OpHas:
+		return ":" + c.param(cond.Field, cond.Value) + " = ANY(" + column + ")", nil
+	}
+
+	op, exists := sqlOperators[cond.Op]
+	if !exists {
+		return "", fmt.Errorf("unknown operator %q", cond.Op)
+	}
+
+	return column + " " + op + " :" + c.param(cond.Field, cond.Value), nil
+}
+
+// param adds the value of the field to the data map under a name that isn't
+// in use.
+func (c *compiler) param(field string, value any) string {
+	for {
+		name := fmt.Sprintf("%s_filter_%d", field, c.n)
+		c.n++
+
+		if _, exists := c.data[name]; !exists {
+			c.data[name] = value
+			return name
+		}
+	}
+}
+
+// escapeLike escapes the characters with a special meaning in a LIKE
+// pattern so they are matched literally.
+func escapeLike(s string) string {
+	r := strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+	return r.Replace(s)
+}
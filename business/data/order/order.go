@@ -5,6 +5,7 @@ import (
 	"errors"
 	"fmt"
 	"net/http"
+	"net/url"
 	"strings"
 
 	"github.com/1core-dev/go-service/pkg/validate"
@@ -21,6 +22,10 @@ var directions = map[string]string{
 	DESC: "DESC",
 }
 
+// DefaultMaxFields is the number of fields a request can order by when no
+// other limit is provided.
+const DefaultMaxFields = 3
+
 // =============================================================================
 
 // By represents a field used to order by and direction.
@@ -39,30 +44,112 @@ func NewBy(field string, direction string) By {
 
 // =============================================================================
 
-// Parse constructs a order.By value by parsing a string in the form
-// of "field,direction".
-func Parse(r *http.Request, defaultOrder By) (By, error) {
-	v := r.URL.Query().Get("orderBy")
+// List represents the set of fields used to order by, in order of priority.
+type List []By
+
+// NewList constructs a new List value with no checks.
+func NewList(bys ...By) List {
+	return List(bys)
+}
+
+// WithTiebreaker returns a copy of the list with the field appended in
+// ascending order, unless the list already orders by that field. Ordering
+// by a unique field last makes the order of the rows stable across pages.
+func (l List) WithTiebreaker(field string) List {
+	for _, by := range l {
+		if by.Field == field {
+			return l
+		}
+	}
+
+	list := make(List, len(l), len(l)+1)
+	copy(list, l)
+
+	return append(list, NewBy(field, ASC))
+}
+
+// String returns the list in the same form accepted by Parse.
+func (l List) String() string {
+	parts := make([]string, len(l))
+	for i, by := range l {
+		parts[i] = by.Field + "," + by.Direction
+	}
+
+	return strings.Join(parts, ";")
+}
+
+// =============================================================================
+
+// Parse constructs a order.List value by parsing a string in the form
+// of "field,direction;field,direction". The direction is optional and
+// defaults to ascending. At most maxFields fields are accepted and a field
+// can only be used once.
+func Parse(r *http.Request, defaultOrder By, maxFields int) (List, error) {
+	const key = "orderBy"
+
+	v, err := rawQueryValue(r, key)
+	if err != nil {
+		return nil, validate.NewFieldsError(key, err)
+	}
 
 	if v == "" {
-		return defaultOrder, nil
+		return NewList(defaultOrder), nil
 	}
 
-	orderParts := strings.Split(v, ",")
+	fields := strings.Split(v, ";")
+	if len(fields) > maxFields {
+		return nil, validate.NewFieldsError(key, fmt.Errorf("can order by at most %d fields", maxFields))
+	}
+
+	list := make(List, 0, len(fields))
+	seen := make(map[string]bool, len(fields))
+
+	for _, field := range fields {
+		orderParts := strings.Split(field, ",")
+
+		var by By
+		switch len(orderParts) {
+		case 1:
+			by = NewBy(strings.Trim(orderParts[0], " "), ASC)
+		case 2:
+			by = NewBy(strings.Trim(orderParts[0], " "), strings.Trim(orderParts[1], " "))
+		default:
+			return nil, validate.NewFieldsError(key, fmt.Errorf("unknown order field: %s", field))
+		}
 
-	var by By
-	switch len(orderParts) {
-	case 1:
-		by = NewBy(strings.Trim(orderParts[0], " "), ASC)
-	case 2:
-		by = NewBy(strings.Trim(orderParts[0], " "), strings.Trim(orderParts[1], " "))
-	default:
-		return By{}, validate.NewFieldsError(v, errors.New("unknown order field"))
+		if by.Field == "" {
+			return nil, validate.NewFieldsError(key, errors.New("missing order field"))
+		}
+
+		if _, exists := directions[by.Direction]; !exists {
+			return nil, validate.NewFieldsError(key, fmt.Errorf("unknown direction: %s", by.Direction))
+		}
+
+		if seen[by.Field] {
+			return nil, validate.NewFieldsError(key, fmt.Errorf("duplicate order field: %s", by.Field))
+		}
+		seen[by.Field] = true
+
+		list = append(list, by)
 	}
 
-	if _, exists := directions[by.Direction]; !exists {
-		return By{}, validate.NewFieldsError(v, fmt.Errorf("unknown direction: %s", by.Direction))
+	return list, nil
+}
+
+// rawQueryValue returns the first value of the key from the raw query string.
+// The standard library drops any parameter containing a semicolon, which is
+// the field separator of the orderBy parameter, so it's read directly.
+func rawQueryValue(r *http.Request, key string) (string, error) {
+	for _, pair := range strings.Split(r.URL.RawQuery, "&") {
+		k, v, _ := strings.Cut(pair, "=")
+
+		k, err := url.QueryUnescape(k)
+		if err != nil || k != key {
+			continue
+		}
+
+		return url.QueryUnescape(v)
 	}
 
-	return by, nil
+	return "", nil
 }
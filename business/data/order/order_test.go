@@ -0,0 +1,157 @@
+package order_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/1core-dev/go-service/business/data/order"
+	"github.com/1core-dev/go-service/pkg/validate"
+)
+
+var defaultOrder = order.NewBy("user_id", order.ASC)
+
+func Test_Order(t *testing.T) {
+	t.Run("parse", parse)
+	t.Run("parseErrors", parseErrors)
+	t.Run("tiebreaker", tiebreaker)
+}
+
+// =============================================================================
+
+func newRequest(rawQuery string) *http.Request {
+	return httptest.NewRequest(http.MethodGet, "/v1/users?"+rawQuery, nil)
+}
+
+// =============================================================================
+
+func parse(t *testing.T) {
+	tests := []struct {
+		name     string
+		rawQuery string
+		exp      order.List
+	}{
+		{name: "default", rawQuery: "", exp: order.NewList(defaultOrder)},
+		{name: "empty", rawQuery: "orderBy=", exp: order.NewList(defaultOrder)},
+		{name: "otherKey", rawQuery: "order=name,DESC", exp: order.NewList(defaultOrder)},
+		{name: "field", rawQuery: "orderBy=name", exp: order.NewList(order.NewBy("name", order.ASC))},
+		{name: "direction", rawQuery: "orderBy=name,DESC", exp: order.NewList(order.NewBy("name", order.DESC))},
+		{
+			name:     "semicolon",
+			rawQuery: "orderBy=name,DESC;email",
+			exp:      order.NewList(order.NewBy("name", order.DESC), order.NewBy("email", order.ASC)),
+		},
+		{
+			name:     "escaped",
+			rawQuery: "orderBy=name%2CDESC%3Bemail%2CASC",
+			exp:      order.NewList(order.NewBy("name", order.DESC), order.NewBy("email", order.ASC)),
+		},
+		{
+			name:     "escapedKey",
+			rawQuery: "order%42y=name",
+			exp:      order.NewList(order.NewBy("name", order.ASC)),
+		},
+		{
+			name:     "spaces",
+			rawQuery: "orderBy=+name+,+DESC+;email",
+			exp:      order.NewList(order.NewBy("name", order.DESC), order.NewBy("email", order.ASC)),
+		},
+		{
+			name:     "otherParams",
+			rawQuery: "page=2&orderBy=name,DESC;email&rows=10",
+			exp:      order.NewList(order.NewBy("name", order.DESC), order.NewBy("email", order.ASC)),
+		},
+		{name: "firstValue", rawQuery: "orderBy=name&orderBy=email", exp: order.NewList(order.NewBy("name", order.ASC))},
+		{name: "badKeyEscape", rawQuery: "%zz=email&orderBy=name", exp: order.NewList(order.NewBy("name", order.ASC))},
+		{
+			name:     "maxFields",
+			rawQuery: "orderBy=name;email;user_id",
+			exp:      order.NewList(order.NewBy("name", order.ASC), order.NewBy("email", order.ASC), order.NewBy("user_id", order.ASC)),
+		},
+	}
+
+	for _, tt := range tests {
+		got, err := order.Parse(newRequest(tt.rawQuery), defaultOrder, order.DefaultMaxFields)
+		if err != nil {
+			t.Errorf("%s: Should be able to parse the order : %s.", tt.name, err)
+			continue
+		}
+
+		if !reflect.DeepEqual(got, tt.exp) {
+			t.Errorf("%s: Should get the expected order : got %v, exp %v.", tt.name, got, tt.exp)
+		}
+	}
+}
+
+func parseErrors(t *testing.T) {
+	tests := []struct {
+		name      string
+		rawQuery  string
+		maxFields int
+		exp       string
+	}{
+		{name: "duplicate", rawQuery: "orderBy=name;email;name,DESC", maxFields: 3, exp: "duplicate order field: name"},
+		{name: "duplicateSpaces", rawQuery: "orderBy=name;+name", maxFields: 3, exp: "duplicate order field: name"},
+		{name: "tooMany", rawQuery: "orderBy=name;email;user_id;roles", maxFields: 3, exp: "can order by at most 3 fields"},
+		{name: "lowerMax", rawQuery: "orderBy=name;email", maxFields: 1, exp: "can order by at most 1 fields"},
+		{name: "direction", rawQuery: "orderBy=name,UP", maxFields: 3, exp: "unknown direction: UP"},
+		{name: "lowercase", rawQuery: "orderBy=name,desc", maxFields: 3, exp: "unknown direction: desc"},
+		{name: "parts", rawQuery: "orderBy=name,ASC,DESC", maxFields: 3, exp: "unknown order field: name,ASC,DESC"},
+		{name: "missingField", rawQuery: "orderBy=name;;email", maxFields: 3, exp: "missing order field"},
+		{name: "badEscape", rawQuery: "orderBy=name%zz", maxFields: 3, exp: `invalid URL escape "%zz"`},
+	}
+
+	for _, tt := range tests {
+		_, err := order.Parse(newRequest(tt.rawQuery), defaultOrder, tt.maxFields)
+		if err == nil {
+			t.Errorf("%s: Should not be able to parse the order.", tt.name)
+			continue
+		}
+
+		fields := validate.GetFieldErrors(err).Fields()
+		if got := fields["orderBy"]; got != tt.exp {
+			t.Errorf("%s: Should get the error of the orderBy field : got %q, exp %q.", tt.name, got, tt.exp)
+		}
+	}
+}
+
+func tiebreaker(t *testing.T) {
+	tests := []struct {
+		name string
+		list order.List
+		exp  order.List
+	}{
+		{name: "empty", list: order.NewList(), exp: order.NewList(order.NewBy("user_id", order.ASC))},
+		{
+			name: "appended",
+			list: order.NewList(order.NewBy("name", order.DESC)),
+			exp:  order.NewList(order.NewBy("name", order.DESC), order.NewBy("user_id", order.ASC)),
+		},
+		{
+			name: "present",
+			list: order.NewList(order.NewBy("user_id", order.DESC), order.NewBy("name", order.ASC)),
+			exp:  order.NewList(order.NewBy("user_id", order.DESC), order.NewBy("name", order.ASC)),
+		},
+	}
+
+	for _, tt := range tests {
+		got := tt.list.WithTiebreaker("user_id")
+
+		if !reflect.DeepEqual(got, tt.exp) {
+			t.Errorf("%s: Should get the expected order : got %v, exp %v.", tt.name, got, tt.exp)
+		}
+	}
+
+	// -------------------------------------------------------------------------
+
+	list := make(order.List, 1, 2)
+	list[0] = order.NewBy("name", order.DESC)
+
+	a := list.WithTiebreaker("user_id")
+	b := list.WithTiebreaker("email")
+
+	if a[1].Field != "user_id" || b[1].Field != "email" || len(list) != 1 {
+		t.Errorf("Should not share the backing array of the list : %v, %v, %v.", list, a, b)
+	}
+}
@@ -2,19 +2,37 @@
 package page
 
 import (
+	"fmt"
 	"net/http"
 	"strconv"
 
 	"github.com/1core-dev/go-service/pkg/validate"
 )
 
-// Page represents the requested page and rows per page.
+// Count represents how the total number of rows is computed.
+type Count string
+
+// Set of count modes that can be requested with the count query string.
+// Counting every row can be slow for large tables, so callers can skip the
+// count or use the query planner's estimate instead.
+const (
+	CountExact     Count = "exact"
+	CountEstimated Count = "estimated"
+	CountNone      Count = "none"
+)
+
+// Page represents the requested page and rows per page. When the cursor
+// query string is provided, even empty, keyset paging is requested and
+// Cursor holds the token of the position to continue from.
 type Page struct {
 	Number      int
 	RowsPerPage int
+	Count       Count
+	Keyset      bool
+	Cursor      string
 }
 
-// Parse parses the request for the page and rows query string. The
+// Parse parses the request for the page, rows and count query string. The
 // defaults are provided as well.
 func Parse(r *http.Request) (Page, error) {
 	values := r.URL.Query()
@@ -37,8 +55,22 @@ func Parse(r *http.Request) (Page, error) {
 		}
 	}
 
+	count := CountExact
+	if c := values.Get("count"); c != "" {
+		count = Count(c)
+
+		switch count {
+		case CountExact, CountEstimated, CountNone:
+		default:
+			return Page{}, validate.NewFieldsError("count", fmt.Errorf("unknown count %q", c))
+		}
+	}
+
 	return Page{
 		Number:      number,
 		RowsPerPage: rowsPerPage,
+		Count:       count,
+		Keyset:      values.Has("cursor"),
+		Cursor:      values.Get("cursor"),
 	}, nil
 }
@@ -12,11 +12,15 @@ import (
 
 	"github.com/1core-dev/go-service/business/core/user"
 	"github.com/1core-dev/go-service/business/core/user/stores/userdb"
+	sqldb "github.com/1core-dev/go-service/business/data/dbsql/pgx"
 	"github.com/1core-dev/go-service/pkg/logger"
+	"github.com/1core-dev/go-service/pkg/web"
 	"github.com/golang-jwt/jwt/v5"
 	"github.com/google/uuid"
 	"github.com/jmoiron/sqlx"
+	"github.com/open-policy-agent/opa/v1/ast"
 	"github.com/open-policy-agent/opa/v1/rego"
+	"go.opentelemetry.io/otel/attribute"
 )
 
 // ErrForbidden is returned when a auth issue is identified.
@@ -63,7 +67,7 @@ func New(cfg Config) (*Auth, error) {
 	// user enabled check.
 	var usrCore *user.Core
 	if cfg.DB != nil {
-		usrCore = user.NewCore(cfg.Log, userdb.NewStore(cfg.Log, cfg.DB))
+		usrCore = user.NewCore(cfg.Log, sqldb.NewBeginner(cfg.DB), userdb.NewStore(cfg.Log, cfg.DB))
 	}
 
 	a := Auth{
@@ -197,11 +201,15 @@ func (a *Auth) publicKeyLookup(kid string) (string, error) {
 // opaPolicyEvaluation asks opa to evaluate the token against the specified
 // token policy and public key.
 func (a *Auth) opaPolicyEvaluation(ctx context.Context, opaPolicy, rule string, input any) error {
+	ctx, span := web.AddSpan(ctx, "business.web.v1.auth.opa", attribute.String("rule", rule))
+	defer span.End()
+
 	query := fmt.Sprintf("x = data.%s.%s", opaPackage, rule)
 
 	q, err := rego.New(
 		rego.Query(query),
 		rego.Module("policy.rego", opaPolicy),
+		rego.SetRegoVersion(ast.RegoV0),
 	).PrepareForEval(ctx)
 	if err != nil {
 		return err
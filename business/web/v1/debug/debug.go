@@ -5,13 +5,16 @@ import (
 	"expvar"
 	"net/http"
 	"net/http/pprof"
+
+	"github.com/1core-dev/go-service/business/web/v1/metrics"
+	"github.com/1core-dev/go-service/pkg/logger"
 )
 
 // Mux registers all the debug routes from standard library into a new mux
 // bypassing the use of the DefaultServerMux. Using the DefaultServerMux would
 // be a security risk since a dependency could inject a handler into our service
 // without us knowing it.
-func Mux() *http.ServeMux {
+func Mux(log *logger.Logger) *http.ServeMux {
 	mux := http.NewServeMux()
 
 	mux.HandleFunc("/debug/pprof", pprof.Index)
@@ -20,6 +23,9 @@ func Mux() *http.ServeMux {
 	mux.HandleFunc("/debug/pprof/symbol", pprof.Symbol)
 	mux.HandleFunc("/debug/pprof/trace", pprof.Trace)
 	mux.Handle("/debug/vars", expvar.Handler())
+	mux.Handle("/metrics", metrics.Handler())
+	mux.HandleFunc("GET /debug/loglevel", getLogLevel(log))
+	mux.HandleFunc("PUT /debug/loglevel", setLogLevel(log))
 
 	return mux
 }
@@ -0,0 +1,71 @@
+package debug
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/1core-dev/go-service/pkg/logger"
+)
+
+// logLevel represents the minimum level of the logger. When TTL is set on
+// an update, the level is reverted to the previous one after it has
+// elapsed.
+type logLevel struct {
+	Level    string     `json:"level"`
+	TTL      string     `json:"ttl,omitempty"`
+	RevertAt *time.Time `json:"revertAt,omitempty"`
+}
+
+func getLogLevel(log *logger.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		respondLogLevel(w, log)
+	}
+}
+
+func setLogLevel(log *logger.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var ll logLevel
+		if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, 1024)).Decode(&ll); err != nil {
+			http.Error(w, fmt.Sprintf("decoding level: %s", err), http.StatusBadRequest)
+			return
+		}
+
+		level, err := logger.ParseLevel(ll.Level)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("parsing level: %s", err), http.StatusBadRequest)
+			return
+		}
+
+		var ttl time.Duration
+		if ll.TTL != "" {
+			ttl, err = time.ParseDuration(ll.TTL)
+			if err != nil || ttl < 0 {
+				http.Error(w, fmt.Sprintf("parsing ttl: %q", ll.TTL), http.StatusBadRequest)
+				return
+			}
+		}
+
+		log.SetLevel(level, ttl)
+
+		log.Info(r.Context(), "log level changed", "level", level.String(), "ttl", ttl.String())
+
+		respondLogLevel(w, log)
+	}
+}
+
+func respondLogLevel(w http.ResponseWriter, log *logger.Logger) {
+	level, revertAt := log.Level()
+
+	ll := logLevel{
+		Level: level.String(),
+	}
+
+	if !revertAt.IsZero() {
+		ll.RevertAt = &revertAt
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(ll)
+}
@@ -0,0 +1,68 @@
+package debug
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/1core-dev/go-service/pkg/logger"
+)
+
+func Test_LogLevel(t *testing.T) {
+	tests := []struct {
+		name       string
+		body       string
+		statusCode int
+		level      string
+		revert     bool
+	}{
+		{name: "level", body: `{"level":"DEBUG"}`, statusCode: http.StatusOK, level: "DEBUG"},
+		{name: "ttl", body: `{"level":"WARN","ttl":"1h"}`, statusCode: http.StatusOK, level: "WARN", revert: true},
+		{name: "zeroTTL", body: `{"level":"ERROR","ttl":"0s"}`, statusCode: http.StatusOK, level: "ERROR"},
+		{name: "badJSON", body: `{"level":`, statusCode: http.StatusBadRequest, level: "INFO"},
+		{name: "badLevel", body: `{"level":"LOUD"}`, statusCode: http.StatusBadRequest, level: "INFO"},
+		{name: "badTTL", body: `{"level":"DEBUG","ttl":"soon"}`, statusCode: http.StatusBadRequest, level: "INFO"},
+		{name: "negativeTTL", body: `{"level":"DEBUG","ttl":"-1m"}`, statusCode: http.StatusBadRequest, level: "INFO"},
+		{name: "oversized", body: `{"level":"DEBUG","ttl":"` + strings.Repeat("1", 2048) + `"}`, statusCode: http.StatusBadRequest, level: "INFO"},
+	}
+
+	for _, tt := range tests {
+		log := logger.New(io.Discard, logger.LevelInfo, "TEST", nil)
+
+		r := httptest.NewRequest(http.MethodPut, "/debug/loglevel", strings.NewReader(tt.body))
+		w := httptest.NewRecorder()
+
+		setLogLevel(log)(w, r)
+
+		if w.Code != tt.statusCode {
+			t.Errorf("%s: Should receive a status code of %d for the response : %d.", tt.name, tt.statusCode, w.Code)
+			continue
+		}
+
+		if level, _ := log.Level(); level.String() != tt.level {
+			t.Errorf("%s: Should get the expected level : got %s, exp %s.", tt.name, level, tt.level)
+		}
+
+		if tt.statusCode != http.StatusOK {
+			continue
+		}
+
+		var ll logLevel
+		if err := json.Unmarshal(w.Body.Bytes(), &ll); err != nil {
+			t.Errorf("%s: Should be able to unmarshal the response : %s.", tt.name, err)
+			continue
+		}
+
+		if ll.Level != tt.level || (ll.RevertAt != nil) != tt.revert {
+			t.Errorf("%s: Should respond with the level : %+v.", tt.name, ll)
+		}
+
+		if tt.revert && time.Until(*ll.RevertAt) < 59*time.Minute {
+			t.Errorf("%s: Should respond with the time the level is reverted at : %v.", tt.name, ll.RevertAt)
+		}
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"expvar"
 	"runtime"
+	"time"
 )
 
 // This holds the single instance of the metrics value needed for collecting
@@ -13,13 +14,13 @@ import (
 var m *metrics
 
 // metrics represents the set of metrics we gather. These fields are
-// safe to be accessed concurrently thanks to expvar.
+// safe to be accessed concurrently thanks to expvar and prometheus.
 // No extra abstraction is required
 type metrics struct {
-	goroutines *expvar.Int
-	requests   *expvar.Int
-	errors     *expvar.Int
-	panics     *expvar.Int
+	requests *expvar.Int
+	errors   *expvar.Int
+	panics   *expvar.Int
+	prom     *promMetrics
 }
 
 // init constructs the metrics value that will be used to capture metrics.
@@ -27,11 +28,15 @@ type metrics struct {
 // inside of expvar is registered as a singleton. The use of once will make
 // sure this initialization only happens once.
 func init() {
+	expvar.Publish("goroutines", expvar.Func(func() any {
+		return runtime.NumGoroutine()
+	}))
+
 	m = &metrics{
-		goroutines: expvar.NewInt("goroutines"),
-		requests:   expvar.NewInt("requests"),
-		errors:     expvar.NewInt("errors"),
-		panics:     expvar.NewInt("panics"),
+		requests: expvar.NewInt("requests"),
+		errors:   expvar.NewInt("errors"),
+		panics:   expvar.NewInt("panics"),
+		prom:     newPromMetrics(),
 	}
 }
 
@@ -39,49 +44,71 @@ type ctxKey int
 
 const key ctxKey = 1
 
-// Set sets the metrics data into the context.
-func Set(ctx context.Context) context.Context {
-	return context.WithValue(ctx, key, m)
+// request holds the labels of the request being measured. The route is the
+// pattern the request matched so the number of label values stays bounded.
+type request struct {
+	route  string
+	method string
 }
 
-// AddGoroutines refreshes the goroutine metric every 100 requests.
-func AddGoroutines(ctx context.Context) int64 {
-	if v, ok := ctx.Value(key).(*metrics); ok {
-		if v.requests.Value()%100 == 0 {
-			g := int64(runtime.NumGoroutine())
-			v.goroutines.Set(g)
-			return g
-		}
-	}
-	return 0
+// Set sets the metrics data for the request into the context.
+func Set(ctx context.Context, route string, method string) context.Context {
+	return context.WithValue(ctx, key, &request{route: route, method: method})
 }
 
 // AddRequest increments the requests metric by 1.
 func AddRequests(ctx context.Context) int64 {
-	v, ok := ctx.Value(key).(*metrics)
-	if ok {
-		v.requests.Add(1)
-		return v.requests.Value()
+	if _, ok := ctx.Value(key).(*request); ok {
+		m.requests.Add(1)
+		return m.requests.Value()
 	}
 	return 0
 }
 
 // AddRequest increments the errors metric by 1.
 func AddErrors(ctx context.Context) int64 {
-	v, ok := ctx.Value(key).(*metrics)
-	if ok {
-		v.errors.Add(1)
-		return v.errors.Value()
+	if r, ok := ctx.Value(key).(*request); ok {
+		m.prom.errors.WithLabelValues(r.route, r.method).Inc()
+		m.errors.Add(1)
+		return m.errors.Value()
 	}
 	return 0
 }
 
 // AddPanics increments the panic metric by 1.
 func AddPanics(ctx context.Context) int64 {
-	v, ok := ctx.Value(key).(*metrics)
-	if ok {
-		v.panics.Add(1)
-		return v.panics.Value()
+	if r, ok := ctx.Value(key).(*request); ok {
+		m.prom.panics.WithLabelValues(r.route, r.method).Inc()
+		m.panics.Add(1)
+		return m.panics.Value()
 	}
 	return 0
 }
+
+// StartRequest marks the request as in flight. The returned function must
+// be called once the request is complete.
+func StartRequest(ctx context.Context) func() {
+	r, ok := ctx.Value(key).(*request)
+	if !ok {
+		return func() {}
+	}
+
+	g := m.prom.inFlight.WithLabelValues(r.route, r.method)
+	g.Inc()
+
+	return g.Dec
+}
+
+// ObserveRequest records the duration and response size of a completed
+// request under the class of its status code.
+func ObserveRequest(ctx context.Context, statusCode int, size int64, duration time.Duration) {
+	r, ok := ctx.Value(key).(*request)
+	if !ok {
+		return
+	}
+
+	status := statusClass(statusCode)
+
+	m.prom.duration.WithLabelValues(r.route, r.method, status).Observe(duration.Seconds())
+	m.prom.size.WithLabelValues(r.route, r.method, status).Observe(float64(size))
+}
@@ -0,0 +1,93 @@
+package metrics
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// registry holds the prometheus collectors of the service. A dedicated
+// registry is used so no dependency can register metrics without us
+// knowing about it.
+var registry = prometheus.NewRegistry()
+
+// promMetrics represents the set of prometheus metrics we gather per route.
+type promMetrics struct {
+	duration *prometheus.HistogramVec
+	size     *prometheus.HistogramVec
+	inFlight *prometheus.GaugeVec
+	errors   *prometheus.CounterVec
+	panics   *prometheus.CounterVec
+}
+
+func newPromMetrics() *promMetrics {
+	pm := promMetrics{
+		duration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "http_request_duration_seconds",
+			Help:    "Duration of the HTTP requests.",
+			Buckets: prometheus.DefBuckets,
+		}, []string{"route", "method", "status"}),
+
+		size: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "http_response_size_bytes",
+			Help:    "Size of the HTTP response bodies.",
+			Buckets: prometheus.ExponentialBuckets(100, 10, 6),
+		}, []string{"route", "method", "status"}),
+
+		inFlight: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Name: "http_requests_in_flight",
+			Help: "Number of HTTP requests being served.",
+		}, []string{"route", "method"}),
+
+		errors: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "http_request_errors_total",
+			Help: "Number of HTTP requests that returned an error.",
+		}, []string{"route", "method"}),
+
+		panics: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "http_request_panics_total",
+			Help: "Number of HTTP requests that panicked.",
+		}, []string{"route", "method"}),
+	}
+
+	registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		pm.duration,
+		pm.size,
+		pm.inFlight,
+		pm.errors,
+		pm.panics,
+	)
+
+	return &pm
+}
+
+// Register adds the collectors to the registry exposed by Handler.
+func Register(cs ...prometheus.Collector) error {
+	for _, c := range cs {
+		if err := registry.Register(c); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Handler returns the handler exposing the metrics in the prometheus text
+// format.
+func Handler() http.Handler {
+	return promhttp.HandlerFor(registry, promhttp.HandlerOpts{})
+}
+
+// statusClass returns the class of the status code, like 2xx.
+func statusClass(statusCode int) string {
+	if statusCode < 100 || statusCode > 599 {
+		return "unknown"
+	}
+
+	return strconv.Itoa(statusCode/100) + "xx"
+}
@@ -0,0 +1,51 @@
+package middlewares
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/1core-dev/go-service/business/data/audit"
+	"github.com/1core-dev/go-service/pkg/logger"
+	"github.com/1core-dev/go-service/pkg/web"
+)
+
+// Audit records every mutating API call in the tamper-evident audit log. It
+// must run outside of the Errors middleware so the final status is known.
+func Audit(log *logger.Logger, a *audit.Log) web.Middleware {
+	m := func(handler web.Handler) web.Handler {
+		h := func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+			ctx, span := web.AddSpan(ctx, "business.web.v1.mid.audit")
+			defer span.End()
+
+			err := handler(ctx, w, r)
+
+			switch r.Method {
+			case http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete:
+			default:
+				return err
+			}
+
+			v := web.GetValues(ctx)
+
+			ne := audit.NewEntry{
+				Actor:   v.Subject,
+				Method:  r.Method,
+				Route:   web.Route(r),
+				Status:  v.StatusCode,
+				TraceID: v.TraceID,
+			}
+
+			// The response has already been sent, so a failure to record the
+			// call is logged rather than returned.
+			if _, err := a.Append(ctx, ne); err != nil {
+				log.Error(ctx, "audit", "msg", err)
+			}
+
+			return err
+		}
+
+		return h
+	}
+
+	return m
+}
@@ -5,6 +5,7 @@ import (
 	"errors"
 	"net/http"
 
+	"github.com/1core-dev/go-service/business/data/actor"
 	"github.com/1core-dev/go-service/business/web/v1/auth"
 	"github.com/1core-dev/go-service/business/web/v1/response"
 	"github.com/1core-dev/go-service/pkg/web"
@@ -20,12 +21,18 @@ var (
 func Authenticate(a *auth.Auth) web.Middleware {
 	m := func(handler web.Handler) web.Handler {
 		h := func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+			ctx, span := web.AddSpan(ctx, "business.web.v1.mid.authenticate")
+			defer span.End()
+
 			claims, err := a.Authenticate(ctx, r.Header.Get("authorization"))
 			if err != nil {
 				return auth.NewAuthError("authenticate: failed: %s", err)
 			}
 
 			ctx = auth.SetClaims(ctx, claims)
+			ctx = actor.Set(ctx, claims.Subject)
+			web.SetSubject(ctx, claims.Subject)
+			ctx = logLevel(ctx, a, claims, r)
 
 			return handler(ctx, w, r)
 		}
@@ -41,6 +48,9 @@ func Authenticate(a *auth.Auth) web.Middleware {
 func Authorize(a *auth.Auth, rule string) web.Middleware {
 	m := func(handler web.Handler) web.Handler {
 		h := func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+			ctx, span := web.AddSpan(ctx, "business.web.v1.mid.authorize")
+			defer span.End()
+
 			claims := auth.GetClaims(ctx)
 			if claims.Subject == "" {
 				return auth.NewAuthError("authorize: you are not authorized for that action, no claims")
@@ -0,0 +1,63 @@
+package middlewares
+
+import (
+	"context"
+	"net/http"
+	"strings"
+
+	"github.com/1core-dev/go-service/pkg/web"
+)
+
+// CacheControl sets the Cache-Control header with the specified directives
+// on successful responses. Error responses are never cached. No header is
+// set when the directives are empty.
+//
+// A response to a request carrying credentials is only meant for the caller,
+// so private is added unless the directives already say who can store it.
+func CacheControl(directives string) web.Middleware {
+	private := directives
+	if directives != "" && !hasDirective(directives, "public", "private", "no-store") {
+		private = "private, " + directives
+	}
+
+	m := func(handler web.Handler) web.Handler {
+		h := func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+			ctx, span := web.AddSpan(ctx, "business.web.v1.mid.cachecontrol")
+			defer span.End()
+
+			if directives == "" {
+				return handler(ctx, w, r)
+			}
+
+			value := directives
+			if r.Header.Get("Authorization") != "" {
+				value = private
+			}
+
+			w.Header().Set("Cache-Control", value)
+
+			if err := handler(ctx, w, r); err != nil {
+				w.Header().Del("Cache-Control")
+				return err
+			}
+
+			return nil
+		}
+		return h
+	}
+	return m
+}
+
+// hasDirective reports whether any of the names is one of the directives.
+func hasDirective(directives string, names ...string) bool {
+	for _, d := range strings.Split(directives, ",") {
+		name, _, _ := strings.Cut(strings.TrimSpace(d), "=")
+		for _, n := range names {
+			if strings.EqualFold(name, n) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
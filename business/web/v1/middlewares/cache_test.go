@@ -0,0 +1,60 @@
+package middlewares_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/1core-dev/go-service/business/web/v1/middlewares"
+	"github.com/1core-dev/go-service/pkg/web"
+)
+
+func Test_CacheControl(t *testing.T) {
+	errFailed := errors.New("failed")
+
+	tests := []struct {
+		name       string
+		directives string
+		authorized bool
+		err        error
+		exp        string
+	}{
+		{name: "anonymous", directives: "no-cache", exp: "no-cache"},
+		{name: "authorized", directives: "no-cache", authorized: true, exp: "private, no-cache"},
+		{name: "maxAge", directives: "max-age=60", authorized: true, exp: "private, max-age=60"},
+		{name: "public", directives: "public, max-age=60", authorized: true, exp: "public, max-age=60"},
+		{name: "private", directives: "Private, no-cache", authorized: true, exp: "Private, no-cache"},
+		{name: "noStore", directives: "no-store", authorized: true, exp: "no-store"},
+		{name: "empty", directives: "", authorized: true, exp: ""},
+		{name: "error", directives: "no-cache", authorized: true, err: errFailed, exp: ""},
+	}
+
+	for _, tt := range tests {
+		handler := func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+			if tt.err != nil {
+				return tt.err
+			}
+
+			return web.Respond(ctx, w, nil, http.StatusNoContent)
+		}
+
+		h := middlewares.CacheControl(tt.directives)(handler)
+
+		r := httptest.NewRequest(http.MethodGet, "/v1/users", nil)
+		if tt.authorized {
+			r.Header.Set("Authorization", "Bearer token")
+		}
+		w := httptest.NewRecorder()
+
+		err := h(context.Background(), w, r)
+		if !errors.Is(err, tt.err) {
+			t.Errorf("%s: Should get the error of the handler : %v.", tt.name, err)
+		}
+
+		if got := w.Header().Get("Cache-Control"); got != tt.exp {
+			t.Errorf("%s: Should get the expected Cache-Control : got %q, exp %q.", tt.name, got, tt.exp)
+		}
+	}
+}
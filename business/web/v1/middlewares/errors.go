@@ -5,6 +5,7 @@ import (
 	"net/http"
 
 	"github.com/1core-dev/go-service/business/web/v1/auth"
+	"github.com/1core-dev/go-service/business/web/v1/metrics"
 	"github.com/1core-dev/go-service/business/web/v1/response"
 	"github.com/1core-dev/go-service/pkg/logger"
 	"github.com/1core-dev/go-service/pkg/validate"
@@ -17,8 +18,18 @@ import (
 func Errors(log *logger.Logger) web.Middleware {
 	m := func(handler web.Handler) web.Handler {
 		h := func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+			ctx, span := web.AddSpan(ctx, "business.web.v1.mid.errors")
+			defer span.End()
+
 			if err := handler(ctx, w, r); err != nil {
 				log.Error(ctx, "message", "msg", err)
+				metrics.AddErrors(ctx)
+
+				// Part of the response was already sent, so the connection
+				// is aborted rather than corrupting it with an error document.
+				if web.IsAbort(err) {
+					return err
+				}
 
 				var e response.ErrorDocument
 				var status int
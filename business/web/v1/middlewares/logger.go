@@ -13,6 +13,9 @@ import (
 func Logger(log *logger.Logger) web.Middleware {
 	m := func(handler web.Handler) web.Handler {
 		h := func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+			ctx, span := web.AddSpan(ctx, "business.web.v1.mid.logger")
+			defer span.End()
+
 			v := web.GetValues(ctx)
 
 			path := r.URL.Path
@@ -0,0 +1,35 @@
+package middlewares
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/1core-dev/go-service/business/web/v1/auth"
+	"github.com/1core-dev/go-service/pkg/logger"
+	"github.com/google/uuid"
+)
+
+// HeaderLogLevel is the header admins can use to change the minimum log
+// level for the rest of a single request, like X-Log-Level: debug.
+const HeaderLogLevel = "X-Log-Level"
+
+// logLevel applies the level requested in the header when the claims are
+// from an admin. An invalid level or a header sent by anyone else is
+// ignored.
+func logLevel(ctx context.Context, a *auth.Auth, claims auth.Claims, r *http.Request) context.Context {
+	value := r.Header.Get(HeaderLogLevel)
+	if value == "" {
+		return ctx
+	}
+
+	level, err := logger.ParseLevel(value)
+	if err != nil {
+		return ctx
+	}
+
+	if err := a.Authorize(ctx, claims, uuid.Nil, auth.RuleAdminOnly); err != nil {
+		return ctx
+	}
+
+	return logger.WithLevel(ctx, level)
+}
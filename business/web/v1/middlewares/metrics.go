@@ -3,30 +3,73 @@ package middlewares
 import (
 	"context"
 	"net/http"
+	"time"
 
 	"github.com/1core-dev/go-service/business/web/v1/metrics"
 	"github.com/1core-dev/go-service/pkg/web"
 )
 
-// Metrics updates program counters.
+// Metrics updates program counters and records the duration and response
+// size of every request. It must run outside of the Errors middleware so
+// error responses are measured too.
 func Metrics() web.Middleware {
 	m := func(handler web.Handler) web.Handler {
 		h := func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
-			ctx = metrics.Set(ctx)
+			ctx, span := web.AddSpan(ctx, "business.web.v1.mid.metrics")
+			defer span.End()
 
-			err := handler(ctx, w, r)
+			ctx = metrics.Set(ctx, web.Route(r), r.Method)
 
-			n := metrics.AddRequests(ctx)
-			if n%1000 == 0 {
-				metrics.AddGoroutines(ctx)
-			}
+			done := metrics.StartRequest(ctx)
+			defer done()
+
+			rw := responseRecorder{ResponseWriter: w}
+			start := time.Now()
+
+			err := handler(ctx, &rw, r)
 
-			if err != nil {
-				metrics.AddErrors(ctx)
+			statusCode := rw.statusCode
+			if statusCode == 0 {
+				statusCode = web.GetValues(ctx).StatusCode
 			}
+
+			metrics.AddRequests(ctx)
+			metrics.ObserveRequest(ctx, statusCode, rw.size, time.Since(start))
+
 			return err
 		}
 		return h
 	}
 	return m
 }
+
+// responseRecorder records the status code and the size of the body of a
+// response. Unwrap gives http.ResponseController access to the original
+// writer so handlers can still flush and extend deadlines.
+type responseRecorder struct {
+	http.ResponseWriter
+	statusCode int
+	size       int64
+}
+
+func (rr *responseRecorder) WriteHeader(statusCode int) {
+	if rr.statusCode == 0 {
+		rr.statusCode = statusCode
+	}
+	rr.ResponseWriter.WriteHeader(statusCode)
+}
+
+func (rr *responseRecorder) Write(b []byte) (int, error) {
+	if rr.statusCode == 0 {
+		rr.statusCode = http.StatusOK
+	}
+
+	n, err := rr.ResponseWriter.Write(b)
+	rr.size += int64(n)
+
+	return n, err
+}
+
+func (rr *responseRecorder) Unwrap() http.ResponseWriter {
+	return rr.ResponseWriter
+}
@@ -15,6 +15,8 @@ import (
 func Panics() web.Middleware {
 	m := func(handler web.Handler) web.Handler {
 		h := func(ctx context.Context, w http.ResponseWriter, r *http.Request) (err error) {
+			ctx, span := web.AddSpan(ctx, "business.web.v1.mid.panics")
+			defer span.End()
 
 			// Defer a function to recover from a panic and set the err return
 			// variable after the fact.
@@ -0,0 +1,215 @@
+package middlewares_test
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/1core-dev/go-service/business/core/user"
+	"github.com/1core-dev/go-service/business/web/v1/auth"
+	"github.com/1core-dev/go-service/business/web/v1/middlewares"
+	"github.com/1core-dev/go-service/pkg/keystore"
+	"github.com/1core-dev/go-service/pkg/logger"
+	"github.com/1core-dev/go-service/pkg/web"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const kid = "test-kid"
+
+func Test_Trace(t *testing.T) {
+	t.Run("spantree", spanTree)
+	t.Run("requestid", requestID)
+}
+
+// =============================================================================
+
+func spanTree(t *testing.T) {
+	app, exporter, token := newTraceApp(t)
+
+	const traceID = "4bf92f3577b34da6a3ce929d0e0e4736"
+	const parentID = "00f067aa0ba902b7"
+
+	r := httptest.NewRequest(http.MethodGet, "/v1/users/"+uuid.NewString(), nil)
+	r.Header.Set("Authorization", "Bearer "+token)
+	r.Header.Set("traceparent", "00-"+traceID+"-"+parentID+"-01")
+	w := httptest.NewRecorder()
+
+	app.ServeHTTP(w, r)
+
+	if w.Code != http.StatusNoContent {
+		t.Fatalf("Should receive a status code of 204 for the response : %d", w.Code)
+	}
+
+	spans := exporter.GetSpans()
+
+	byName := make(map[string]tracetest.SpanStub)
+	for _, span := range spans {
+		if span.SpanContext.TraceID().String() != traceID {
+			t.Errorf("Should record span %s in the incoming trace : %s", span.Name, span.SpanContext.TraceID())
+		}
+		byName[span.Name] = span
+	}
+
+	root, exists := byName["pkg.web.handle"]
+	if !exists {
+		t.Fatalf("Should record the request span : %v", names(spans))
+	}
+
+	if root.Parent.SpanID().String() != parentID || !root.Parent.IsRemote() {
+		t.Errorf("Should parent the request span to the caller's span : %s", root.Parent.SpanID())
+	}
+
+	exp := "00-" + traceID + "-" + root.SpanContext.SpanID().String() + "-01"
+	if got := w.Header().Get("traceparent"); got != exp {
+		t.Errorf("Should echo the request span in the traceparent header : got %s, exp %s", got, exp)
+	}
+
+	tree := []struct {
+		name   string
+		parent string
+	}{
+		{"business.web.v1.mid.errors", "pkg.web.handle"},
+		{"business.web.v1.mid.authenticate", "business.web.v1.mid.errors"},
+		{"business.web.v1.mid.authorize", "business.web.v1.mid.authenticate"},
+		{"handler", "business.web.v1.mid.authorize"},
+	}
+
+	for _, tt := range tree {
+		span, exists := byName[tt.name]
+		if !exists {
+			t.Errorf("Should record the %s span : %v", tt.name, names(spans))
+			continue
+		}
+
+		if span.Parent.SpanID() != byName[tt.parent].SpanContext.SpanID() {
+			t.Errorf("Should record the %s span as a child of %s.", tt.name, tt.parent)
+		}
+	}
+
+	// Both the authentication and the authorization policies are evaluated.
+	var opa []trace.SpanID
+	for _, span := range spans {
+		if span.Name == "business.web.v1.auth.opa" {
+			opa = append(opa, span.Parent.SpanID())
+		}
+	}
+
+	if len(opa) != 2 {
+		t.Fatalf("Should record two opa evaluation spans : %d", len(opa))
+	}
+
+	for _, name := range []string{"business.web.v1.mid.authenticate", "business.web.v1.mid.authorize"} {
+		id := byName[name].SpanContext.SpanID()
+		if opa[0] != id && opa[1] != id {
+			t.Errorf("Should record an opa evaluation span as a child of %s.", name)
+		}
+	}
+}
+
+func requestID(t *testing.T) {
+	app, exporter, token := newTraceApp(t)
+
+	requestID := uuid.New()
+
+	r := httptest.NewRequest(http.MethodGet, "/v1/users/"+uuid.NewString(), nil)
+	r.Header.Set("Authorization", "Bearer "+token)
+	r.Header.Set("X-Request-ID", requestID.String())
+	w := httptest.NewRecorder()
+
+	app.ServeHTTP(w, r)
+
+	if got := w.Header().Get("X-Request-ID"); got != requestID.String() {
+		t.Errorf("Should echo the request ID : got %s, exp %s", got, requestID)
+	}
+
+	for _, span := range exporter.GetSpans() {
+		if got := span.SpanContext.TraceID(); got != trace.TraceID(requestID) {
+			t.Errorf("Should use the request ID as the trace ID for span %s : %s", span.Name, got)
+		}
+	}
+}
+
+// =============================================================================
+
+func newTraceApp(t *testing.T) (*web.App, *tracetest.InMemoryExporter, string) {
+	pk, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("Should be able to generate a key : %s", err)
+	}
+
+	der, err := x509.MarshalPKCS8PrivateKey(pk)
+	if err != nil {
+		t.Fatalf("Should be able to marshal the key : %s", err)
+	}
+
+	ks := keystore.NewMap(map[string]keystore.PrivateKey{
+		kid: {
+			PK:  pk,
+			PEM: pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}),
+		},
+	})
+
+	log := logger.New(io.Discard, logger.LevelInfo, "TEST", web.GetTraceID)
+
+	a, err := auth.New(auth.Config{
+		Log:       log,
+		KeyLookup: ks,
+		Issuer:    "service project",
+	})
+	if err != nil {
+		t.Fatalf("Should be able to construct auth : %s", err)
+	}
+
+	claims := auth.Claims{
+		RegisteredClaims: jwt.RegisteredClaims{
+			Subject:   uuid.NewString(),
+			Issuer:    "service project",
+			ExpiresAt: jwt.NewNumericDate(time.Now().UTC().Add(time.Hour)),
+			IssuedAt:  jwt.NewNumericDate(time.Now().UTC()),
+		},
+		Roles: []user.Role{user.RoleAdmin},
+	}
+
+	token, err := a.GenerateToken(kid, claims)
+	if err != nil {
+		t.Fatalf("Should be able to generate a token : %s", err)
+	}
+
+	exporter := tracetest.NewInMemoryExporter()
+	tp := sdktrace.NewTracerProvider(
+		sdktrace.WithSyncer(exporter),
+		sdktrace.WithIDGenerator(web.IDGenerator{}),
+	)
+
+	handler := func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+		_, span := web.AddSpan(ctx, "handler")
+		defer span.End()
+
+		return web.Respond(ctx, w, nil, http.StatusNoContent)
+	}
+
+	app := web.NewApp(nil, tp.Tracer("test"), middlewares.Errors(log))
+	app.Handle(http.MethodGet, "v1", "/users/:user_id", handler,
+		middlewares.Authenticate(a), middlewares.Authorize(a, auth.RuleAdminOrSubject))
+
+	return app, exporter, token
+}
+
+func names(spans tracetest.SpanStubs) []string {
+	var names []string
+	for _, span := range spans {
+		names = append(names, span.Name)
+	}
+	return names
+}
@@ -17,6 +17,9 @@ import (
 func ExecuteInTransation(log *logger.Logger, bgn transaction.Beginner) web.Middleware {
 	m := func(handler web.Handler) web.Handler {
 		h := func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+			ctx, span := web.AddSpan(ctx, "business.web.v1.mid.transaction")
+			defer span.End()
+
 			hasCommited := false
 
 			log.Info(ctx, "BEGIN TRANSACTION")
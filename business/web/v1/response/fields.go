@@ -0,0 +1,34 @@
+package response
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// Fields reduces the value to the specified JSON fields so clients can
+// request a sparse response. The field names must be validated by the
+// caller. If no fields are specified, every field is kept.
+func Fields(v any, fields []string) (map[string]json.RawMessage, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+
+	var all map[string]json.RawMessage
+	if err := json.Unmarshal(data, &all); err != nil {
+		return nil, fmt.Errorf("value is not an object: %w", err)
+	}
+
+	if len(fields) == 0 {
+		return all, nil
+	}
+
+	m := make(map[string]json.RawMessage, len(fields))
+	for _, field := range fields {
+		if value, exists := all[field]; exists {
+			m[field] = value
+		}
+	}
+
+	return m, nil
+}
@@ -0,0 +1,87 @@
+package response
+
+import (
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// PageLinks contains the URLs of the pages around the current page. A link
+// is omitted when the page doesn't exist or can't be known.
+type PageLinks struct {
+	First string `json:"first,omitempty"`
+	Prev  string `json:"prev,omitempty"`
+	Next  string `json:"next,omitempty"`
+	Last  string `json:"last,omitempty"`
+}
+
+// NewPageLinks constructs the links for the page of the request URL. Without
+// an exact total, the last page is unknown and the next page is assumed to
+// exist when the current page is full.
+func NewPageLinks(u *url.URL, page int, rowsPerPage int, items int, total *int, estimated bool) PageLinks {
+	if rowsPerPage < 1 {
+		return PageLinks{}
+	}
+
+	links := PageLinks{
+		First: pageURL(u, 1, rowsPerPage),
+	}
+
+	if page > 1 {
+		links.Prev = pageURL(u, page-1, rowsPerPage)
+	}
+
+	switch {
+	case total != nil && !estimated:
+		last := max((*total+rowsPerPage-1)/rowsPerPage, 1)
+
+		links.Last = pageURL(u, last, rowsPerPage)
+		if page < last {
+			links.Next = pageURL(u, page+1, rowsPerPage)
+		}
+
+	case items == rowsPerPage:
+		links.Next = pageURL(u, page+1, rowsPerPage)
+	}
+
+	return links
+}
+
+// Header returns the links in the form of an RFC 8288 Link header.
+func (pl PageLinks) Header() string {
+	var parts []string
+
+	add := func(rel string, link string) {
+		if link != "" {
+			parts = append(parts, "<"+link+`>; rel="`+rel+`"`)
+		}
+	}
+
+	add("first", pl.First)
+	add("prev", pl.Prev)
+	add("next", pl.Next)
+	add("last", pl.Last)
+
+	return strings.Join(parts, ", ")
+}
+
+// pageURL returns the request URL for a different page. The raw query is
+// edited directly since parsing it would drop any parameter containing a
+// semicolon.
+func pageURL(u *url.URL, page int, rowsPerPage int) string {
+	var pairs []string
+	for _, pair := range strings.Split(u.RawQuery, "&") {
+		key, _, _ := strings.Cut(pair, "=")
+
+		switch key {
+		case "", "page", "rows":
+			continue
+		}
+
+		pairs = append(pairs, pair)
+	}
+
+	pairs = append(pairs, "page="+strconv.Itoa(page), "rows="+strconv.Itoa(rowsPerPage))
+
+	return u.Path + "?" + strings.Join(pairs, "&")
+}
@@ -0,0 +1,112 @@
+package response_test
+
+import (
+	"net/url"
+	"testing"
+
+	"github.com/1core-dev/go-service/business/web/v1/response"
+)
+
+func Test_PageLinks(t *testing.T) {
+	t.Run("links", pageLinks)
+	t.Run("url", pageLinksURL)
+	t.Run("header", pageLinksHeader)
+}
+
+// =============================================================================
+
+func pageLinks(t *testing.T) {
+	u, err := url.Parse("/v1/users")
+	if err != nil {
+		t.Fatalf("Should be able to parse the URL : %s.", err)
+	}
+
+	total := func(n int) *int { return &n }
+
+	const (
+		page1 = "/v1/users?page=1&rows=10"
+		page2 = "/v1/users?page=2&rows=10"
+		page3 = "/v1/users?page=3&rows=10"
+		page4 = "/v1/users?page=4&rows=10"
+	)
+
+	tests := []struct {
+		name      string
+		page      int
+		rows      int
+		items     int
+		total     *int
+		estimated bool
+		exp       response.PageLinks
+	}{
+		{name: "first", page: 1, rows: 10, items: 10, total: total(25), exp: response.PageLinks{First: page1, Next: page2, Last: page3}},
+		{name: "middle", page: 2, rows: 10, items: 10, total: total(25), exp: response.PageLinks{First: page1, Prev: page1, Next: page3, Last: page3}},
+		{name: "last", page: 3, rows: 10, items: 5, total: total(25), exp: response.PageLinks{First: page1, Prev: page2, Last: page3}},
+		{name: "exact", page: 3, rows: 10, items: 10, total: total(30), exp: response.PageLinks{First: page1, Prev: page2, Last: page3}},
+		{name: "empty", page: 1, rows: 10, items: 0, total: total(0), exp: response.PageLinks{First: page1, Last: page1}},
+		{name: "pastLast", page: 4, rows: 10, items: 0, total: total(25), exp: response.PageLinks{First: page1, Prev: page3, Last: page3}},
+		{name: "noTotalFull", page: 3, rows: 10, items: 10, exp: response.PageLinks{First: page1, Prev: page2, Next: page4}},
+		{name: "noTotalPartial", page: 3, rows: 10, items: 9, exp: response.PageLinks{First: page1, Prev: page2}},
+		{name: "estimatedFull", page: 1, rows: 10, items: 10, total: total(5), estimated: true, exp: response.PageLinks{First: page1, Next: page2}},
+		{name: "estimatedPartial", page: 2, rows: 10, items: 3, total: total(500), estimated: true, exp: response.PageLinks{First: page1, Prev: page1}},
+		{name: "noRows", page: 1, rows: 0, items: 0, total: total(25), exp: response.PageLinks{}},
+	}
+
+	for _, tt := range tests {
+		got := response.NewPageLinks(u, tt.page, tt.rows, tt.items, tt.total, tt.estimated)
+
+		if got != tt.exp {
+			t.Errorf("%s: Should get the expected links : got %+v, exp %+v.", tt.name, got, tt.exp)
+		}
+	}
+}
+
+func pageLinksURL(t *testing.T) {
+	tests := []struct {
+		name string
+		url  string
+		exp  string
+	}{
+		{name: "replace", url: "/v1/users?page=5&rows=20&name=Bill", exp: "/v1/users?name=Bill&page=2&rows=10"},
+		{name: "order", url: "/v1/users?orderBy=name,DESC&email=bill%40example.com", exp: "/v1/users?orderBy=name,DESC&email=bill%40example.com&page=2&rows=10"},
+		{name: "semicolon", url: "/v1/users?filter=a;b&page=1", exp: "/v1/users?filter=a;b&page=2&rows=10"},
+		{name: "emptyPairs", url: "/v1/users?&name=Bill&&", exp: "/v1/users?name=Bill&page=2&rows=10"},
+		{name: "prefix", url: "/v1/users?pages=3&rowsPerPage=4", exp: "/v1/users?pages=3&rowsPerPage=4&page=2&rows=10"},
+	}
+
+	for _, tt := range tests {
+		u, err := url.Parse(tt.url)
+		if err != nil {
+			t.Fatalf("%s: Should be able to parse the URL : %s.", tt.name, err)
+		}
+
+		got := response.NewPageLinks(u, 1, 10, 10, nil, false)
+
+		if got.Next != tt.exp {
+			t.Errorf("%s: Should keep the other parameters : got %q, exp %q.", tt.name, got.Next, tt.exp)
+		}
+	}
+}
+
+func pageLinksHeader(t *testing.T) {
+	tests := []struct {
+		name  string
+		links response.PageLinks
+		exp   string
+	}{
+		{name: "none", links: response.PageLinks{}, exp: ""},
+		{name: "first", links: response.PageLinks{First: "/a?page=1"}, exp: `</a?page=1>; rel="first"`},
+		{
+			name:  "all",
+			links: response.PageLinks{First: "/a?page=1", Prev: "/a?page=2", Next: "/a?page=4", Last: "/a?page=9"},
+			exp:   `</a?page=1>; rel="first", </a?page=2>; rel="prev", </a?page=4>; rel="next", </a?page=9>; rel="last"`,
+		},
+		{name: "gap", links: response.PageLinks{First: "/a?page=1", Last: "/a?page=1"}, exp: `</a?page=1>; rel="first", </a?page=1>; rel="last"`},
+	}
+
+	for _, tt := range tests {
+		if got := tt.links.Header(); got != tt.exp {
+			t.Errorf("%s: Should get the expected header : got %q, exp %q.", tt.name, got, tt.exp)
+		}
+	}
+}
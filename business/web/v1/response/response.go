@@ -2,21 +2,35 @@ package response
 
 import "errors"
 
-// PageDocument is the form used for API responses from query API calls.
+// PageDocument is the form used for API responses from query API calls. The
+// total is omitted when the caller asked not to count the rows.
 type PageDocument[T any] struct {
-	Items       []T `json:"items"`
-	Total       int `json:"total"`
-	Page        int `json:"page"`
-	RowsPerPage int `json:"rowsPerPage"`
+	Items          []T       `json:"items"`
+	Total          *int      `json:"total,omitempty"`
+	TotalEstimated bool      `json:"totalEstimated,omitempty"`
+	Page           int       `json:"page"`
+	RowsPerPage    int       `json:"rowsPerPage"`
+	Links          PageLinks `json:"links"`
 }
 
-// NewPageDocument constructs a response value for a web paging response.
-func NewPageDocument[T any](items []T, total int, page int, rowsPrePage int) PageDocument[T] {
-	return PageDocument[T]{
+// CursorDocument is the form used for API responses from query API calls
+// using keyset paging. The cursors are omitted when there is no page in
+// that direction.
+type CursorDocument[T any] struct {
+	Items       []T    `json:"items"`
+	RowsPerPage int    `json:"rowsPerPage"`
+	NextCursor  string `json:"nextCursor,omitempty"`
+	PrevCursor  string `json:"prevCursor,omitempty"`
+}
+
+// NewCursorDocument constructs a response value for a web keyset paging
+// response.
+func NewCursorDocument[T any](items []T, rowsPerPage int, nextCursor string, prevCursor string) CursorDocument[T] {
+	return CursorDocument[T]{
 		Items:       items,
-		Total:       total,
-		Page:        page,
-		RowsPerPage: rowsPrePage,
+		RowsPerPage: rowsPerPage,
+		NextCursor:  nextCursor,
+		PrevCursor:  prevCursor,
 	}
 }
 
@@ -3,20 +3,25 @@ package v1
 import (
 	"os"
 
+	"github.com/1core-dev/go-service/business/data/audit"
 	"github.com/1core-dev/go-service/business/web/v1/auth"
 	"github.com/1core-dev/go-service/business/web/v1/middlewares"
 	"github.com/1core-dev/go-service/pkg/logger"
 	"github.com/1core-dev/go-service/pkg/web"
 	"github.com/jmoiron/sqlx"
+	"go.opentelemetry.io/otel/trace"
 )
 
 // APIMuxConfig contains all the mandatory system required by handlers.
 type APIMuxConfig struct {
-	Build    string
-	Shutdown chan os.Signal
-	Log      *logger.Logger
-	Auth     *auth.Auth
-	DB       *sqlx.DB
+	Build        string
+	Shutdown     chan os.Signal
+	Log          *logger.Logger
+	Auth         *auth.Auth
+	DB           *sqlx.DB
+	CursorKey    []byte
+	CacheControl string
+	Tracer       trace.Tracer
 }
 
 // RouteAdder defines behavior that sets the routes to bind for an instance
@@ -29,9 +34,11 @@ type RouteAdder interface {
 func APIMux(cfg APIMuxConfig, routeAdder RouteAdder) *web.App {
 	app := web.NewApp(
 		cfg.Shutdown,
+		cfg.Tracer,
 		middlewares.Logger(cfg.Log),
-		middlewares.Errors(cfg.Log),
+		middlewares.Audit(cfg.Log, audit.New(cfg.Log, cfg.DB)),
 		middlewares.Metrics(),
+		middlewares.Errors(cfg.Log),
 		middlewares.Panics(),
 	)
 
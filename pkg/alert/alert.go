@@ -0,0 +1,332 @@
+// Package alert provides support for sending alerts for the records being
+// logged without blocking the code doing the logging.
+package alert
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/1core-dev/go-service/pkg/logger"
+)
+
+// Alert represents the records with the same message logged from the same
+// file. Count is the number of records the alert stands for, logged between
+// First and Last. The attributes are the ones of the last record.
+type Alert struct {
+	Service    string            `json:"service,omitempty"`
+	Level      string            `json:"level"`
+	Message    string            `json:"message"`
+	File       string            `json:"file"`
+	Count      int               `json:"count"`
+	First      time.Time         `json:"first"`
+	Last       time.Time         `json:"last"`
+	Attributes map[string]string `json:"attributes,omitempty"`
+}
+
+// Notifier delivers alerts to a destination.
+type Notifier interface {
+	Notify(ctx context.Context, a Alert) error
+}
+
+// Config represents the configuration of the alerter.
+//
+// QueueSize is the number of records and alerts that can be waiting to be
+// processed before new ones are dropped. Window is the time records with
+// the same message and file are grouped together for: the first one is
+// sent right away and the ones following within the window are sent as a
+// single alert once it has elapsed. A failed delivery is retried up to
+// Retries times, waiting Backoff before the first retry and doubling the
+// wait after that.
+//
+// The Log is used to report failed deliveries at the warn level, so it must
+// not send warn records to the alerter.
+type Config struct {
+	Log       *logger.Logger
+	Service   string
+	Notifiers []Notifier
+	QueueSize int
+	Window    time.Duration
+	Retries   int
+	Backoff   time.Duration
+}
+
+// Alerter receives records and delivers them as alerts on its own
+// goroutines.
+type Alerter struct {
+	cfg          Config
+	records      chan logger.Record
+	alerts       chan Alert
+	quit         chan struct{}
+	done         chan struct{}
+	ctx          context.Context
+	cancel       context.CancelFunc
+	dropped      atomic.Int64
+	shutdownOnce sync.Once
+}
+
+// New constructs an alerter and starts processing the records it receives.
+func New(cfg Config) *Alerter {
+	if cfg.QueueSize <= 0 {
+		cfg.QueueSize = 100
+	}
+
+	if cfg.Window <= 0 {
+		cfg.Window = time.Minute
+	}
+
+	if cfg.Backoff <= 0 {
+		cfg.Backoff = time.Second
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	a := Alerter{
+		cfg:     cfg,
+		records: make(chan logger.Record, cfg.QueueSize),
+		alerts:  make(chan Alert, cfg.QueueSize),
+		quit:    make(chan struct{}),
+		done:    make(chan struct{}),
+		ctx:     ctx,
+		cancel:  cancel,
+	}
+
+	var wg sync.WaitGroup
+	wg.Add(2)
+
+	go func() {
+		defer wg.Done()
+		a.group()
+	}()
+
+	go func() {
+		defer wg.Done()
+		a.deliver()
+	}()
+
+	go func() {
+		wg.Wait()
+		close(a.done)
+	}()
+
+	return &a
+}
+
+// Send queues the record to be sent as an alert. It never blocks, when the
+// queue is full or the alerter has been shut down the record is dropped.
+// Send can be used as a logger.EventFunc and does nothing on a nil alerter,
+// so records logged before the alerter is constructed are ignored.
+func (a *Alerter) Send(ctx context.Context, r logger.Record) {
+	if a == nil {
+		return
+	}
+
+	select {
+	case a.records <- r:
+	default:
+		a.dropped.Add(1)
+	}
+}
+
+// Dropped returns the number of records and alerts that were dropped
+// because the queues were full.
+func (a *Alerter) Dropped() int64 {
+	return a.dropped.Load()
+}
+
+// Shutdown stops receiving records, sends the alerts for the records that
+// were already received and waits for the deliveries to complete. When the
+// context is done first, the pending deliveries are abandoned.
+func (a *Alerter) Shutdown(ctx context.Context) error {
+	a.shutdownOnce.Do(func() {
+		close(a.quit)
+	})
+
+	select {
+	case <-a.done:
+		a.cancel()
+		return nil
+
+	case <-ctx.Done():
+		a.cancel()
+		<-a.done
+		return fmt.Errorf("alerter shutdown: %w", ctx.Err())
+	}
+}
+
+// =============================================================================
+
+type groupKey struct {
+	message string
+	file    string
+}
+
+// group holds the records received since the last alert was sent for a
+// message and file.
+type group struct {
+	alert Alert
+	until time.Time
+}
+
+// group deduplicates the records and groups them into alerts.
+func (a *Alerter) group() {
+	defer close(a.alerts)
+
+	groups := make(map[groupKey]*group)
+
+	ticker := time.NewTicker(a.cfg.Window / 4)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case r := <-a.records:
+			a.receive(groups, r)
+
+		case now := <-ticker.C:
+			a.expire(groups, now)
+
+		case <-a.quit:
+			a.flush(groups)
+			return
+		}
+	}
+}
+
+// flush groups the records still in the queue and sends the alerts for
+// every group, waiting for room in the queue of alerts if necessary.
+func (a *Alerter) flush(groups map[groupKey]*group) {
+	for {
+		select {
+		case r := <-a.records:
+			a.receive(groups, r)
+			continue
+		default:
+		}
+		break
+	}
+
+	for _, g := range groups {
+		if g.alert.Count > 0 {
+			a.alerts <- g.alert
+		}
+	}
+}
+
+func (a *Alerter) receive(groups map[groupKey]*group, r logger.Record) {
+	key := groupKey{message: r.Message, file: r.File}
+
+	g, exists := groups[key]
+	if !exists {
+		alert := a.toAlert(r)
+		a.queue(alert)
+
+		groups[key] = &group{
+			until: r.Time.Add(a.cfg.Window),
+		}
+		return
+	}
+
+	if g.alert.Count == 0 {
+		g.alert = a.toAlert(r)
+		return
+	}
+
+	g.alert.Count++
+	g.alert.Last = r.Time
+	g.alert.Attributes = attributes(r)
+}
+
+// expire sends the alerts for the windows that have elapsed. A message that
+// kept being logged gets a new window, otherwise it's forgotten.
+func (a *Alerter) expire(groups map[groupKey]*group, now time.Time) {
+	for key, g := range groups {
+		if now.Before(g.until) {
+			continue
+		}
+
+		if g.alert.Count == 0 {
+			delete(groups, key)
+			continue
+		}
+
+		a.queue(g.alert)
+
+		g.alert = Alert{}
+		g.until = now.Add(a.cfg.Window)
+	}
+}
+
+func (a *Alerter) queue(alert Alert) {
+	select {
+	case a.alerts <- alert:
+	default:
+		a.dropped.Add(1)
+	}
+}
+
+func (a *Alerter) toAlert(r logger.Record) Alert {
+	return Alert{
+		Service:    a.cfg.Service,
+		Level:      r.Level.String(),
+		Message:    r.Message,
+		File:       r.File,
+		Count:      1,
+		First:      r.Time,
+		Last:       r.Time,
+		Attributes: attributes(r),
+	}
+}
+
+func attributes(r logger.Record) map[string]string {
+	if len(r.Attributes) == 0 {
+		return nil
+	}
+
+	attrs := make(map[string]string, len(r.Attributes))
+	for k, v := range r.Attributes {
+		attrs[k] = fmt.Sprint(v)
+	}
+
+	return attrs
+}
+
+// =============================================================================
+
+// deliver sends the alerts to every notifier.
+func (a *Alerter) deliver() {
+	for alert := range a.alerts {
+		for _, n := range a.cfg.Notifiers {
+			if err := a.notify(n, alert); err != nil && a.cfg.Log != nil {
+				a.cfg.Log.Warn(a.ctx, "alert", "status", "delivery failed", "message", alert.Message, "notifier", fmt.Sprintf("%T", n), "msg", err)
+			}
+		}
+	}
+}
+
+// notify delivers the alert, retrying with an exponential backoff.
+func (a *Alerter) notify(n Notifier, alert Alert) error {
+	backoff := a.cfg.Backoff
+
+	var err error
+	for attempt := 0; ; attempt++ {
+		if err = n.Notify(a.ctx, alert); err == nil {
+			return nil
+		}
+
+		if attempt == a.cfg.Retries {
+			return fmt.Errorf("attempt %d: %w", attempt+1, err)
+		}
+
+		timer := time.NewTimer(backoff)
+		select {
+		case <-timer.C:
+		case <-a.ctx.Done():
+			timer.Stop()
+			return fmt.Errorf("attempt %d: %w", attempt+1, err)
+		}
+
+		backoff *= 2
+	}
+}
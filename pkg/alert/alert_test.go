@@ -0,0 +1,239 @@
+package alert_test
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/1core-dev/go-service/pkg/alert"
+	"github.com/1core-dev/go-service/pkg/logger"
+)
+
+func Test_Alert(t *testing.T) {
+	t.Run("group", group)
+	t.Run("retry", retry)
+	t.Run("slack", slack)
+	t.Run("nonblocking", nonblocking)
+	t.Run("nil", nilAlerter)
+}
+
+// =============================================================================
+
+// receiver records the payloads posted to it. The first fail requests are
+// answered with a server error.
+type receiver struct {
+	mu       sync.Mutex
+	fail     int
+	requests int
+	payloads [][]byte
+}
+
+func (rc *receiver) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	data, _ := io.ReadAll(r.Body)
+
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+
+	rc.requests++
+	if rc.requests <= rc.fail {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		return
+	}
+
+	rc.payloads = append(rc.payloads, data)
+}
+
+func (rc *receiver) alerts(t *testing.T) []alert.Alert {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+
+	alerts := make([]alert.Alert, len(rc.payloads))
+	for i, p := range rc.payloads {
+		if err := json.Unmarshal(p, &alerts[i]); err != nil {
+			t.Fatalf("Should be able to unmarshal the alert : %s.", err)
+		}
+	}
+
+	return alerts
+}
+
+func newLogger(a *alert.Alerter) *logger.Logger {
+	return logger.NewWithEvents(io.Discard, logger.LevelInfo, "TEST", nil, logger.Events{
+		Error: a.Send,
+	})
+}
+
+// =============================================================================
+
+func group(t *testing.T) {
+	rc := receiver{}
+	srv := httptest.NewServer(&rc)
+	defer srv.Close()
+
+	a := alert.New(alert.Config{
+		Service:   "TEST",
+		Notifiers: []alert.Notifier{alert.Webhook{URL: srv.URL}},
+		Window:    100 * time.Millisecond,
+	})
+
+	log := newLogger(a)
+	ctx := context.Background()
+
+	for i := range 5 {
+		log.Error(ctx, "database down", "attempt", i)
+	}
+	log.Error(ctx, "other failure")
+
+	time.Sleep(250 * time.Millisecond)
+
+	if err := a.Shutdown(ctx); err != nil {
+		t.Fatalf("Should be able to shutdown the alerter : %s.", err)
+	}
+
+	alerts := rc.alerts(t)
+	if len(alerts) != 3 {
+		t.Fatalf("Should get an alert for the first records and one for the burst : got %d, exp 3.", len(alerts))
+	}
+
+	counts := make(map[string]int)
+	for _, al := range alerts {
+		counts[al.Message] += al.Count
+
+		if al.Service != "TEST" || al.Level != "ERROR" || !strings.HasPrefix(al.File, "alert_test.go:") {
+			t.Errorf("Should get the service, level and file of the record : %+v.", al)
+		}
+	}
+
+	if counts["database down"] != 5 || counts["other failure"] != 1 {
+		t.Errorf("Should account for every record : got %v.", counts)
+	}
+
+	last := alerts[len(alerts)-1]
+	if last.Message != "database down" || last.Count != 4 || last.Attributes["attempt"] != "4" {
+		t.Errorf("Should group the burst into one alert : %+v.", last)
+	}
+}
+
+func retry(t *testing.T) {
+	rc := receiver{fail: 2}
+	srv := httptest.NewServer(&rc)
+	defer srv.Close()
+
+	a := alert.New(alert.Config{
+		Notifiers: []alert.Notifier{alert.Webhook{URL: srv.URL}},
+		Retries:   3,
+		Backoff:   10 * time.Millisecond,
+	})
+
+	log := newLogger(a)
+	ctx := context.Background()
+
+	log.Error(ctx, "database down")
+
+	if err := a.Shutdown(ctx); err != nil {
+		t.Fatalf("Should be able to shutdown the alerter : %s.", err)
+	}
+
+	if rc.requests != 3 {
+		t.Errorf("Should retry the failed deliveries : got %d requests, exp 3.", rc.requests)
+	}
+
+	if alerts := rc.alerts(t); len(alerts) != 1 {
+		t.Errorf("Should deliver the alert once : got %d.", len(alerts))
+	}
+}
+
+func slack(t *testing.T) {
+	rc := receiver{}
+	srv := httptest.NewServer(&rc)
+	defer srv.Close()
+
+	a := alert.New(alert.Config{
+		Service:   "TEST",
+		Notifiers: []alert.Notifier{alert.Slack{URL: srv.URL}},
+	})
+
+	log := newLogger(a)
+	ctx := context.Background()
+
+	log.Error(ctx, "database down", "host", "db-1")
+
+	if err := a.Shutdown(ctx); err != nil {
+		t.Fatalf("Should be able to shutdown the alerter : %s.", err)
+	}
+
+	if len(rc.payloads) != 1 {
+		t.Fatalf("Should post the alert : got %d.", len(rc.payloads))
+	}
+
+	var payload struct {
+		Text string `json:"text"`
+	}
+	if err := json.Unmarshal(rc.payloads[0], &payload); err != nil {
+		t.Fatalf("Should be able to unmarshal the payload : %s.", err)
+	}
+
+	for _, s := range []string{"*ERROR* database down [TEST]", "file: `alert_test.go:", "host: `db-1`"} {
+		if !strings.Contains(payload.Text, s) {
+			t.Errorf("Should get %q in the text : %s.", s, payload.Text)
+		}
+	}
+}
+
+func nonblocking(t *testing.T) {
+	release := make(chan struct{})
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+	}))
+	defer srv.Close()
+
+	a := alert.New(alert.Config{
+		Notifiers: []alert.Notifier{alert.Webhook{URL: srv.URL}},
+		QueueSize: 2,
+	})
+
+	log := newLogger(a)
+	ctx := context.Background()
+
+	start := time.Now()
+	for i := range 100 {
+		log.Error(ctx, "failure "+string(rune('a'+i%26)), "i", i)
+	}
+
+	if since := time.Since(start); since > time.Second {
+		t.Errorf("Should not block while the notifier is stuck : took %s.", since)
+	}
+
+	if a.Dropped() == 0 {
+		t.Errorf("Should drop the records that don't fit in the queue.")
+	}
+
+	close(release)
+
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	if err := a.Shutdown(ctx); err != nil {
+		t.Fatalf("Should be able to shutdown the alerter : %s.", err)
+	}
+}
+
+func nilAlerter(t *testing.T) {
+	defer func() {
+		if r := recover(); r != nil {
+			t.Errorf("Should ignore the records sent before the alerter is constructed : %v.", r)
+		}
+	}()
+
+	var a *alert.Alerter
+
+	log := newLogger(a)
+	log.Error(context.Background(), "failure")
+}
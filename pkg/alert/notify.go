@@ -0,0 +1,115 @@
+package alert
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/1core-dev/go-service/pkg/logger"
+)
+
+// defaultClient is used by the notifiers constructed without a client.
+var defaultClient = &http.Client{
+	Timeout: 10 * time.Second,
+}
+
+// Webhook delivers the alerts as JSON to a URL.
+type Webhook struct {
+	URL    string
+	Client *http.Client
+}
+
+// Notify implements the Notifier interface.
+func (wh Webhook) Notify(ctx context.Context, a Alert) error {
+	return post(ctx, wh.Client, wh.URL, a)
+}
+
+// Slack delivers the alerts to a Slack incoming webhook, or any service
+// accepting the same payload.
+type Slack struct {
+	URL    string
+	Client *http.Client
+}
+
+// Notify implements the Notifier interface.
+func (s Slack) Notify(ctx context.Context, a Alert) error {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "*%s* %s", a.Level, a.Message)
+	if a.Service != "" {
+		fmt.Fprintf(&b, " [%s]", a.Service)
+	}
+	if a.Count > 1 {
+		fmt.Fprintf(&b, " (%d times between %s and %s)", a.Count, a.First.Format(time.RFC3339), a.Last.Format(time.RFC3339))
+	}
+	fmt.Fprintf(&b, "\nfile: `%s`", a.File)
+
+	keys := make([]string, 0, len(a.Attributes))
+	for k := range a.Attributes {
+		keys = append(keys, k)
+	}
+	slices.Sort(keys)
+
+	for _, k := range keys {
+		fmt.Fprintf(&b, "\n%s: `%s`", k, a.Attributes[k])
+	}
+
+	payload := struct {
+		Text string `json:"text"`
+	}{
+		Text: b.String(),
+	}
+
+	return post(ctx, s.Client, s.URL, payload)
+}
+
+// Log delivers the alerts by logging them, for when there is nowhere else
+// to send them to.
+type Log struct {
+	Log *logger.Logger
+}
+
+// Notify implements the Notifier interface.
+func (l Log) Notify(ctx context.Context, a Alert) error {
+	l.Log.Warn(ctx, "******* SEND ALERT *******", "message", a.Message, "file", a.File, "count", a.Count)
+	return nil
+}
+
+// =============================================================================
+
+func post(ctx context.Context, client *http.Client, url string, payload any) error {
+	if client == nil {
+		client = defaultClient
+	}
+
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("marshal: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(data))
+	if err != nil {
+		return fmt.Errorf("request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("post: %w", err)
+	}
+	defer resp.Body.Close()
+
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 1<<16))
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("post: status %d", resp.StatusCode)
+	}
+
+	return nil
+}
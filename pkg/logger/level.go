@@ -0,0 +1,112 @@
+package logger
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+// levelControl manages the minimum level of a logger and reverts it after
+// a change that is only meant to be temporary.
+type levelControl struct {
+	mu       sync.Mutex
+	level    *slog.LevelVar
+	base     slog.Level
+	timer    *time.Timer
+	revertAt time.Time
+}
+
+func newLevelControl(level *slog.LevelVar) *levelControl {
+	return &levelControl{
+		level: level,
+	}
+}
+
+func (lc *levelControl) set(level slog.Level, ttl time.Duration) {
+	lc.mu.Lock()
+	defer lc.mu.Unlock()
+
+	// Keep the level from before the first temporary change so a series of
+	// temporary changes still reverts to it.
+	switch lc.timer {
+	case nil:
+		lc.base = lc.level.Level()
+	default:
+		lc.timer.Stop()
+	}
+	lc.timer = nil
+	lc.revertAt = time.Time{}
+
+	lc.level.Set(level)
+
+	if ttl <= 0 {
+		return
+	}
+
+	var timer *time.Timer
+	timer = time.AfterFunc(ttl, func() {
+		lc.mu.Lock()
+		defer lc.mu.Unlock()
+
+		// The level was changed again since this timer was started.
+		if lc.timer != timer {
+			return
+		}
+
+		lc.level.Set(lc.base)
+		lc.timer = nil
+		lc.revertAt = time.Time{}
+	})
+
+	lc.timer = timer
+	lc.revertAt = time.Now().Add(ttl)
+}
+
+func (lc *levelControl) get() (slog.Level, time.Time) {
+	lc.mu.Lock()
+	defer lc.mu.Unlock()
+
+	return lc.level.Level(), lc.revertAt
+}
+
+// =============================================================================
+
+// Level returns the minimum level of the records being logged and, when
+// the level was changed temporarily, the time it will be reverted at.
+func (log *Logger) Level() (Level, time.Time) {
+	if log.level == nil {
+		return LevelInfo, time.Time{}
+	}
+
+	level, revertAt := log.level.get()
+
+	return Level(level), revertAt
+}
+
+// SetLevel changes the minimum level of the records being logged. When ttl
+// is greater than zero, the level is reverted to the previous level once it
+// has elapsed. It has no effect on a logger constructed with NewWithHandler.
+func (log *Logger) SetLevel(level Level, ttl time.Duration) {
+	if log.level != nil {
+		log.level.set(slog.Level(level), ttl)
+	}
+}
+
+// =============================================================================
+
+type ctxKey int
+
+const levelKey ctxKey = 1
+
+// WithLevel returns a context that overrides the minimum level of the
+// records logged with it, like for tracing a single request at the debug
+// level.
+func WithLevel(ctx context.Context, level Level) context.Context {
+	return context.WithValue(ctx, levelKey, level)
+}
+
+func levelFromContext(ctx context.Context) (Level, bool) {
+	level, ok := ctx.Value(levelKey).(Level)
+	return level, ok
+}
@@ -0,0 +1,131 @@
+package logger_test
+
+import (
+	"io"
+	"testing"
+	"time"
+
+	"github.com/1core-dev/go-service/pkg/logger"
+)
+
+func Test_Level(t *testing.T) {
+	t.Run("set", levelSet)
+	t.Run("revert", levelRevert)
+	t.Run("cancel", levelCancel)
+	t.Run("base", levelBase)
+}
+
+// =============================================================================
+
+func newLevelLogger() *logger.Logger {
+	return logger.New(io.Discard, logger.LevelInfo, "TEST", nil)
+}
+
+// waitLevel waits for the logger to get to the level, giving up after a
+// second.
+func waitLevel(log *logger.Logger, level logger.Level) bool {
+	deadline := time.Now().Add(time.Second)
+
+	for time.Now().Before(deadline) {
+		if got, _ := log.Level(); got == level {
+			return true
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	return false
+}
+
+// =============================================================================
+
+func levelSet(t *testing.T) {
+	log := newLevelLogger()
+
+	log.SetLevel(logger.LevelWarn, 0)
+
+	level, revertAt := log.Level()
+	if level != logger.LevelWarn || !revertAt.IsZero() {
+		t.Errorf("Should change the level for good : got %s, %v.", level, revertAt)
+	}
+
+	// -------------------------------------------------------------------------
+
+	log = logger.NewWithHandler(nil)
+	log.SetLevel(logger.LevelDebug, time.Minute)
+
+	if level, _ := log.Level(); level != logger.LevelInfo {
+		t.Errorf("Should not change the level of a logger with a handler : %s.", level)
+	}
+}
+
+func levelRevert(t *testing.T) {
+	log := newLevelLogger()
+
+	start := time.Now()
+	log.SetLevel(logger.LevelDebug, 50*time.Millisecond)
+
+	level, revertAt := log.Level()
+	if level != logger.LevelDebug {
+		t.Fatalf("Should change the level : got %s.", level)
+	}
+
+	if revertAt.Before(start.Add(50*time.Millisecond)) || revertAt.After(time.Now().Add(50*time.Millisecond)) {
+		t.Errorf("Should report when the level is reverted : %v.", revertAt)
+	}
+
+	if !waitLevel(log, logger.LevelInfo) {
+		t.Fatalf("Should revert the level once the ttl has elapsed.")
+	}
+
+	if _, revertAt := log.Level(); !revertAt.IsZero() {
+		t.Errorf("Should clear the revert time once reverted : %v.", revertAt)
+	}
+}
+
+func levelCancel(t *testing.T) {
+	log := newLevelLogger()
+
+	log.SetLevel(logger.LevelDebug, 20*time.Millisecond)
+	log.SetLevel(logger.LevelWarn, time.Hour)
+
+	// Give the first timer the time to fire.
+	time.Sleep(100 * time.Millisecond)
+
+	if level, _ := log.Level(); level != logger.LevelWarn {
+		t.Errorf("Should cancel the revert of the first change : got %s.", level)
+	}
+
+	// -------------------------------------------------------------------------
+
+	log.SetLevel(logger.LevelError, 0)
+
+	time.Sleep(50 * time.Millisecond)
+
+	level, revertAt := log.Level()
+	if level != logger.LevelError || !revertAt.IsZero() {
+		t.Errorf("Should cancel the revert with a change for good : got %s, %v.", level, revertAt)
+	}
+}
+
+func levelBase(t *testing.T) {
+	log := newLevelLogger()
+
+	log.SetLevel(logger.LevelDebug, time.Hour)
+	log.SetLevel(logger.LevelWarn, 20*time.Millisecond)
+
+	if !waitLevel(log, logger.LevelInfo) {
+		level, _ := log.Level()
+		t.Fatalf("Should revert to the level from before the first change : got %s.", level)
+	}
+
+	// -------------------------------------------------------------------------
+
+	// Once reverted, the next temporary change starts from the current level.
+	log.SetLevel(logger.LevelError, 0)
+	log.SetLevel(logger.LevelDebug, 20*time.Millisecond)
+
+	if !waitLevel(log, logger.LevelError) {
+		level, _ := log.Level()
+		t.Errorf("Should revert to the level set for good : got %s.", level)
+	}
+}
@@ -2,11 +2,9 @@ package logger
 
 import (
 	"context"
-	"fmt"
 	"io"
 	"log"
 	"log/slog"
-	"path/filepath"
 	"runtime"
 	"time"
 )
@@ -15,20 +13,56 @@ import (
 // the specific context.
 type TraceIDFunc func(ctx context.Context) string
 
+// Config represents the configuration of a logger. When a LevelVar is
+// provided it's used in place of MinLevel, so the level can be shared with
+// other handlers. When Sinks are provided the records are written to them
+// instead of as JSON to the Writer. When no Redactor is provided the
+// default redactor is used.
+type Config struct {
+	Writer      io.Writer
+	Sinks       []Sink
+	MinLevel    Level
+	LevelVar    *slog.LevelVar
+	ServiceName string
+	TraceIDFunc TraceIDFunc
+	Events      Events
+	Redactor    Redactor
+	Sampling    Sampling
+}
+
 // Logger represents a logger for logging information.
 type Logger struct {
 	handler     slog.Handler
 	traceIDFunc TraceIDFunc
+	sampler     *sampler
+	level       *levelControl
 }
 
 // New constructs a new logger for application use.
 func New(w io.Writer, minLevel Level, serviceName string, traceIDFunc TraceIDFunc) *Logger {
-	return new(w, minLevel, serviceName, traceIDFunc, Events{})
+	return new(Config{
+		Writer:      w,
+		MinLevel:    minLevel,
+		ServiceName: serviceName,
+		TraceIDFunc: traceIDFunc,
+	})
 }
 
 // NewWithEvents constructs a new logger for application use with events.
 func NewWithEvents(w io.Writer, minLevel Level, serviceName string, traceIDFunc TraceIDFunc, events Events) *Logger {
-	return new(w, minLevel, serviceName, traceIDFunc, events)
+	return new(Config{
+		Writer:      w,
+		MinLevel:    minLevel,
+		ServiceName: serviceName,
+		TraceIDFunc: traceIDFunc,
+		Events:      events,
+	})
+}
+
+// NewWithConfig constructs a new logger for application use with the
+// specified configuration.
+func NewWithConfig(cfg Config) *Logger {
+	return new(cfg)
 }
 
 // NewWithHandler returns a new logger for application use with the underlying
@@ -85,7 +119,12 @@ func (log *Logger) Errorc(ctx context.Context, caller int, msg string, args ...a
 func (log *Logger) write(ctx context.Context, level Level, caller int, msg string, args ...any) {
 	slogLevel := slog.Level(level)
 
-	if !log.handler.Enabled(ctx, slogLevel) {
+	// A level set on the context overrides the level of the handler.
+	if minLevel, ok := levelFromContext(ctx); ok {
+		if level < minLevel {
+			return
+		}
+	} else if !log.handler.Enabled(ctx, slogLevel) {
 		return
 	}
 
@@ -102,34 +141,45 @@ func (log *Logger) write(ctx context.Context, level Level, caller int, msg strin
 	log.handler.Handle(ctx, record)
 }
 
-func new(w io.Writer, minLevel Level, serviceName string, traceIDFunc TraceIDFunc, events Events) *Logger {
-	// Convert the file name to just the name.ext when this keu/value will
-	// be logged.
-	f := func(groups []string, attr slog.Attr) slog.Attr {
-		if attr.Key == slog.SourceKey {
-			if source, ok := attr.Value.Any().(*slog.Source); ok {
-				v := fmt.Sprintf("%s:%d", filepath.Base(source.File), source.Line)
-				return slog.Attr{Key: "file", Value: slog.StringValue(v)}
-			}
-		}
+func new(cfg Config) *Logger {
+	levelVar := cfg.LevelVar
+	if levelVar == nil {
+		levelVar = &slog.LevelVar{}
+		levelVar.Set(slog.Level(cfg.MinLevel))
+	}
 
-		return attr
+	// Without sinks, the records are written as JSON to the writer.
+	sinks := cfg.Sinks
+	if len(sinks) == 0 {
+		sinks = []Sink{{Writer: cfg.Writer, Format: FormatJSON}}
 	}
 
-	// Construct the slog JSON handler for use.
-	handler := slog.Handler(
-		slog.NewJSONHandler(w, &slog.HandlerOptions{AddSource: true, Level: slog.Level(minLevel), ReplaceAttr: f}),
-	)
+	// Construct the handler writing to the sinks for use.
+	handler := slog.Handler(newMultiHandler(sinks, levelVar))
 
-	// If events are to be processed, wrap the JSON handler around the custom
+	// If events are to be processed, wrap the sinks handler around the custom
 	// log handler.
-	if events.Debug != nil || events.Info != nil || events.Warn != nil || events.Error != nil {
+	if events := cfg.Events; events.Debug != nil || events.Info != nil || events.Warn != nil || events.Error != nil {
 		handler = newLogHandler(handler, events)
 	}
 
+	// Sample the records before the events so suppressed records don't
+	// trigger them.
+	sampler := newSampler(cfg.Sampling)
+	handler = newSampleHandler(handler, sampler)
+
+	// Redact the records before they reach the events or get written so
+	// sensitive values never leave the process.
+	redactor := cfg.Redactor
+	if redactor == nil {
+		// The default configuration doesn't hash so it can't fail.
+		redactor, _ = NewRedactor(DefaultRedactorConfig())
+	}
+	handler = newRedactHandler(handler, redactor)
+
 	// Attributes to add to every log.
 	attrs := []slog.Attr{
-		{Key: "service", Value: slog.StringValue(serviceName)},
+		{Key: "service", Value: slog.StringValue(cfg.ServiceName)},
 	}
 
 	// Add those attributes and capture the final handler.
@@ -137,6 +187,8 @@ func new(w io.Writer, minLevel Level, serviceName string, traceIDFunc TraceIDFun
 
 	return &Logger{
 		handler:     handler,
-		traceIDFunc: traceIDFunc,
+		traceIDFunc: cfg.TraceIDFunc,
+		sampler:     sampler,
+		level:       newLevelControl(levelVar),
 	}
 }
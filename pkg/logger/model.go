@@ -2,7 +2,10 @@ package logger
 
 import (
 	"context"
+	"fmt"
 	"log/slog"
+	"path/filepath"
+	"runtime"
 	"time"
 )
 
@@ -17,11 +20,28 @@ const (
 	LevelError = Level(slog.LevelError)
 )
 
-// Record represents the data that is being logged.
+// ParseLevel parses the name of a level, like info or DEBUG.
+func ParseLevel(s string) (Level, error) {
+	var l slog.Level
+	if err := l.UnmarshalText([]byte(s)); err != nil {
+		return 0, err
+	}
+
+	return Level(l), nil
+}
+
+// String returns the name of the level.
+func (l Level) String() string {
+	return slog.Level(l).String()
+}
+
+// Record represents the data that is being logged. File is the name.ext
+// and line of the code that logged it.
 type Record struct {
 	Time       time.Time
 	Message    string
 	Level      Level
+	File       string
 	Attributes map[string]any
 }
 
@@ -34,10 +54,17 @@ func toRecord(r slog.Record) Record {
 	}
 	r.Attrs(f)
 
+	var file string
+	if r.PC != 0 {
+		frame, _ := runtime.CallersFrames([]uintptr{r.PC}).Next()
+		file = fmt.Sprintf("%s:%d", filepath.Base(frame.File), frame.Line)
+	}
+
 	return Record{
 		Time:       r.Time,
 		Message:    r.Message,
 		Level:      Level(r.Level),
+		File:       file,
 		Attributes: attrs,
 	}
 }
@@ -0,0 +1,264 @@
+package logger
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/mail"
+	"reflect"
+	"regexp"
+	"strings"
+)
+
+// Redactor hides sensitive information from the logged attributes. The
+// message of a record is passed as an attribute with the slog.MessageKey
+// key.
+type Redactor interface {
+	Redact(attr slog.Attr) slog.Attr
+}
+
+// ErrMissingHashKey is returned when redacted values are to be hashed
+// without a key, which would let anyone confirm a guess of the value by
+// hashing it.
+var ErrMissingHashKey = errors.New("redactor: hash requires a hash key")
+
+// Set of patterns matching credentials that are redacted wherever they
+// appear in a string.
+var (
+	PatternJWT    = regexp.MustCompile(`eyJ[A-Za-z0-9_-]+\.eyJ[A-Za-z0-9_-]+\.[A-Za-z0-9_-]*`)
+	PatternBearer = regexp.MustCompile(`(?i)bearer\s+[A-Za-z0-9\-._~+/]+=*`)
+)
+
+// RedactorConfig configures the built-in redactor.
+//
+// The value of an attribute is redacted when its key contains one of the
+// Keys, ignoring case, or when it holds a value of one of the Types. Parts
+// of string values matching one of the Patterns are redacted wherever they
+// appear. When Hash is set, redacted values are replaced by a keyed hash of
+// the value instead of being dropped so the same value can be correlated
+// across logs without being revealed.
+type RedactorConfig struct {
+	Keys     []string
+	Types    []reflect.Type
+	Patterns []*regexp.Regexp
+	Hash     bool
+	HashKey  []byte
+}
+
+// DefaultRedactorConfig returns the configuration used when a logger is
+// constructed without a redactor.
+func DefaultRedactorConfig() RedactorConfig {
+	return RedactorConfig{
+		Keys: []string{"password", "token", "authorization", "email"},
+		Types: []reflect.Type{
+			reflect.TypeOf(mail.Address{}),
+		},
+		Patterns: []*regexp.Regexp{PatternJWT, PatternBearer},
+	}
+}
+
+// NewRedactor constructs the built-in redactor.
+func NewRedactor(cfg RedactorConfig) (Redactor, error) {
+	if cfg.Hash && len(cfg.HashKey) == 0 {
+		return nil, ErrMissingHashKey
+	}
+
+	keys := make([]string, len(cfg.Keys))
+	for i, k := range cfg.Keys {
+		keys[i] = strings.ToLower(k)
+	}
+
+	types := make(map[reflect.Type]bool, len(cfg.Types))
+	for _, t := range cfg.Types {
+		types[t] = true
+	}
+
+	return &redactor{
+		keys:     keys,
+		types:    types,
+		patterns: cfg.Patterns,
+		hash:     cfg.Hash,
+		hashKey:  cfg.HashKey,
+	}, nil
+}
+
+// =============================================================================
+
+type redactor struct {
+	keys     []string
+	types    map[reflect.Type]bool
+	patterns []*regexp.Regexp
+	hash     bool
+	hashKey  []byte
+}
+
+// Redact implements the Redactor interface.
+func (rd *redactor) Redact(attr slog.Attr) slog.Attr {
+	value := attr.Value.Resolve()
+
+	if value.Kind() == slog.KindGroup {
+		attrs := value.Group()
+
+		redacted := make([]slog.Attr, len(attrs))
+		for i, a := range attrs {
+			redacted[i] = rd.Redact(a)
+		}
+
+		return slog.Attr{Key: attr.Key, Value: slog.GroupValue(redacted...)}
+	}
+
+	if rd.sensitiveKey(attr.Key) || rd.sensitiveType(value) {
+		return slog.String(attr.Key, rd.replace(valueString(value)))
+	}
+
+	switch value.Kind() {
+	case slog.KindString:
+		return slog.String(attr.Key, rd.scrub(value.String()))
+
+	case slog.KindAny:
+		switch v := value.Any().(type) {
+		case error:
+			if s := v.Error(); rd.matches(s) {
+				return slog.String(attr.Key, rd.scrub(s))
+			}
+		case fmt.Stringer:
+			if s := v.String(); rd.matches(s) {
+				return slog.String(attr.Key, rd.scrub(s))
+			}
+		}
+	}
+
+	return slog.Attr{Key: attr.Key, Value: value}
+}
+
+func (rd *redactor) sensitiveKey(key string) bool {
+	if key == slog.MessageKey {
+		return false
+	}
+
+	key = strings.ToLower(key)
+	for _, k := range rd.keys {
+		if strings.Contains(key, k) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (rd *redactor) sensitiveType(value slog.Value) bool {
+	if value.Kind() != slog.KindAny || len(rd.types) == 0 {
+		return false
+	}
+
+	t := reflect.TypeOf(value.Any())
+	if t == nil {
+		return false
+	}
+
+	if t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	return rd.types[t]
+}
+
+func (rd *redactor) matches(s string) bool {
+	for _, p := range rd.patterns {
+		if p.MatchString(s) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// scrub redacts the parts of the string matching the patterns.
+func (rd *redactor) scrub(s string) string {
+	for _, p := range rd.patterns {
+		s = p.ReplaceAllStringFunc(s, rd.replace)
+	}
+
+	return s
+}
+
+// replace returns what is logged in place of a sensitive value.
+func (rd *redactor) replace(s string) string {
+	if !rd.hash {
+		return "[REDACTED]"
+	}
+
+	h := hmac.New(sha256.New, rd.hashKey)
+	h.Write([]byte(s))
+
+	return "[REDACTED:" + hex.EncodeToString(h.Sum(nil))[:16] + "]"
+}
+
+func valueString(value slog.Value) string {
+	if value.Kind() == slog.KindAny {
+		if v, ok := value.Any().(*mail.Address); ok && v != nil {
+			return v.Address
+		}
+		if v, ok := value.Any().(mail.Address); ok {
+			return v.Address
+		}
+	}
+
+	return value.String()
+}
+
+// =============================================================================
+
+// redactHandler redacts the message and attributes of the records before
+// they reach the wrapped handler.
+type redactHandler struct {
+	handler  slog.Handler
+	redactor Redactor
+}
+
+func newRedactHandler(handler slog.Handler, redactor Redactor) *redactHandler {
+	return &redactHandler{
+		handler:  handler,
+		redactor: redactor,
+	}
+}
+
+// Enabled reports whether the handler handles records at the given level.
+func (h *redactHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.handler.Enabled(ctx, level)
+}
+
+// WithAttrs returns a new handler whose attributes consists of h's
+// attributes followed by the redacted attrs.
+func (h *redactHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	redacted := make([]slog.Attr, len(attrs))
+	for i, a := range attrs {
+		redacted[i] = h.redactor.Redact(a)
+	}
+
+	return &redactHandler{handler: h.handler.WithAttrs(redacted), redactor: h.redactor}
+}
+
+// WithGroup returns a new handler with the given group appended to the
+// receiver's existing group.
+func (h *redactHandler) WithGroup(name string) slog.Handler {
+	return &redactHandler{handler: h.handler.WithGroup(name), redactor: h.redactor}
+}
+
+// Handle redacts the record and passes it to the wrapped handler.
+func (h *redactHandler) Handle(ctx context.Context, r slog.Record) error {
+	msg := h.redactor.Redact(slog.String(slog.MessageKey, r.Message)).Value.String()
+
+	nr := slog.NewRecord(r.Time, r.Level, msg, r.PC)
+
+	r.Attrs(func(a slog.Attr) bool {
+		nr.AddAttrs(h.redactor.Redact(a))
+		return true
+	})
+
+	return h.handler.Handle(ctx, nr)
+}
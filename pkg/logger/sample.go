@@ -0,0 +1,206 @@
+package logger
+
+import (
+	"context"
+	"log/slog"
+	"slices"
+	"sync"
+	"time"
+)
+
+// Sampling represents the configuration for sampling and rate limiting the
+// records being logged. Records are counted per level, message, call site
+// and error within each Interval, so distinct errors logged with the same
+// message are never suppressed together.
+//
+// Every record at LevelWarn and above is logged, unless Burst is set, in
+// which case identical records beyond the first Burst in an interval are
+// suppressed. Debug and Info records are logged in full for the First
+// records of a message in an interval and then only every Thereafter
+// record. A zero Interval disables sampling.
+//
+// When a record is suppressed, a summary with the number of records that
+// were suppressed is logged once the interval has elapsed. Summaries are
+// written with the next record that is logged, so they can be delayed when
+// nothing is being logged. They are logged at the level of the suppressed
+// records, but never above LevelWarn so the error events aren't raised
+// again for records that were already reported.
+type Sampling struct {
+	Interval   time.Duration
+	First      int
+	Thereafter int
+	Burst      int
+}
+
+// SetSampling changes the sampling of the records being logged. It has no
+// effect on a logger constructed with NewWithHandler.
+func (log *Logger) SetSampling(cfg Sampling) {
+	if log.sampler != nil {
+		log.sampler.set(cfg)
+	}
+}
+
+// =============================================================================
+
+// sampleDetailKeys are the attributes holding the error of a record. The
+// error is part of the sample key since a single message, like the one used
+// by the errors middleware, is commonly logged for unrelated errors.
+var sampleDetailKeys = []string{"msg", "error", "err", "ERROR"}
+
+type sampleKey struct {
+	level   slog.Level
+	message string
+	pc      uintptr
+	detail  string
+}
+
+// newSampleKey identifies the record for sampling.
+func newSampleKey(r slog.Record) sampleKey {
+	key := sampleKey{
+		level:   r.Level,
+		message: r.Message,
+		pc:      r.PC,
+	}
+
+	r.Attrs(func(attr slog.Attr) bool {
+		if slices.Contains(sampleDetailKeys, attr.Key) {
+			key.detail = attr.Value.String()
+			return false
+		}
+		return true
+	})
+
+	return key
+}
+
+// sampler holds the counters shared by the handlers derived from the same
+// sampling handler.
+type sampler struct {
+	mu         sync.Mutex
+	cfg        Sampling
+	start      time.Time
+	counts     map[sampleKey]int
+	suppressed map[sampleKey]int
+}
+
+func newSampler(cfg Sampling) *sampler {
+	return &sampler{
+		cfg:        cfg,
+		counts:     make(map[sampleKey]int),
+		suppressed: make(map[sampleKey]int),
+	}
+}
+
+func (s *sampler) set(cfg Sampling) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.cfg = cfg
+	s.start = time.Time{}
+	clear(s.counts)
+}
+
+// sample reports if the record should be logged and returns the summaries
+// of the previous interval when it has elapsed.
+func (s *sampler) sample(now time.Time, key sampleKey) (bool, map[sampleKey]int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var summaries map[sampleKey]int
+
+	if now.Sub(s.start) >= s.cfg.Interval {
+		s.start = now
+		clear(s.counts)
+
+		if len(s.suppressed) > 0 {
+			summaries = s.suppressed
+			s.suppressed = make(map[sampleKey]int)
+		}
+	}
+
+	if s.cfg.Interval <= 0 {
+		return true, summaries
+	}
+
+	s.counts[key]++
+	n := s.counts[key]
+
+	var keep bool
+	switch {
+	case key.level >= slog.LevelWarn:
+		keep = s.cfg.Burst <= 0 || n <= s.cfg.Burst
+
+	case n <= s.cfg.First:
+		keep = true
+
+	case s.cfg.Thereafter > 0:
+		keep = (n-s.cfg.First)%s.cfg.Thereafter == 0
+	}
+
+	if !keep {
+		s.suppressed[key]++
+	}
+
+	return keep, summaries
+}
+
+// =============================================================================
+
+// sampleHandler provides a wrapper around the slog handler to drop the
+// records that aren't sampled.
+type sampleHandler struct {
+	handler slog.Handler
+	sampler *sampler
+}
+
+func newSampleHandler(handler slog.Handler, sampler *sampler) *sampleHandler {
+	return &sampleHandler{
+		handler: handler,
+		sampler: sampler,
+	}
+}
+
+// Enabled reports whether the handler handles records at the given level.
+func (h *sampleHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.handler.Enabled(ctx, level)
+}
+
+// WithAttrs returns a new handler whose attributes consists of h's
+// attributes followed by attrs.
+func (h *sampleHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &sampleHandler{handler: h.handler.WithAttrs(attrs), sampler: h.sampler}
+}
+
+// WithGroup returns a new handler with the given group appended to the
+// receiver's existing group.
+func (h *sampleHandler) WithGroup(name string) slog.Handler {
+	return &sampleHandler{handler: h.handler.WithGroup(name), sampler: h.sampler}
+}
+
+// Handle logs the summaries of the previous interval if it has elapsed and
+// passes the record to the wrapped handler when it's sampled.
+func (h *sampleHandler) Handle(ctx context.Context, r slog.Record) error {
+	keep, summaries := h.sampler.sample(time.Now(), newSampleKey(r))
+
+	for key, n := range summaries {
+		summary := slog.NewRecord(time.Now(), min(key.level, slog.LevelWarn), "suppressed messages", key.pc)
+		summary.AddAttrs(
+			slog.String("suppressed_msg", key.message),
+			slog.String("suppressed_level", key.level.String()),
+			slog.Int("suppressed", n),
+		)
+		if key.detail != "" {
+			summary.AddAttrs(slog.String("suppressed_detail", key.detail))
+		}
+
+		if err := h.handler.Handle(ctx, summary); err != nil {
+			return err
+		}
+	}
+
+	if !keep {
+		return nil
+	}
+
+	return h.handler.Handle(ctx, r)
+}
@@ -0,0 +1,318 @@
+package logger
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+)
+
+func Test_Sample(t *testing.T) {
+	t.Run("first", sampleFirst)
+	t.Run("burst", sampleBurst)
+	t.Run("keys", sampleKeys)
+	t.Run("interval", sampleInterval)
+	t.Run("summaries", sampleSummaries)
+	t.Run("events", sampleEvents)
+}
+
+// =============================================================================
+
+// kept returns which of the n records with the key were sampled, starting
+// at the specified time with one millisecond between records.
+func kept(s *sampler, now time.Time, key sampleKey, n int) []bool {
+	keep := make([]bool, n)
+	for i := range n {
+		keep[i], _ = s.sample(now.Add(time.Duration(i)*time.Millisecond), key)
+	}
+
+	return keep
+}
+
+func equalKept(t *testing.T, name string, got []bool, exp []bool) {
+	t.Helper()
+
+	for i := range exp {
+		if got[i] != exp[i] {
+			t.Errorf("%s: Should get the expected sampling : got %v, exp %v.", name, got, exp)
+			return
+		}
+	}
+}
+
+// =============================================================================
+
+func sampleFirst(t *testing.T) {
+	now := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	key := sampleKey{level: slog.LevelInfo, message: "request"}
+
+	tests := []struct {
+		name string
+		cfg  Sampling
+		exp  []bool
+	}{
+		{
+			name: "disabled",
+			cfg:  Sampling{First: 1},
+			exp:  []bool{true, true, true, true},
+		},
+		{
+			name: "thereafter",
+			cfg:  Sampling{Interval: time.Second, First: 2, Thereafter: 3},
+			exp:  []bool{true, true, false, false, true, false, false, true},
+		},
+		{
+			name: "firstOnly",
+			cfg:  Sampling{Interval: time.Second, First: 2},
+			exp:  []bool{true, true, false, false, false},
+		},
+		{
+			name: "noFirst",
+			cfg:  Sampling{Interval: time.Second, Thereafter: 2},
+			exp:  []bool{false, true, false, true},
+		},
+	}
+
+	for _, tt := range tests {
+		s := newSampler(tt.cfg)
+		equalKept(t, tt.name, kept(s, now, key, len(tt.exp)), tt.exp)
+	}
+}
+
+func sampleBurst(t *testing.T) {
+	now := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	tests := []struct {
+		name  string
+		cfg   Sampling
+		level slog.Level
+		exp   []bool
+	}{
+		{
+			name:  "unlimited",
+			cfg:   Sampling{Interval: time.Second, First: 1},
+			level: slog.LevelWarn,
+			exp:   []bool{true, true, true, true},
+		},
+		{
+			name:  "warn",
+			cfg:   Sampling{Interval: time.Second, First: 1, Burst: 2},
+			level: slog.LevelWarn,
+			exp:   []bool{true, true, false, false},
+		},
+		{
+			name:  "error",
+			cfg:   Sampling{Interval: time.Second, First: 1, Thereafter: 1, Burst: 3},
+			level: slog.LevelError,
+			exp:   []bool{true, true, true, false, false},
+		},
+	}
+
+	for _, tt := range tests {
+		s := newSampler(tt.cfg)
+		key := sampleKey{level: tt.level, message: "message"}
+
+		equalKept(t, tt.name, kept(s, now, key, len(tt.exp)), tt.exp)
+	}
+}
+
+func sampleKeys(t *testing.T) {
+	now := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	newRecord := func(pc uintptr, args ...any) slog.Record {
+		r := slog.NewRecord(now, slog.LevelError, "message", pc)
+		r.Add(args...)
+		return r
+	}
+
+	base := newSampleKey(newRecord(1, "status", 500, "msg", errors.New("db down")))
+
+	if base.detail != "db down" || base.pc != 1 {
+		t.Fatalf("Should get the error and call site in the key : %+v.", base)
+	}
+
+	same := []slog.Record{
+		newRecord(1, "status", 500, "msg", errors.New("db down")),
+		newRecord(1, "msg", "db down", "trace_id", "x"),
+	}
+
+	for i, r := range same {
+		if newSampleKey(r) != base {
+			t.Errorf("Should get the same key for record %d : %+v.", i, newSampleKey(r))
+		}
+	}
+
+	distinct := []slog.Record{
+		newRecord(1, "msg", errors.New("timeout")),
+		newRecord(1, "error", "db down, retrying"),
+		newRecord(2, "msg", errors.New("db down")),
+		newRecord(1),
+	}
+
+	for i, r := range distinct {
+		if newSampleKey(r) == base {
+			t.Errorf("Should get a distinct key for record %d.", i)
+		}
+	}
+
+	// -------------------------------------------------------------------------
+
+	s := newSampler(Sampling{Interval: time.Second, First: 1, Burst: 1})
+
+	for i, r := range []slog.Record{same[0], distinct[0], distinct[1], distinct[2]} {
+		if keep, _ := s.sample(now, newSampleKey(r)); !keep {
+			t.Errorf("Should not suppress distinct errors with the same message : record %d.", i)
+		}
+	}
+
+	if keep, _ := s.sample(now, newSampleKey(same[1])); keep {
+		t.Errorf("Should suppress the same error beyond the burst.")
+	}
+}
+
+func sampleInterval(t *testing.T) {
+	now := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	s := newSampler(Sampling{Interval: time.Second, First: 1})
+	info := sampleKey{level: slog.LevelInfo, message: "request"}
+	debug := sampleKey{level: slog.LevelDebug, message: "request"}
+
+	equalKept(t, "first", kept(s, now, info, 4), []bool{true, false, false, false})
+	equalKept(t, "otherLevel", kept(s, now.Add(10*time.Millisecond), debug, 3), []bool{true, false, false})
+
+	keep, summaries := s.sample(now.Add(999*time.Millisecond), info)
+	if keep || summaries != nil {
+		t.Errorf("Should stay in the interval until it has elapsed : %v, %v.", keep, summaries)
+	}
+
+	keep, summaries = s.sample(now.Add(time.Second), info)
+	if !keep {
+		t.Errorf("Should reset the counts once the interval has elapsed.")
+	}
+
+	exp := map[sampleKey]int{info: 4, debug: 2}
+	if len(summaries) != len(exp) || summaries[info] != exp[info] || summaries[debug] != exp[debug] {
+		t.Errorf("Should get the suppressed counts of the previous interval : got %v, exp %v.", summaries, exp)
+	}
+
+	if _, summaries = s.sample(now.Add(3*time.Second), info); summaries != nil {
+		t.Errorf("Should not repeat the summaries : %v.", summaries)
+	}
+
+	// -------------------------------------------------------------------------
+
+	s.set(Sampling{Interval: time.Second, First: 2})
+	equalKept(t, "set", kept(s, now.Add(3*time.Second+time.Millisecond), info, 3), []bool{true, true, false})
+}
+
+func sampleSummaries(t *testing.T) {
+	var records []slog.Record
+	capture := captureHandler(func(r slog.Record) {
+		records = append(records, r)
+	})
+
+	s := newSampler(Sampling{Interval: time.Hour, First: 1, Burst: 1})
+	h := newSampleHandler(capture, s)
+	ctx := context.Background()
+
+	for range 3 {
+		r := slog.NewRecord(time.Now(), slog.LevelError, "message", 7)
+		r.AddAttrs(slog.String("msg", "db down"))
+
+		if err := h.Handle(ctx, r); err != nil {
+			t.Fatalf("Should be able to handle the record : %s.", err)
+		}
+	}
+
+	if len(records) != 1 {
+		t.Fatalf("Should only pass the sampled records : got %d, exp 1.", len(records))
+	}
+
+	// Pretend the interval has elapsed.
+	s.mu.Lock()
+	s.start = time.Time{}
+	s.mu.Unlock()
+
+	if err := h.Handle(ctx, slog.NewRecord(time.Now(), slog.LevelInfo, "other", 0)); err != nil {
+		t.Fatalf("Should be able to handle the record : %s.", err)
+	}
+
+	if len(records) != 3 {
+		t.Fatalf("Should pass the summary and the record : got %d, exp 3.", len(records))
+	}
+
+	summary := records[1]
+	if summary.Level != slog.LevelWarn || summary.Message != "suppressed messages" || summary.PC != 7 {
+		t.Errorf("Should get the summary of the suppressed records : %+v.", summary)
+	}
+
+	attrs := make(map[string]string)
+	summary.Attrs(func(attr slog.Attr) bool {
+		attrs[attr.Key] = attr.Value.String()
+		return true
+	})
+
+	if attrs["suppressed_msg"] != "message" || attrs["suppressed_level"] != "ERROR" || attrs["suppressed"] != "2" || attrs["suppressed_detail"] != "db down" {
+		t.Errorf("Should get the attributes of the summary : %v.", attrs)
+	}
+
+	if records[2].Message != "other" {
+		t.Errorf("Should pass the record after the summary : %q.", records[2].Message)
+	}
+}
+
+func sampleEvents(t *testing.T) {
+	var errs, warns []Record
+
+	log := NewWithConfig(Config{
+		Writer:      io.Discard,
+		MinLevel:    LevelInfo,
+		ServiceName: "TEST",
+		Events: Events{
+			Warn:  func(ctx context.Context, r Record) { warns = append(warns, r) },
+			Error: func(ctx context.Context, r Record) { errs = append(errs, r) },
+		},
+		Sampling: Sampling{Interval: time.Hour, First: 1, Thereafter: 1, Burst: 1},
+	})
+
+	ctx := context.Background()
+
+	for range 3 {
+		log.Error(ctx, "message", "msg", "db down")
+	}
+
+	for range 3 {
+		log.Info(ctx, "request")
+	}
+
+	// Pretend the interval has elapsed.
+	log.sampler.mu.Lock()
+	log.sampler.start = time.Time{}
+	log.sampler.mu.Unlock()
+
+	log.Info(ctx, "other")
+
+	if len(errs) != 1 {
+		t.Fatalf("Should only raise the error event of the sampled record : got %d, exp 1.", len(errs))
+	}
+
+	if len(warns) != 1 || warns[0].Message != "suppressed messages" || warns[0].Attributes["suppressed_level"] != "ERROR" {
+		t.Errorf("Should log the summary of the errors at the warn level : %+v.", warns)
+	}
+}
+
+// =============================================================================
+
+// captureHandler passes every record to the function.
+type captureHandler func(slog.Record)
+
+func (h captureHandler) Enabled(context.Context, slog.Level) bool { return true }
+func (h captureHandler) WithAttrs([]slog.Attr) slog.Handler       { return h }
+func (h captureHandler) WithGroup(string) slog.Handler            { return h }
+
+func (h captureHandler) Handle(_ context.Context, r slog.Record) error {
+	h(r)
+	return nil
+}
@@ -0,0 +1,149 @@
+package logger
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"path/filepath"
+)
+
+// Format represents the format records are written in.
+type Format string
+
+// Set of formats a sink can write records in.
+const (
+	FormatJSON Format = "json"
+	FormatText Format = "text"
+)
+
+// ParseFormat parses the name of a format, like json or text.
+func ParseFormat(s string) (Format, error) {
+	switch f := Format(s); f {
+	case FormatJSON, FormatText:
+		return f, nil
+	}
+
+	return "", fmt.Errorf("unknown format %q", s)
+}
+
+// Sink represents a destination records are written to. When no Level is
+// provided the sink follows the level of the logger, including changes made
+// at runtime and for a single request. Color only applies to the text
+// format.
+type Sink struct {
+	Writer io.Writer
+	Format Format
+	Level  slog.Leveler
+	Color  bool
+}
+
+// =============================================================================
+
+type sinkHandler struct {
+	handler slog.Handler
+	follows bool
+}
+
+// multiHandler writes the records to every sink whose level they meet.
+type multiHandler struct {
+	sinks []sinkHandler
+}
+
+func newMultiHandler(sinks []Sink, level slog.Leveler) *multiHandler {
+	// Convert the file name to just the name.ext when this key/value will
+	// be logged.
+	f := func(groups []string, attr slog.Attr) slog.Attr {
+		if attr.Key == slog.SourceKey {
+			if source, ok := attr.Value.Any().(*slog.Source); ok {
+				v := fmt.Sprintf("%s:%d", filepath.Base(source.File), source.Line)
+				return slog.Attr{Key: "file", Value: slog.StringValue(v)}
+			}
+		}
+
+		return attr
+	}
+
+	h := multiHandler{
+		sinks: make([]sinkHandler, len(sinks)),
+	}
+
+	for i, sink := range sinks {
+		sinkLevel := sink.Level
+		if sinkLevel == nil {
+			sinkLevel = level
+		}
+
+		var handler slog.Handler
+		switch sink.Format {
+		case FormatText:
+			handler = newTextHandler(sink.Writer, sinkLevel, sink.Color)
+		default:
+			handler = slog.NewJSONHandler(sink.Writer, &slog.HandlerOptions{AddSource: true, Level: sinkLevel, ReplaceAttr: f})
+		}
+
+		h.sinks[i] = sinkHandler{
+			handler: handler,
+			follows: sink.Level == nil,
+		}
+	}
+
+	return &h
+}
+
+// Enabled reports whether any sink handles records at the given level.
+func (h *multiHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	for _, s := range h.sinks {
+		if s.handler.Enabled(ctx, level) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// WithAttrs returns a new handler whose sinks have attrs added.
+func (h *multiHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return h.with(func(handler slog.Handler) slog.Handler {
+		return handler.WithAttrs(attrs)
+	})
+}
+
+// WithGroup returns a new handler whose sinks have the group added.
+func (h *multiHandler) WithGroup(name string) slog.Handler {
+	return h.with(func(handler slog.Handler) slog.Handler {
+		return handler.WithGroup(name)
+	})
+}
+
+func (h *multiHandler) with(f func(slog.Handler) slog.Handler) *multiHandler {
+	h2 := multiHandler{
+		sinks: make([]sinkHandler, len(h.sinks)),
+	}
+
+	for i, s := range h.sinks {
+		h2.sinks[i] = sinkHandler{handler: f(s.handler), follows: s.follows}
+	}
+
+	return &h2
+}
+
+// Handle writes the record to the sinks. A level set on the context
+// overrides the level of the sinks following the level of the logger.
+func (h *multiHandler) Handle(ctx context.Context, r slog.Record) error {
+	_, override := levelFromContext(ctx)
+
+	var errs []error
+	for _, s := range h.sinks {
+		if !(override && s.follows) && !s.handler.Enabled(ctx, r.Level) {
+			continue
+		}
+
+		if err := s.handler.Handle(ctx, r.Clone()); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return errors.Join(errs...)
+}
@@ -0,0 +1,145 @@
+package logger_test
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/1core-dev/go-service/pkg/logger"
+)
+
+func Test_Sink(t *testing.T) {
+	t.Run("format", sinkFormat)
+	t.Run("levels", sinkLevels)
+	t.Run("follows", sinkFollows)
+	t.Run("context", sinkContext)
+	t.Run("attrs", sinkAttrs)
+}
+
+// =============================================================================
+
+// newSinkLogger returns a logger at the info level writing to a sink with
+// its own error level and to a sink following the level of the logger.
+func newSinkLogger() (*logger.Logger, *bytes.Buffer, *bytes.Buffer) {
+	var errs, all bytes.Buffer
+
+	log := logger.NewWithConfig(logger.Config{
+		MinLevel:    logger.LevelInfo,
+		ServiceName: "TEST",
+		Sinks: []logger.Sink{
+			{Writer: &errs, Format: logger.FormatJSON, Level: slog.LevelError},
+			{Writer: &all, Format: logger.FormatJSON},
+		},
+	})
+
+	return log, &errs, &all
+}
+
+// messages returns the messages written to the buffer as JSON lines.
+func messages(buf *bytes.Buffer) []string {
+	var msgs []string
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		if _, after, found := strings.Cut(line, `"msg":"`); found {
+			msg, _, _ := strings.Cut(after, `"`)
+			msgs = append(msgs, msg)
+		}
+	}
+
+	return msgs
+}
+
+func equalMessages(t *testing.T, name string, got []string, exp []string) {
+	t.Helper()
+
+	if strings.Join(got, ",") != strings.Join(exp, ",") {
+		t.Errorf("%s: Should get the expected messages : got %v, exp %v.", name, got, exp)
+	}
+}
+
+// =============================================================================
+
+func sinkFormat(t *testing.T) {
+	tests := []struct {
+		name string
+		exp  logger.Format
+		ok   bool
+	}{
+		{name: "json", exp: logger.FormatJSON, ok: true},
+		{name: "text", exp: logger.FormatText, ok: true},
+		{name: "JSON"},
+		{name: "xml"},
+		{name: ""},
+	}
+
+	for _, tt := range tests {
+		got, err := logger.ParseFormat(tt.name)
+
+		if (err == nil) != tt.ok || got != tt.exp {
+			t.Errorf("%q: Should get the expected format : got %q, %v.", tt.name, got, err)
+		}
+	}
+}
+
+func sinkLevels(t *testing.T) {
+	log, errs, all := newSinkLogger()
+	ctx := context.Background()
+
+	log.Debug(ctx, "debug")
+	log.Info(ctx, "info")
+	log.Warn(ctx, "warn")
+	log.Error(ctx, "error")
+
+	equalMessages(t, "errors", messages(errs), []string{"error"})
+	equalMessages(t, "all", messages(all), []string{"info", "warn", "error"})
+}
+
+func sinkFollows(t *testing.T) {
+	log, errs, all := newSinkLogger()
+	ctx := context.Background()
+
+	log.SetLevel(logger.LevelDebug, 0)
+	log.Debug(ctx, "debug")
+
+	log.SetLevel(logger.LevelError, 0)
+	log.Warn(ctx, "warn")
+	log.Error(ctx, "error")
+
+	equalMessages(t, "errors", messages(errs), []string{"error"})
+	equalMessages(t, "all", messages(all), []string{"debug", "error"})
+}
+
+func sinkContext(t *testing.T) {
+	log, errs, all := newSinkLogger()
+
+	ctx := logger.WithLevel(context.Background(), logger.LevelDebug)
+	log.Debug(ctx, "debug")
+	log.Error(ctx, "error")
+
+	// The override applies to the logger level, even above it.
+	log.SetLevel(logger.LevelError, 0)
+	log.Info(ctx, "info")
+
+	ctx = logger.WithLevel(context.Background(), logger.LevelError)
+	log.Warn(ctx, "warn")
+
+	equalMessages(t, "errors", messages(errs), []string{"error"})
+	equalMessages(t, "all", messages(all), []string{"debug", "error", "info"})
+}
+
+func sinkAttrs(t *testing.T) {
+	log, errs, all := newSinkLogger()
+
+	log.Error(context.Background(), "error", "status", 500)
+
+	for name, buf := range map[string]*bytes.Buffer{"errors": errs, "all": all} {
+		out := buf.String()
+
+		for _, s := range []string{`"service":"TEST"`, `"status":500`, `"file":"sink_test.go:`} {
+			if !strings.Contains(out, s) {
+				t.Errorf("%s: Should log %s : %s.", name, s, out)
+			}
+		}
+	}
+}
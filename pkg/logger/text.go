@@ -0,0 +1,235 @@
+package logger
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// traceIDLength is the number of characters of a trace ID shown by the text
+// handler, which is enough to tell requests apart when reading the logs.
+const traceIDLength = 8
+
+// Set of terminal escape sequences used to color the text output.
+const (
+	colorReset  = "\033[0m"
+	colorDim    = "\033[2m"
+	colorRed    = "\033[31m"
+	colorGreen  = "\033[32m"
+	colorYellow = "\033[33m"
+	colorBlue   = "\033[34m"
+	colorCyan   = "\033[36m"
+)
+
+// textHandler writes records as human readable lines for local development.
+// The attributes are sorted by key so lines are easy to compare, multi-line
+// values like stack traces are printed indented below the line and trace
+// IDs are shortened.
+type textHandler struct {
+	mu     *sync.Mutex
+	w      io.Writer
+	level  slog.Leveler
+	color  bool
+	attrs  []slog.Attr
+	prefix string
+}
+
+func newTextHandler(w io.Writer, level slog.Leveler, color bool) *textHandler {
+	return &textHandler{
+		mu:    &sync.Mutex{},
+		w:     w,
+		level: level,
+		color: color,
+	}
+}
+
+// Enabled reports whether the handler handles records at the given level.
+func (h *textHandler) Enabled(_ context.Context, level slog.Level) bool {
+	return level >= h.level.Level()
+}
+
+// WithAttrs returns a new handler whose attributes consists of h's
+// attributes followed by attrs.
+func (h *textHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	h2 := *h
+	h2.attrs = slices.Clip(h.attrs)
+	for _, a := range attrs {
+		h2.attrs = appendAttr(h2.attrs, h.prefix, a)
+	}
+
+	return &h2
+}
+
+// WithGroup returns a new handler with the given group appended to the
+// receiver's existing group.
+func (h *textHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+
+	h2 := *h
+	h2.prefix = h.prefix + name + "."
+
+	return &h2
+}
+
+// Handle formats the record as a line of text.
+func (h *textHandler) Handle(_ context.Context, r slog.Record) error {
+	attrs := slices.Clip(h.attrs)
+	r.Attrs(func(a slog.Attr) bool {
+		attrs = appendAttr(attrs, h.prefix, a)
+		return true
+	})
+
+	slices.SortStableFunc(attrs, func(a, b slog.Attr) int {
+		return strings.Compare(a.Key, b.Key)
+	})
+
+	var buf bytes.Buffer
+
+	buf.WriteString(h.paint(colorDim, r.Time.Format(time.TimeOnly+".000")))
+	buf.WriteByte(' ')
+	buf.WriteString(h.paint(levelColor(r.Level), fmt.Sprintf("%-5s", r.Level.String())))
+
+	if r.PC != 0 {
+		frame, _ := runtime.CallersFrames([]uintptr{r.PC}).Next()
+		buf.WriteByte(' ')
+		buf.WriteString(h.paint(colorDim, filepath.Base(frame.File)+":"+strconv.Itoa(frame.Line)))
+	}
+
+	var traceID string
+	var blocks []slog.Attr
+	var fields []slog.Attr
+
+	for _, a := range attrs {
+		switch {
+		case a.Key == "trace_id":
+			traceID = a.Value.String()
+		case strings.Contains(a.Value.String(), "\n"):
+			blocks = append(blocks, a)
+		default:
+			fields = append(fields, a)
+		}
+	}
+
+	if traceID != "" {
+		buf.WriteByte(' ')
+		buf.WriteString(h.paint(colorBlue, "["+shortTraceID(traceID)+"]"))
+	}
+
+	buf.WriteByte(' ')
+	buf.WriteString(r.Message)
+
+	for _, a := range fields {
+		buf.WriteByte(' ')
+		buf.WriteString(h.paint(colorCyan, a.Key+"="))
+		buf.WriteString(quote(a.Value.String()))
+	}
+
+	buf.WriteByte('\n')
+
+	for _, a := range blocks {
+		buf.WriteString("    ")
+		buf.WriteString(h.paint(colorCyan, a.Key+":"))
+		buf.WriteByte('\n')
+		writeBlock(&buf, a.Value.String())
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	_, err := h.w.Write(buf.Bytes())
+
+	return err
+}
+
+func (h *textHandler) paint(color string, s string) string {
+	if !h.color {
+		return s
+	}
+
+	return color + s + colorReset
+}
+
+// =============================================================================
+
+// appendAttr resolves the attribute and flattens groups into keys joined
+// with a dot.
+func appendAttr(attrs []slog.Attr, prefix string, a slog.Attr) []slog.Attr {
+	value := a.Value.Resolve()
+
+	if value.Kind() == slog.KindGroup {
+		if a.Key != "" {
+			prefix += a.Key + "."
+		}
+		for _, ga := range value.Group() {
+			attrs = appendAttr(attrs, prefix, ga)
+		}
+		return attrs
+	}
+
+	if a.Equal(slog.Attr{}) {
+		return attrs
+	}
+
+	return append(attrs, slog.Attr{Key: prefix + a.Key, Value: value})
+}
+
+// writeBlock writes the multi-line value indented below the line. The
+// stack traces from panics are wrapped as PANIC [...] TRACE [...], so the
+// value is split at the trace to show each frame on its own line.
+func writeBlock(buf *bytes.Buffer, s string) {
+	if before, after, found := strings.Cut(s, " TRACE ["); found {
+		writeIndented(buf, before)
+		s = strings.TrimSuffix(after, "]")
+	}
+
+	writeIndented(buf, s)
+}
+
+func writeIndented(buf *bytes.Buffer, s string) {
+	for line := range strings.SplitSeq(strings.TrimRight(s, "\n"), "\n") {
+		buf.WriteString("        ")
+		buf.WriteString(line)
+		buf.WriteByte('\n')
+	}
+}
+
+func shortTraceID(traceID string) string {
+	traceID = strings.ReplaceAll(traceID, "-", "")
+	if len(traceID) > traceIDLength {
+		return traceID[:traceIDLength]
+	}
+
+	return traceID
+}
+
+func quote(s string) string {
+	if s == "" || strings.ContainsAny(s, " \t\"=") {
+		return strconv.Quote(s)
+	}
+
+	return s
+}
+
+func levelColor(level slog.Level) string {
+	switch {
+	case level >= slog.LevelError:
+		return colorRed
+	case level >= slog.LevelWarn:
+		return colorYellow
+	case level >= slog.LevelInfo:
+		return colorGreen
+	default:
+		return colorDim
+	}
+}
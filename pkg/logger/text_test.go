@@ -0,0 +1,110 @@
+package logger_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log/slog"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/1core-dev/go-service/pkg/logger"
+)
+
+func Test_Text(t *testing.T) {
+	t.Run("line", textLine)
+	t.Run("block", textBlock)
+	t.Run("color", textColor)
+}
+
+// =============================================================================
+
+func newTextLogger(color bool) (*logger.Logger, *bytes.Buffer) {
+	var buf bytes.Buffer
+
+	log := logger.NewWithConfig(logger.Config{
+		MinLevel:    logger.LevelInfo,
+		ServiceName: "TEST",
+		TraceIDFunc: func(ctx context.Context) string { return "4bf92f35-77b3-4da6-a3ce-929d0e0e4736" },
+		Sinks:       []logger.Sink{{Writer: &buf, Format: logger.FormatText, Color: color}},
+	})
+
+	return log, &buf
+}
+
+// =============================================================================
+
+func textLine(t *testing.T) {
+	log, buf := newTextLogger(false)
+
+	log.Info(context.Background(), "request started", "path", "/v1/users", "agent", "go test", "empty", "", "user", map[string]int{"id": 1})
+
+	line := buf.String()
+
+	exp := regexp.MustCompile(`^\d{2}:\d{2}:\d{2}\.\d{3} INFO  text_test\.go:\d+ \[4bf92f35\] request started agent="go test" empty="" path=/v1/users service=TEST user=map\[id:1\]\n$`)
+	if !exp.MatchString(line) {
+		t.Errorf("Should get the expected line : %q.", line)
+	}
+
+	// -------------------------------------------------------------------------
+
+	buf.Reset()
+	log.Info(context.Background(), "grouped", "b", 2, slog.Group("req", "a", 1))
+
+	if out := buf.String(); !strings.Contains(out, " grouped b=2 req.a=1 service=TEST\n") {
+		t.Errorf("Should flatten and sort the attributes : %q.", out)
+	}
+}
+
+func textBlock(t *testing.T) {
+	log, buf := newTextLogger(false)
+
+	log.Error(context.Background(), "panic", "trace", "PANIC [boom] TRACE [goroutine 1:\nmain.main()\n\tmain.go:10]", "msg", errors.New("first\nsecond"))
+
+	lines := strings.Split(strings.TrimRight(buf.String(), "\n"), "\n")
+
+	exp := []string{
+		"    msg:",
+		"        first",
+		"        second",
+		"    trace:",
+		"        PANIC [boom]",
+		"        goroutine 1:",
+		"        main.main()",
+		"        \tmain.go:10",
+	}
+
+	if len(lines) != len(exp)+1 || !strings.HasSuffix(lines[0], " panic service=TEST") {
+		t.Fatalf("Should write the multi-line values below the line : %q.", lines)
+	}
+
+	for i, line := range lines[1:] {
+		if line != exp[i] {
+			t.Errorf("Should get the expected line %d : got %q, exp %q.", i, line, exp[i])
+		}
+	}
+}
+
+func textColor(t *testing.T) {
+	log, buf := newTextLogger(true)
+
+	log.Error(context.Background(), "failed", "status", 500)
+
+	out := buf.String()
+
+	for _, s := range []string{"\033[31mERROR\033[0m", "\033[34m[4bf92f35]\033[0m", "\033[36mstatus=\033[0m500"} {
+		if !strings.Contains(out, s) {
+			t.Errorf("Should color the output with %q : %q.", s, out)
+		}
+	}
+
+	// -------------------------------------------------------------------------
+
+	log, buf = newTextLogger(false)
+	log.Error(context.Background(), "failed")
+
+	if strings.Contains(buf.String(), "\033[") {
+		t.Errorf("Should not color the output : %q.", buf.String())
+	}
+}
@@ -0,0 +1,409 @@
+// Package patch provides support for applying JSON Merge Patch (RFC 7386)
+// and JSON Patch (RFC 6902) documents to JSON documents.
+package patch
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/1core-dev/go-service/pkg/validate"
+)
+
+// Set of content types used to select the patch format.
+const (
+	MergePatchContentType = "application/merge-patch+json"
+	JSONPatchContentType  = "application/json-patch+json"
+)
+
+// Set of error variables for applying patches.
+var (
+	ErrPathNotFound  = errors.New("path does not exist")
+	ErrInvalidIndex  = errors.New("invalid array index")
+	ErrNotContainer  = errors.New("path does not reference an object or array")
+	ErrTestFailed    = errors.New("test failed, value does not match")
+	ErrMissingValue  = errors.New("value is required")
+	ErrMissingFrom   = errors.New("from is required")
+	ErrUnknownOp     = errors.New("unknown operation")
+	ErrMoveIntoChild = errors.New("from cannot be a prefix of path")
+)
+
+// =============================================================================
+
+// MergePatch applies the RFC 7386 merge patch to the JSON document and
+// returns the patched document.
+func MergePatch(doc []byte, patch []byte) ([]byte, error) {
+	var target any
+	if err := json.Unmarshal(doc, &target); err != nil {
+		return nil, fmt.Errorf("unmarshal document: %w", err)
+	}
+
+	var p any
+	if err := json.Unmarshal(patch, &p); err != nil {
+		return nil, validate.NewFieldsError("patch", err)
+	}
+
+	return json.Marshal(mergePatch(target, p))
+}
+
+func mergePatch(target any, patch any) any {
+	p, ok := patch.(map[string]any)
+	if !ok {
+		return patch
+	}
+
+	t, ok := target.(map[string]any)
+	if !ok {
+		t = make(map[string]any)
+	}
+
+	for k, v := range p {
+		if v == nil {
+			delete(t, k)
+			continue
+		}
+		t[k] = mergePatch(t[k], v)
+	}
+
+	return t
+}
+
+// =============================================================================
+
+// Operation represents a single RFC 6902 patch operation.
+type Operation struct {
+	Op    string          `json:"op"`
+	Path  string          `json:"path"`
+	From  string          `json:"from"`
+	Value json.RawMessage `json:"value"`
+}
+
+// JSONPatch applies the RFC 6902 patch to the JSON document and returns the
+// patched document. The patch is applied atomically, the first operation
+// that fails is reported as a field error against its path.
+func JSONPatch(doc []byte, patch []byte) ([]byte, error) {
+	var target any
+	if err := json.Unmarshal(doc, &target); err != nil {
+		return nil, fmt.Errorf("unmarshal document: %w", err)
+	}
+
+	var ops []Operation
+	if err := json.Unmarshal(patch, &ops); err != nil {
+		return nil, validate.NewFieldsError("patch", err)
+	}
+
+	for i, op := range ops {
+		var err error
+		target, err = apply(target, op)
+		if err != nil {
+			field := op.Path
+			if field == "" {
+				field = fmt.Sprintf("patch[%d]", i)
+			}
+			return nil, validate.NewFieldsError(field, fmt.Errorf("operation %d %q: %w", i, op.Op, err))
+		}
+	}
+
+	return json.Marshal(target)
+}
+
+func apply(doc any, op Operation) (any, error) {
+	path, err := parsePointer(op.Path)
+	if err != nil {
+		return nil, err
+	}
+
+	switch op.Op {
+	case "add":
+		v, err := op.value()
+		if err != nil {
+			return nil, err
+		}
+		return add(doc, path, v)
+
+	case "remove":
+		doc, _, err := remove(doc, path)
+		return doc, err
+
+	case "replace":
+		v, err := op.value()
+		if err != nil {
+			return nil, err
+		}
+		doc, _, err := remove(doc, path)
+		if err != nil {
+			return nil, err
+		}
+		return add(doc, path, v)
+
+	case "move":
+		from, err := op.from()
+		if err != nil {
+			return nil, err
+		}
+		if len(from) < len(path) && slices.Equal(from, path[:len(from)]) {
+			return nil, ErrMoveIntoChild
+		}
+		doc, v, err := remove(doc, from)
+		if err != nil {
+			return nil, err
+		}
+		return add(doc, path, v)
+
+	case "copy":
+		from, err := op.from()
+		if err != nil {
+			return nil, err
+		}
+		v, err := get(doc, from)
+		if err != nil {
+			return nil, err
+		}
+		return add(doc, path, deepCopy(v))
+
+	case "test":
+		v, err := op.value()
+		if err != nil {
+			return nil, err
+		}
+		cur, err := get(doc, path)
+		if err != nil {
+			return nil, err
+		}
+		if !reflect.DeepEqual(cur, v) {
+			return nil, ErrTestFailed
+		}
+		return doc, nil
+	}
+
+	return nil, ErrUnknownOp
+}
+
+func (op Operation) value() (any, error) {
+	if op.Value == nil {
+		return nil, ErrMissingValue
+	}
+
+	var v any
+	if err := json.Unmarshal(op.Value, &v); err != nil {
+		return nil, err
+	}
+
+	return v, nil
+}
+
+func (op Operation) from() ([]string, error) {
+	if op.From == "" {
+		return nil, ErrMissingFrom
+	}
+
+	return parsePointer(op.From)
+}
+
+// =============================================================================
+
+// parsePointer splits an RFC 6901 JSON pointer into its reference tokens.
+func parsePointer(pointer string) ([]string, error) {
+	if pointer == "" {
+		return nil, nil
+	}
+
+	if !strings.HasPrefix(pointer, "/") {
+		return nil, fmt.Errorf("invalid pointer %q", pointer)
+	}
+
+	tokens := strings.Split(pointer[1:], "/")
+	for i, tok := range tokens {
+		tok = strings.ReplaceAll(tok, "~1", "/")
+		tokens[i] = strings.ReplaceAll(tok, "~0", "~")
+	}
+
+	return tokens, nil
+}
+
+// arrayIndex converts the token into an index for an array of the specified
+// length. The end of the array is a valid index when appending.
+func arrayIndex(tok string, length int, appending bool) (int, error) {
+	if appending && tok == "-" {
+		return length, nil
+	}
+
+	if tok == "" || (len(tok) > 1 && tok[0] == '0') {
+		return 0, ErrInvalidIndex
+	}
+
+	i, err := strconv.Atoi(tok)
+	if err != nil || i < 0 {
+		return 0, ErrInvalidIndex
+	}
+
+	last := length - 1
+	if appending {
+		last = length
+	}
+
+	if i > last {
+		return 0, ErrInvalidIndex
+	}
+
+	return i, nil
+}
+
+// get returns the value referenced by the path.
+func get(node any, path []string) (any, error) {
+	for _, tok := range path {
+		switch n := node.(type) {
+		case map[string]any:
+			v, exists := n[tok]
+			if !exists {
+				return nil, ErrPathNotFound
+			}
+			node = v
+
+		case []any:
+			i, err := arrayIndex(tok, len(n), false)
+			if err != nil {
+				return nil, err
+			}
+			node = n[i]
+
+		default:
+			return nil, ErrPathNotFound
+		}
+	}
+
+	return node, nil
+}
+
+// add sets the value at the path, inserting into arrays, and returns the
+// updated node.
+func add(node any, path []string, v any) (any, error) {
+	if len(path) == 0 {
+		return v, nil
+	}
+
+	tok := path[0]
+
+	switch n := node.(type) {
+	case map[string]any:
+		if len(path) == 1 {
+			n[tok] = v
+			return n, nil
+		}
+
+		child, exists := n[tok]
+		if !exists {
+			return nil, ErrPathNotFound
+		}
+
+		child, err := add(child, path[1:], v)
+		if err != nil {
+			return nil, err
+		}
+		n[tok] = child
+
+		return n, nil
+
+	case []any:
+		if len(path) == 1 {
+			i, err := arrayIndex(tok, len(n), true)
+			if err != nil {
+				return nil, err
+			}
+			return slices.Insert(n, i, v), nil
+		}
+
+		i, err := arrayIndex(tok, len(n), false)
+		if err != nil {
+			return nil, err
+		}
+
+		child, err := add(n[i], path[1:], v)
+		if err != nil {
+			return nil, err
+		}
+		n[i] = child
+
+		return n, nil
+	}
+
+	return nil, ErrNotContainer
+}
+
+// remove deletes the value at the path and returns the updated node with
+// the value that was removed.
+func remove(node any, path []string) (any, any, error) {
+	if len(path) == 0 {
+		return nil, node, nil
+	}
+
+	tok := path[0]
+
+	switch n := node.(type) {
+	case map[string]any:
+		child, exists := n[tok]
+		if !exists {
+			return nil, nil, ErrPathNotFound
+		}
+
+		if len(path) == 1 {
+			delete(n, tok)
+			return n, child, nil
+		}
+
+		child, removed, err := remove(child, path[1:])
+		if err != nil {
+			return nil, nil, err
+		}
+		n[tok] = child
+
+		return n, removed, nil
+
+	case []any:
+		i, err := arrayIndex(tok, len(n), false)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		if len(path) == 1 {
+			removed := n[i]
+			return slices.Delete(n, i, i+1), removed, nil
+		}
+
+		child, removed, err := remove(n[i], path[1:])
+		if err != nil {
+			return nil, nil, err
+		}
+		n[i] = child
+
+		return n, removed, nil
+	}
+
+	return nil, nil, ErrPathNotFound
+}
+
+// deepCopy returns a copy of the value that shares no maps or slices with
+// the original.
+func deepCopy(v any) any {
+	switch v := v.(type) {
+	case map[string]any:
+		m := make(map[string]any, len(v))
+		for k, e := range v {
+			m[k] = deepCopy(e)
+		}
+		return m
+
+	case []any:
+		s := make([]any, len(v))
+		for i, e := range v {
+			s[i] = deepCopy(e)
+		}
+		return s
+	}
+
+	return v
+}
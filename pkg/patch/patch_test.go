@@ -0,0 +1,185 @@
+package patch_test
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/1core-dev/go-service/pkg/patch"
+)
+
+const doc = `{"name":"Bill","roles":["ADMIN","USER"],"a/b":1,"m~n":2,"address":{"city":"Miami","zip":"33101"}}`
+
+func Test_Patch(t *testing.T) {
+	t.Run("jsonpatch", jsonPatch)
+	t.Run("jsonpatchErrors", jsonPatchErrors)
+	t.Run("mergepatch", mergePatch)
+}
+
+// =============================================================================
+
+func jsonPatch(t *testing.T) {
+	tests := []struct {
+		name  string
+		patch string
+		exp   string
+	}{
+		{
+			name:  "add",
+			patch: `[{"op":"add","path":"/department","value":"IT"}]`,
+			exp:   `{"name":"Bill","roles":["ADMIN","USER"],"a/b":1,"m~n":2,"address":{"city":"Miami","zip":"33101"},"department":"IT"}`,
+		},
+		{
+			name:  "addIndex",
+			patch: `[{"op":"add","path":"/roles/1","value":"OWNER"}]`,
+			exp:   `{"name":"Bill","roles":["ADMIN","OWNER","USER"],"a/b":1,"m~n":2,"address":{"city":"Miami","zip":"33101"}}`,
+		},
+		{
+			name:  "addEnd",
+			patch: `[{"op":"add","path":"/roles/-","value":"OWNER"}]`,
+			exp:   `{"name":"Bill","roles":["ADMIN","USER","OWNER"],"a/b":1,"m~n":2,"address":{"city":"Miami","zip":"33101"}}`,
+		},
+		{
+			name:  "remove",
+			patch: `[{"op":"remove","path":"/address/zip"}]`,
+			exp:   `{"name":"Bill","roles":["ADMIN","USER"],"a/b":1,"m~n":2,"address":{"city":"Miami"}}`,
+		},
+		{
+			name:  "removeIndex",
+			patch: `[{"op":"remove","path":"/roles/0"}]`,
+			exp:   `{"name":"Bill","roles":["USER"],"a/b":1,"m~n":2,"address":{"city":"Miami","zip":"33101"}}`,
+		},
+		{
+			name:  "replace",
+			patch: `[{"op":"replace","path":"/name","value":"Ed"}]`,
+			exp:   `{"name":"Ed","roles":["ADMIN","USER"],"a/b":1,"m~n":2,"address":{"city":"Miami","zip":"33101"}}`,
+		},
+		{
+			name:  "move",
+			patch: `[{"op":"move","from":"/address/city","path":"/city"}]`,
+			exp:   `{"name":"Bill","roles":["ADMIN","USER"],"a/b":1,"m~n":2,"address":{"zip":"33101"},"city":"Miami"}`,
+		},
+		{
+			name:  "copy",
+			patch: `[{"op":"copy","from":"/address","path":"/billing"}]`,
+			exp:   `{"name":"Bill","roles":["ADMIN","USER"],"a/b":1,"m~n":2,"address":{"city":"Miami","zip":"33101"},"billing":{"city":"Miami","zip":"33101"}}`,
+		},
+		{
+			name:  "test",
+			patch: `[{"op":"test","path":"/roles","value":["ADMIN","USER"]},{"op":"replace","path":"/name","value":"Ed"}]`,
+			exp:   `{"name":"Ed","roles":["ADMIN","USER"],"a/b":1,"m~n":2,"address":{"city":"Miami","zip":"33101"}}`,
+		},
+		{
+			name:  "escapeSlash",
+			patch: `[{"op":"replace","path":"/a~1b","value":10}]`,
+			exp:   `{"name":"Bill","roles":["ADMIN","USER"],"a/b":10,"m~n":2,"address":{"city":"Miami","zip":"33101"}}`,
+		},
+		{
+			name:  "escapeTilde",
+			patch: `[{"op":"remove","path":"/m~0n"}]`,
+			exp:   `{"name":"Bill","roles":["ADMIN","USER"],"a/b":1,"address":{"city":"Miami","zip":"33101"}}`,
+		},
+	}
+
+	for _, tt := range tests {
+		got, err := patch.JSONPatch([]byte(doc), []byte(tt.patch))
+		if err != nil {
+			t.Errorf("%s: Should be able to apply the patch : %s.", tt.name, err)
+			continue
+		}
+
+		equalJSON(t, tt.name, got, tt.exp)
+	}
+}
+
+func jsonPatchErrors(t *testing.T) {
+	tests := []struct {
+		name  string
+		patch string
+		err   error
+	}{
+		{name: "testFailed", patch: `[{"op":"test","path":"/name","value":"Ed"}]`, err: patch.ErrTestFailed},
+		{name: "removeMissing", patch: `[{"op":"remove","path":"/department"}]`, err: patch.ErrPathNotFound},
+		{name: "indexOutOfRange", patch: `[{"op":"add","path":"/roles/3","value":"OWNER"}]`, err: patch.ErrInvalidIndex},
+		{name: "indexLeadingZero", patch: `[{"op":"remove","path":"/roles/01"}]`, err: patch.ErrInvalidIndex},
+		{name: "removeEnd", patch: `[{"op":"remove","path":"/roles/-"}]`, err: patch.ErrInvalidIndex},
+		{name: "missingValue", patch: `[{"op":"add","path":"/department"}]`, err: patch.ErrMissingValue},
+		{name: "missingFrom", patch: `[{"op":"copy","path":"/department"}]`, err: patch.ErrMissingFrom},
+		{name: "moveIntoChild", patch: `[{"op":"move","from":"/address","path":"/address/home"}]`, err: patch.ErrMoveIntoChild},
+		{name: "unknownOp", patch: `[{"op":"merge","path":"/name","value":"Ed"}]`, err: patch.ErrUnknownOp},
+	}
+
+	for _, tt := range tests {
+		// The errors are reported as field errors against the path, which
+		// only keep the message of the error.
+		_, err := patch.JSONPatch([]byte(doc), []byte(tt.patch))
+		if err == nil || !strings.Contains(err.Error(), tt.err.Error()) {
+			t.Errorf("%s: Should get the expected error : got %v, exp %v.", tt.name, err, tt.err)
+		}
+	}
+
+	// A failing operation must not leave the earlier ones applied.
+	if _, err := patch.JSONPatch([]byte(doc), []byte(`[{"op":"replace","path":"/name","value":"Ed"},{"op":"test","path":"/name","value":"Bill"}]`)); err == nil || !strings.Contains(err.Error(), patch.ErrTestFailed.Error()) {
+		t.Errorf("Should fail the whole patch when a test fails : %v.", err)
+	}
+}
+
+func mergePatch(t *testing.T) {
+	tests := []struct {
+		name  string
+		patch string
+		exp   string
+	}{
+		{
+			name:  "replace",
+			patch: `{"name":"Ed"}`,
+			exp:   `{"name":"Ed","roles":["ADMIN","USER"],"a/b":1,"m~n":2,"address":{"city":"Miami","zip":"33101"}}`,
+		},
+		{
+			name:  "delete",
+			patch: `{"m~n":null,"address":{"zip":null}}`,
+			exp:   `{"name":"Bill","roles":["ADMIN","USER"],"a/b":1,"address":{"city":"Miami"}}`,
+		},
+		{
+			name:  "arrayReplaced",
+			patch: `{"roles":["USER"]}`,
+			exp:   `{"name":"Bill","roles":["USER"],"a/b":1,"m~n":2,"address":{"city":"Miami","zip":"33101"}}`,
+		},
+		{
+			name:  "nested",
+			patch: `{"address":{"state":"FL"},"billing":{"city":"Tampa","zip":null}}`,
+			exp:   `{"name":"Bill","roles":["ADMIN","USER"],"a/b":1,"m~n":2,"address":{"city":"Miami","zip":"33101","state":"FL"},"billing":{"city":"Tampa"}}`,
+		},
+	}
+
+	for _, tt := range tests {
+		got, err := patch.MergePatch([]byte(doc), []byte(tt.patch))
+		if err != nil {
+			t.Errorf("%s: Should be able to apply the patch : %s.", tt.name, err)
+			continue
+		}
+
+		equalJSON(t, tt.name, got, tt.exp)
+	}
+}
+
+// =============================================================================
+
+func equalJSON(t *testing.T, name string, got []byte, exp string) {
+	t.Helper()
+
+	var g, e any
+	if err := json.Unmarshal(got, &g); err != nil {
+		t.Fatalf("%s: Should get a valid document : %s.", name, err)
+	}
+	if err := json.Unmarshal([]byte(exp), &e); err != nil {
+		t.Fatalf("%s: Should have a valid expected document : %s.", name, err)
+	}
+
+	if !reflect.DeepEqual(g, e) {
+		t.Errorf("%s: Should get the expected document.", name)
+		t.Errorf("GOT: %s", got)
+		t.Errorf("EXP: %s", exp)
+	}
+}
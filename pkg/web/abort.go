@@ -0,0 +1,33 @@
+package web
+
+import "errors"
+
+// abortError is a type used to abort a response that has already started.
+type abortError struct {
+	Err error
+}
+
+// NewAbortError returns an error that causes the framework to abort the
+// connection instead of completing the response. It's for errors found
+// after part of the response was sent, when writing an error response
+// would corrupt it.
+func NewAbortError(err error) error {
+	return &abortError{Err: err}
+}
+
+// Error is implementation of the error interface.
+func (ae *abortError) Error() string {
+	return ae.Err.Error()
+}
+
+// Unwrap returns the error that caused the abort.
+func (ae *abortError) Unwrap() error {
+	return ae.Err
+}
+
+// IsAbort checks to see if the abort error is contained in the specified
+// error value.
+func IsAbort(err error) bool {
+	var ae *abortError
+	return errors.As(err, &ae)
+}
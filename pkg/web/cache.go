@@ -0,0 +1,66 @@
+package web
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// CheckNotModified sets the ETag and Last-Modified headers for the resource
+// and reports whether the copy the client has cached is still current based
+// on the If-None-Match and If-Modified-Since headers. When it returns true
+// the handler should call RespondNotModified instead of sending the resource.
+// An empty etag or zero lastModified leaves that validator out.
+func CheckNotModified(w http.ResponseWriter, r *http.Request, etag string, lastModified time.Time) bool {
+	if etag != "" {
+		w.Header().Set("ETag", etag)
+	}
+
+	if !lastModified.IsZero() {
+		w.Header().Set("Last-Modified", lastModified.UTC().Format(http.TimeFormat))
+	}
+
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		return false
+	}
+
+	// If-None-Match takes precedence over If-Modified-Since when both are
+	// provided, as required by RFC 9110.
+	if inm := r.Header.Get("If-None-Match"); inm != "" {
+		return etag != "" && etagMatch(inm, etag)
+	}
+
+	if ims := r.Header.Get("If-Modified-Since"); ims != "" && !lastModified.IsZero() {
+		t, err := http.ParseTime(ims)
+		if err != nil {
+			return false
+		}
+
+		// The header only has a precision of seconds.
+		return !lastModified.Truncate(time.Second).After(t)
+	}
+
+	return false
+}
+
+// RespondNotModified sends a 304 without a body. The validators set by
+// CheckNotModified are kept in the response.
+func RespondNotModified(ctx context.Context, w http.ResponseWriter) error {
+	return Respond(ctx, w, nil, http.StatusNotModified)
+}
+
+// etagMatch reports whether the etag is in the If-None-Match header using
+// the weak comparison, which ignores the W/ prefix.
+func etagMatch(header string, etag string) bool {
+	etag = strings.TrimPrefix(etag, "W/")
+
+	for _, v := range strings.Split(header, ",") {
+		v = strings.TrimSpace(v)
+		if v == "*" || strings.TrimPrefix(v, "W/") == etag {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,132 @@
+package web
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func Test_Cache(t *testing.T) {
+	t.Run("etagMatch", etagMatches)
+	t.Run("notModified", notModified)
+	t.Run("respond", respondNotModified)
+}
+
+// =============================================================================
+
+func etagMatches(t *testing.T) {
+	tests := []struct {
+		name   string
+		header string
+		etag   string
+		exp    bool
+	}{
+		{name: "strong", header: `"3"`, etag: `"3"`, exp: true},
+		{name: "different", header: `"2"`, etag: `"3"`, exp: false},
+		{name: "list", header: `"1", "2" ,"3"`, etag: `"3"`, exp: true},
+		{name: "listMissing", header: `"1", "2"`, etag: `"3"`, exp: false},
+		{name: "weakHeader", header: `W/"3"`, etag: `"3"`, exp: true},
+		{name: "weakETag", header: `"3"`, etag: `W/"3"`, exp: true},
+		{name: "weakBoth", header: `W/"1", W/"3"`, etag: `W/"3"`, exp: true},
+		{name: "any", header: `*`, etag: `"3"`, exp: true},
+		{name: "unquoted", header: `3`, etag: `"3"`, exp: false},
+		{name: "prefix", header: `"31"`, etag: `"3"`, exp: false},
+	}
+
+	for _, tt := range tests {
+		if got := etagMatch(tt.header, tt.etag); got != tt.exp {
+			t.Errorf("%s: Should get the expected match : got %t, exp %t.", tt.name, got, tt.exp)
+		}
+	}
+}
+
+func notModified(t *testing.T) {
+	lastModified := time.Date(2024, 1, 2, 3, 4, 5, 600, time.UTC)
+
+	tests := []struct {
+		name         string
+		method       string
+		headers      map[string]string
+		etag         string
+		lastModified time.Time
+		exp          bool
+	}{
+		{name: "noValidators", method: http.MethodGet, etag: `"3"`, lastModified: lastModified, exp: false},
+		{name: "etagMatch", method: http.MethodGet, headers: map[string]string{"If-None-Match": `"3"`}, etag: `"3"`, lastModified: lastModified, exp: true},
+		{name: "etagWeak", method: http.MethodGet, headers: map[string]string{"If-None-Match": `W/"3"`}, etag: `"3"`, exp: true},
+		{name: "etagAny", method: http.MethodGet, headers: map[string]string{"If-None-Match": `*`}, etag: `"3"`, exp: true},
+		{name: "etagAnyNoETag", method: http.MethodGet, headers: map[string]string{"If-None-Match": `*`}, lastModified: lastModified, exp: false},
+		{name: "etagStale", method: http.MethodGet, headers: map[string]string{"If-None-Match": `"2"`}, etag: `"3"`, exp: false},
+		{name: "head", method: http.MethodHead, headers: map[string]string{"If-None-Match": `"3"`}, etag: `"3"`, exp: true},
+		{name: "put", method: http.MethodPut, headers: map[string]string{"If-None-Match": `"3"`}, etag: `"3"`, exp: false},
+		{name: "since", method: http.MethodGet, headers: map[string]string{"If-Modified-Since": lastModified.Format(http.TimeFormat)}, lastModified: lastModified, exp: true},
+		{name: "sinceLater", method: http.MethodGet, headers: map[string]string{"If-Modified-Since": lastModified.Add(time.Hour).Format(http.TimeFormat)}, lastModified: lastModified, exp: true},
+		{name: "sinceEarlier", method: http.MethodGet, headers: map[string]string{"If-Modified-Since": lastModified.Add(-time.Second).Format(http.TimeFormat)}, lastModified: lastModified, exp: false},
+		{name: "sinceInvalid", method: http.MethodGet, headers: map[string]string{"If-Modified-Since": "yesterday"}, lastModified: lastModified, exp: false},
+		{name: "sinceNoLastModified", method: http.MethodGet, headers: map[string]string{"If-Modified-Since": lastModified.Format(http.TimeFormat)}, etag: `"3"`, exp: false},
+		{
+			name:         "etagPrecedence",
+			method:       http.MethodGet,
+			headers:      map[string]string{"If-None-Match": `"2"`, "If-Modified-Since": lastModified.Format(http.TimeFormat)},
+			etag:         `"3"`,
+			lastModified: lastModified,
+			exp:          false,
+		},
+	}
+
+	for _, tt := range tests {
+		r := httptest.NewRequest(tt.method, "/v1/users/1", nil)
+		for k, v := range tt.headers {
+			r.Header.Set(k, v)
+		}
+		w := httptest.NewRecorder()
+
+		if got := CheckNotModified(w, r, tt.etag, tt.lastModified); got != tt.exp {
+			t.Errorf("%s: Should get the expected result : got %t, exp %t.", tt.name, got, tt.exp)
+		}
+
+		if got := w.Header().Get("ETag"); got != tt.etag {
+			t.Errorf("%s: Should set the ETag : got %q, exp %q.", tt.name, got, tt.etag)
+		}
+
+		exp := ""
+		if !tt.lastModified.IsZero() {
+			exp = "Tue, 02 Jan 2024 03:04:05 GMT"
+		}
+
+		if got := w.Header().Get("Last-Modified"); got != exp {
+			t.Errorf("%s: Should set the Last-Modified : got %q, exp %q.", tt.name, got, exp)
+		}
+	}
+}
+
+func respondNotModified(t *testing.T) {
+	v := Values{}
+	ctx := SetValues(context.Background(), &v)
+
+	r := httptest.NewRequest(http.MethodGet, "/v1/users/1", nil)
+	r.Header.Set("If-None-Match", `"3"`)
+	w := httptest.NewRecorder()
+
+	if !CheckNotModified(w, r, `"3"`, time.Time{}) {
+		t.Fatalf("Should match the cached copy.")
+	}
+
+	if err := RespondNotModified(ctx, w); err != nil {
+		t.Fatalf("Should be able to respond : %s.", err)
+	}
+
+	if w.Code != http.StatusNotModified || v.StatusCode != http.StatusNotModified {
+		t.Errorf("Should respond with a 304 : got %d, %d.", w.Code, v.StatusCode)
+	}
+
+	if w.Body.Len() != 0 || w.Header().Get("Content-Type") != "" {
+		t.Errorf("Should respond without a body : %q.", w.Body.String())
+	}
+
+	if w.Header().Get("ETag") != `"3"` {
+		t.Errorf("Should keep the ETag in the response.")
+	}
+}
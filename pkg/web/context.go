@@ -2,18 +2,35 @@ package web
 
 import (
 	"context"
+	"strings"
 	"time"
+
+	"go.opentelemetry.io/otel/trace"
 )
 
 type ctxKey int
 
 const key ctxKey = 1
 
-// Values represent state for each request.
+// zeroTraceID is the trace ID reported outside of a request. It has the
+// form of a W3C trace ID like every other trace ID.
+var zeroTraceID = strings.Repeat("0", 32)
+
+// Values represent state for each request. TraceID, ParentSpanID,
+// TraceFlags and TraceState hold the incoming W3C trace context and SpanID
+// identifies the work done for the request by this service. Tracer records
+// the spans added with AddSpan.
 type Values struct {
-	TraceID    string
-	Now        time.Time
-	StatusCode int
+	Tracer       trace.Tracer
+	TraceID      string
+	SpanID       string
+	ParentSpanID string
+	TraceFlags   string
+	TraceState   string
+	RequestID    string
+	Now          time.Time
+	StatusCode   int
+	Subject      string
 }
 
 // SetValues sets the specified Values in the context.
@@ -26,7 +43,7 @@ func GetValues(ctx context.Context) *Values {
 	v, ok := ctx.Value(key).(*Values)
 	if !ok {
 		return &Values{
-			TraceID: "00000000-0000-0000-0000-000000000000",
+			TraceID: zeroTraceID,
 			Now:     time.Now(),
 		}
 	}
@@ -37,7 +54,7 @@ func GetValues(ctx context.Context) *Values {
 func GetTraceID(ctx context.Context) string {
 	v, ok := ctx.Value(key).(*Values)
 	if !ok {
-		return "00000000-0000-0000-0000-000000000000"
+		return zeroTraceID
 	}
 	return v.TraceID
 }
@@ -53,9 +70,19 @@ func GetTime(ctx context.Context) time.Time {
 
 // SetStatusCode sets the status code back into the context.
 func SetStatusCode(ctx context.Context, statusCode int) {
-	v, ok := ctx.Value(statusCode).(*Values)
+	v, ok := ctx.Value(key).(*Values)
 	if !ok {
 		return
 	}
 	v.StatusCode = statusCode
 }
+
+// SetSubject sets the subject of the authenticated caller back into the
+// context so it is available to the outer middleware.
+func SetSubject(ctx context.Context, subject string) {
+	v, ok := ctx.Value(key).(*Values)
+	if !ok {
+		return
+	}
+	v.Subject = subject
+}
@@ -0,0 +1,148 @@
+package web
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"net/http"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	"go.opentelemetry.io/otel/trace/noop"
+)
+
+// AddSpan adds an OpenTelemetry span to the trace of the request. When no
+// tracer was provided to the App, a span that records nothing is returned.
+func AddSpan(ctx context.Context, spanName string, keyValues ...attribute.KeyValue) (context.Context, trace.Span) {
+	v, ok := ctx.Value(key).(*Values)
+	if !ok || v.Tracer == nil {
+		return ctx, noop.Span{}
+	}
+
+	ctx, span := v.Tracer.Start(ctx, spanName, trace.WithAttributes(keyValues...))
+
+	return ctx, span
+}
+
+// startSpan starts the span for the request as a child of the incoming
+// trace context. When the span is recorded, the values are updated with the
+// IDs of the span so logs and response headers match the exported trace.
+func (a *App) startSpan(ctx context.Context, v *Values, r *http.Request) (context.Context, trace.Span) {
+	if a.tracer == nil {
+		return ctx, noop.Span{}
+	}
+
+	if sc, ok := remoteSpanContext(v); ok {
+		ctx = trace.ContextWithRemoteSpanContext(ctx, sc)
+	}
+
+	ctx, span := a.tracer.Start(ctx, "pkg.web.handle",
+		trace.WithSpanKind(trace.SpanKindServer),
+		trace.WithAttributes(
+			attribute.String("http.request.method", r.Method),
+			attribute.String("url.path", r.URL.Path),
+		),
+	)
+
+	if span.IsRecording() {
+		sc := span.SpanContext()
+		v.TraceID = sc.TraceID().String()
+		v.SpanID = sc.SpanID().String()
+		v.TraceFlags = sc.TraceFlags().String()
+	}
+
+	return ctx, span
+}
+
+// endSpan records the outcome of the request on its span and ends it.
+func endSpan(span trace.Span, v *Values, err error) {
+	span.SetAttributes(attribute.Int("http.response.status_code", v.StatusCode))
+
+	switch {
+	case err != nil:
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	case v.StatusCode >= http.StatusInternalServerError:
+		span.SetStatus(codes.Error, http.StatusText(v.StatusCode))
+	}
+
+	span.End()
+}
+
+// remoteSpanContext returns the span context of the caller from the
+// traceparent and tracestate headers that were accepted.
+func remoteSpanContext(v *Values) (trace.SpanContext, bool) {
+	if v.ParentSpanID == "" {
+		return trace.SpanContext{}, false
+	}
+
+	traceID, err := trace.TraceIDFromHex(v.TraceID)
+	if err != nil {
+		return trace.SpanContext{}, false
+	}
+
+	spanID, err := trace.SpanIDFromHex(v.ParentSpanID)
+	if err != nil {
+		return trace.SpanContext{}, false
+	}
+
+	var flags trace.TraceFlags
+	if b, err := hex.DecodeString(v.TraceFlags); err == nil && len(b) == 1 {
+		flags = trace.TraceFlags(b[0])
+	}
+
+	// An invalid tracestate is dropped rather than failing the request.
+	state, _ := trace.ParseTraceState(v.TraceState)
+
+	sc := trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID:    traceID,
+		SpanID:     spanID,
+		TraceFlags: flags,
+		TraceState: state,
+		Remote:     true,
+	})
+
+	return sc, sc.IsValid()
+}
+
+// =============================================================================
+
+// IDGenerator generates the IDs of OpenTelemetry spans. The span of a
+// request is given the trace and span IDs already assigned to the request
+// so a trace started from an X-Request-ID keeps that ID. Every other span
+// gets a random ID. It implements the IDGenerator interface of the sdk.
+type IDGenerator struct{}
+
+// NewIDs returns the IDs for a span starting a new trace.
+func (IDGenerator) NewIDs(ctx context.Context) (trace.TraceID, trace.SpanID) {
+	if v, ok := ctx.Value(key).(*Values); ok && !trace.SpanContextFromContext(ctx).IsValid() {
+		traceID, err1 := trace.TraceIDFromHex(v.TraceID)
+		spanID, err2 := trace.SpanIDFromHex(v.SpanID)
+		if err1 == nil && err2 == nil {
+			return traceID, spanID
+		}
+	}
+
+	var traceID trace.TraceID
+	rand.Read(traceID[:])
+
+	return traceID, newSpanID()
+}
+
+// NewSpanID returns the ID for a span within an existing trace.
+func (IDGenerator) NewSpanID(ctx context.Context, traceID trace.TraceID) trace.SpanID {
+	if v, ok := ctx.Value(key).(*Values); ok && trace.SpanContextFromContext(ctx).IsRemote() {
+		if spanID, err := trace.SpanIDFromHex(v.SpanID); err == nil {
+			return spanID
+		}
+	}
+
+	return newSpanID()
+}
+
+func newSpanID() trace.SpanID {
+	var spanID trace.SpanID
+	rand.Read(spanID[:])
+	return spanID
+}
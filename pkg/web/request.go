@@ -18,6 +18,11 @@ func Param(r *http.Request, key string) string {
 	return m[key]
 }
 
+// Route returns the registered route pattern that matched the request.
+func Route(r *http.Request) string {
+	return httptreemux.ContextRoute(r.Context())
+}
+
 // Decode reads the body of an HTTP request looking for a JSON document. The
 // body is decoded into the provided value.
 // If the provided value is a struct then it is checked for validation tags.
@@ -0,0 +1,167 @@
+package web
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"net/http"
+	"strings"
+)
+
+// Set of headers used to propagate the trace context between services. The
+// traceparent and tracestate headers are defined by the W3C Trace Context
+// specification.
+const (
+	HeaderTraceParent = "traceparent"
+	HeaderTraceState  = "tracestate"
+	HeaderRequestID   = "X-Request-ID"
+)
+
+// Set of limits on the incoming headers that are kept. Longer values are
+// discarded rather than truncated.
+const (
+	maxTraceStateLen = 512
+	maxRequestIDLen  = 128
+)
+
+// setTrace sets the trace context of the request into the values. The trace
+// ID and parent span are taken from the traceparent header when it's valid.
+// Otherwise the X-Request-ID header is used as the trace ID if it has the
+// form of one, and a new trace is started if not. A new span ID is always
+// generated for the work done by this service.
+func setTrace(v *Values, r *http.Request) {
+	v.SpanID = newID(8)
+	v.TraceFlags = "01"
+
+	if traceID, parentID, flags, ok := parseTraceParent(r.Header.Get(HeaderTraceParent)); ok {
+		v.TraceID = traceID
+		v.ParentSpanID = parentID
+		v.TraceFlags = flags
+
+		if ts := r.Header.Get(HeaderTraceState); len(ts) <= maxTraceStateLen {
+			v.TraceState = ts
+		}
+	}
+
+	if id := r.Header.Get(HeaderRequestID); id != "" && len(id) <= maxRequestIDLen && isPrintable(id) {
+		v.RequestID = id
+
+		if v.TraceID == "" {
+			if traceID, ok := requestTraceID(id); ok {
+				v.TraceID = traceID
+			}
+		}
+	}
+
+	if v.TraceID == "" {
+		v.TraceID = newID(16)
+	}
+
+	if v.RequestID == "" {
+		v.RequestID = v.TraceID
+	}
+}
+
+// SetTraceHeaders sets the trace context headers for the values into the
+// header. It's used to echo the trace back to the caller and to propagate
+// it to outbound calls, where this service's span becomes the parent.
+func SetTraceHeaders(ctx context.Context, h http.Header) {
+	v, ok := ctx.Value(key).(*Values)
+	if !ok {
+		return
+	}
+
+	h.Set(HeaderTraceParent, v.TraceParent())
+	h.Set(HeaderRequestID, v.RequestID)
+
+	if v.TraceState != "" {
+		h.Set(HeaderTraceState, v.TraceState)
+	}
+}
+
+// TraceParent returns the traceparent header value identifying the span of
+// this service within the trace.
+func (v *Values) TraceParent() string {
+	return "00-" + v.TraceID + "-" + v.SpanID + "-" + v.TraceFlags
+}
+
+// =============================================================================
+
+// parseTraceParent parses the traceparent header. Versions above 00 are
+// accepted as long as they start with the fields of version 00.
+func parseTraceParent(s string) (traceID string, parentID string, flags string, ok bool) {
+	if len(s) < 55 || (len(s) > 55 && s[55] != '-') {
+		return "", "", "", false
+	}
+
+	parts := strings.SplitN(s[:55], "-", 4)
+	if len(parts) != 4 {
+		return "", "", "", false
+	}
+
+	version, traceID, parentID, flags := parts[0], parts[1], parts[2], parts[3]
+
+	switch {
+	case !isHex(version, 2) || version == "ff" || (version == "00" && len(s) != 55):
+		return "", "", "", false
+	case !isHex(traceID, 32) || isZero(traceID):
+		return "", "", "", false
+	case !isHex(parentID, 16) || isZero(parentID):
+		return "", "", "", false
+	case !isHex(flags, 2):
+		return "", "", "", false
+	}
+
+	return traceID, parentID, flags, true
+}
+
+// requestTraceID converts a request ID in the form of a UUID or of a trace
+// ID into a trace ID.
+func requestTraceID(id string) (string, bool) {
+	id = strings.ToLower(strings.ReplaceAll(id, "-", ""))
+	if !isHex(id, 32) || isZero(id) {
+		return "", false
+	}
+
+	return id, true
+}
+
+// newID returns a random ID of n bytes in lowercase hex.
+func newID(n int) string {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		panic(err)
+	}
+
+	return hex.EncodeToString(b)
+}
+
+// isHex reports whether the string is n lowercase hex characters.
+func isHex(s string, n int) bool {
+	if len(s) != n {
+		return false
+	}
+
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if (c < '0' || c > '9') && (c < 'a' || c > 'f') {
+			return false
+		}
+	}
+
+	return true
+}
+
+func isZero(s string) bool {
+	return strings.Trim(s, "0") == ""
+}
+
+func isPrintable(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] < 0x21 || s[i] > 0x7e {
+			return false
+		}
+	}
+
+	return true
+}
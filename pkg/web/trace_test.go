@@ -0,0 +1,201 @@
+package web
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+const (
+	traceID  = "4bf92f3577b34da6a3ce929d0e0e4736"
+	parentID = "00f067aa0ba902b7"
+)
+
+func Test_Trace(t *testing.T) {
+	t.Run("traceparent", traceParent)
+	t.Run("requestID", requestID)
+	t.Run("setTrace", setTraceValues)
+	t.Run("zero", zeroValues)
+}
+
+// =============================================================================
+
+func traceParent(t *testing.T) {
+	tests := []struct {
+		name   string
+		header string
+		ok     bool
+		flags  string
+	}{
+		{name: "valid", header: "00-" + traceID + "-" + parentID + "-01", ok: true, flags: "01"},
+		{name: "notSampled", header: "00-" + traceID + "-" + parentID + "-00", ok: true, flags: "00"},
+		{name: "futureVersion", header: "cc-" + traceID + "-" + parentID + "-01", ok: true, flags: "01"},
+		{name: "futureVersionFields", header: "cc-" + traceID + "-" + parentID + "-01-what-the-future-holds", ok: true, flags: "01"},
+		{name: "empty", header: ""},
+		{name: "invalidVersion", header: "ff-" + traceID + "-" + parentID + "-01"},
+		{name: "versionTrailing", header: "00-" + traceID + "-" + parentID + "-01-extra"},
+		{name: "futureVersionNoDash", header: "cc-" + traceID + "-" + parentID + "-01x"},
+		{name: "zeroTraceID", header: "00-" + strings.Repeat("0", 32) + "-" + parentID + "-01"},
+		{name: "zeroParentID", header: "00-" + traceID + "-" + strings.Repeat("0", 16) + "-01"},
+		{name: "uppercaseTraceID", header: "00-" + strings.ToUpper(traceID) + "-" + parentID + "-01"},
+		{name: "uppercaseParentID", header: "00-" + traceID + "-" + strings.ToUpper(parentID) + "-01"},
+		{name: "shortTraceID", header: "00-" + traceID[:31] + "-" + parentID + "-01"},
+		{name: "badSeparator", header: "00_" + traceID + "-" + parentID + "-01"},
+		{name: "badFlags", header: "00-" + traceID + "-" + parentID + "-0g"},
+		{name: "oversized", header: "00-" + traceID + "-" + parentID + "-01" + strings.Repeat("0", 1024)},
+	}
+
+	for _, tt := range tests {
+		gotTrace, gotParent, gotFlags, ok := parseTraceParent(tt.header)
+
+		if ok != tt.ok {
+			t.Errorf("%s: Should get the expected result : got %t, exp %t.", tt.name, ok, tt.ok)
+			continue
+		}
+
+		if !ok {
+			continue
+		}
+
+		if gotTrace != traceID || gotParent != parentID || gotFlags != tt.flags {
+			t.Errorf("%s: Should get the fields of the header : got %s %s %s.", tt.name, gotTrace, gotParent, gotFlags)
+		}
+	}
+}
+
+func requestID(t *testing.T) {
+	tests := []struct {
+		name string
+		id   string
+		exp  string
+	}{
+		{name: "traceID", id: traceID, exp: traceID},
+		{name: "uuid", id: "4bf92f35-77b3-4da6-a3ce-929d0e0e4736", exp: traceID},
+		{name: "uppercase", id: "4BF92F35-77B3-4DA6-A3CE-929D0E0E4736", exp: traceID},
+		{name: "zero", id: "00000000-0000-0000-0000-000000000000"},
+		{name: "short", id: "abc-123"},
+		{name: "notHex", id: "4bf92f3577b34da6a3ce929d0e0e473z"},
+		{name: "oversized", id: traceID + traceID},
+	}
+
+	for _, tt := range tests {
+		got, ok := requestTraceID(tt.id)
+
+		if ok != (tt.exp != "") || got != tt.exp {
+			t.Errorf("%s: Should get the expected trace ID : got %q %t, exp %q.", tt.name, got, ok, tt.exp)
+		}
+	}
+}
+
+func setTraceValues(t *testing.T) {
+	tests := []struct {
+		name       string
+		headers    map[string]string
+		traceID    string
+		parentID   string
+		requestID  string
+		traceState string
+	}{
+		{
+			name:       "traceparent",
+			headers:    map[string]string{HeaderTraceParent: "00-" + traceID + "-" + parentID + "-01", HeaderTraceState: "vendor=value", HeaderRequestID: "abc-123"},
+			traceID:    traceID,
+			parentID:   parentID,
+			requestID:  "abc-123",
+			traceState: "vendor=value",
+		},
+		{
+			name:      "requestIDFallback",
+			headers:   map[string]string{HeaderRequestID: "4BF92F35-77B3-4DA6-A3CE-929D0E0E4736"},
+			traceID:   traceID,
+			requestID: "4BF92F35-77B3-4DA6-A3CE-929D0E0E4736",
+		},
+		{
+			name:      "traceparentWins",
+			headers:   map[string]string{HeaderTraceParent: "00-" + traceID + "-" + parentID + "-01", HeaderRequestID: "11111111-2222-3333-4444-555555555555"},
+			traceID:   traceID,
+			parentID:  parentID,
+			requestID: "11111111-2222-3333-4444-555555555555",
+		},
+		{
+			name:      "invalidTraceparentFallback",
+			headers:   map[string]string{HeaderTraceParent: "00-" + strings.Repeat("0", 32) + "-" + parentID + "-01", HeaderRequestID: traceID},
+			traceID:   traceID,
+			requestID: traceID,
+		},
+		{
+			name:     "oversizedTraceState",
+			headers:  map[string]string{HeaderTraceParent: "00-" + traceID + "-" + parentID + "-01", HeaderTraceState: strings.Repeat("a", maxTraceStateLen+1)},
+			traceID:  traceID,
+			parentID: parentID,
+		},
+		{
+			name:    "oversizedRequestID",
+			headers: map[string]string{HeaderRequestID: strings.Repeat("a", maxRequestIDLen+1)},
+		},
+		{
+			name:    "unprintableRequestID",
+			headers: map[string]string{HeaderRequestID: "abc 123"},
+		},
+		{
+			name: "none",
+		},
+	}
+
+	for _, tt := range tests {
+		r := httptest.NewRequest(http.MethodGet, "/", nil)
+		for k, v := range tt.headers {
+			r.Header.Set(k, v)
+		}
+
+		var v Values
+		setTrace(&v, r)
+
+		if !isHex(v.SpanID, 16) || isZero(v.SpanID) {
+			t.Errorf("%s: Should get a new span ID : %q.", tt.name, v.SpanID)
+		}
+
+		if tt.traceID != "" && v.TraceID != tt.traceID {
+			t.Errorf("%s: Should get the trace ID : got %q, exp %q.", tt.name, v.TraceID, tt.traceID)
+		}
+
+		if tt.traceID == "" && (!isHex(v.TraceID, 32) || isZero(v.TraceID)) {
+			t.Errorf("%s: Should start a new trace : %q.", tt.name, v.TraceID)
+		}
+
+		if v.ParentSpanID != tt.parentID || v.TraceState != tt.traceState {
+			t.Errorf("%s: Should get the parent and state : got %q %q.", tt.name, v.ParentSpanID, v.TraceState)
+		}
+
+		expRequestID := tt.requestID
+		if expRequestID == "" {
+			expRequestID = v.TraceID
+		}
+
+		if v.RequestID != expRequestID {
+			t.Errorf("%s: Should get the request ID : got %q, exp %q.", tt.name, v.RequestID, expRequestID)
+		}
+
+		if exp := "00-" + v.TraceID + "-" + v.SpanID + "-01"; v.TraceParent() != exp {
+			t.Errorf("%s: Should get the traceparent of this service : got %q, exp %q.", tt.name, v.TraceParent(), exp)
+		}
+	}
+}
+
+func zeroValues(t *testing.T) {
+	ctx := context.Background()
+
+	if id := GetTraceID(ctx); id != strings.Repeat("0", 32) {
+		t.Errorf("Should get a zero trace ID in the form of a trace ID : %q.", id)
+	}
+
+	if id := GetValues(ctx).TraceID; id != strings.Repeat("0", 32) {
+		t.Errorf("Should get a zero trace ID in the values : %q.", id)
+	}
+
+	if _, ok := requestTraceID(GetTraceID(ctx)); ok {
+		t.Errorf("Should never accept the zero trace ID from a caller.")
+	}
+}
@@ -9,7 +9,7 @@ import (
 	"time"
 
 	"github.com/dimfeld/httptreemux/v5"
-	"github.com/google/uuid"
+	"go.opentelemetry.io/otel/trace"
 )
 
 // App is the entrypoint into our application and what configures our context
@@ -17,6 +17,7 @@ import (
 type App struct {
 	*httptreemux.ContextMux
 	shutdown chan os.Signal
+	tracer   trace.Tracer
 	mw       []Middleware
 }
 
@@ -24,10 +25,12 @@ type App struct {
 type Handler func(ctx context.Context, w http.ResponseWriter, r *http.Request) error
 
 // NewApp created an App value that handle as et og routes for the application.
-func NewApp(shutdown chan os.Signal, mw ...Middleware) *App {
+// The tracer is used to record a span for every request and may be nil.
+func NewApp(shutdown chan os.Signal, tracer trace.Tracer, mw ...Middleware) *App {
 	return &App{
 		ContextMux: httptreemux.NewContextMux(),
 		shutdown:   shutdown,
+		tracer:     tracer,
 		mw:         mw,
 	}
 }
@@ -58,11 +61,26 @@ func (a *App) Handle(method string, group string, path string, handler Handler,
 func (a *App) handle(method string, group string, path string, handler Handler) {
 	h := func(w http.ResponseWriter, r *http.Request) {
 		v := Values{
-			TraceID: uuid.NewString(),
-			Now:     time.Now().UTC(),
+			Tracer: a.tracer,
+			Now:    time.Now().UTC(),
 		}
+		setTrace(&v, r)
+
 		ctx := SetValues(r.Context(), &v)
-		if err := handler(ctx, w, r); err != nil {
+
+		ctx, span := a.startSpan(ctx, &v, r)
+		SetTraceHeaders(ctx, w.Header())
+
+		err := handler(ctx, w, r)
+		endSpan(span, &v, err)
+
+		if err != nil {
+			// The server closes the connection without logging anything,
+			// so the client can tell the response is incomplete.
+			if IsAbort(err) {
+				panic(http.ErrAbortHandler)
+			}
+
 			if validateShutdown(err) {
 				a.SignalShutdown()
 				return
@@ -0,0 +1,186 @@
+package xlsx_test
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/1core-dev/go-service/pkg/xlsx"
+)
+
+func Test_XLSX(t *testing.T) {
+	t.Run("parts", parts)
+	t.Run("rows", rows)
+	t.Run("closed", closed)
+}
+
+// =============================================================================
+
+// sheet is the subset of the worksheet part the tests read back.
+type sheet struct {
+	Rows []struct {
+		Cells []struct {
+			Type string `xml:"t,attr"`
+			Text string `xml:"is>t"`
+		} `xml:"c"`
+	} `xml:"sheetData>row"`
+}
+
+func write(t *testing.T, sheetName string, records [][]string) *zip.Reader {
+	t.Helper()
+
+	var buf bytes.Buffer
+
+	w, err := xlsx.NewWriter(&buf, sheetName)
+	if err != nil {
+		t.Fatalf("Should be able to construct the writer : %s.", err)
+	}
+
+	for _, record := range records {
+		if err := w.Write(record); err != nil {
+			t.Fatalf("Should be able to write a row : %s.", err)
+		}
+	}
+
+	if err := w.Close(); err != nil {
+		t.Fatalf("Should be able to close the writer : %s.", err)
+	}
+
+	zr, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatalf("Should get a valid zip archive : %s.", err)
+	}
+
+	return zr
+}
+
+func readPart(t *testing.T, zr *zip.Reader, name string) []byte {
+	t.Helper()
+
+	f, err := zr.Open(name)
+	if err != nil {
+		t.Fatalf("Should find the %s part : %s.", name, err)
+	}
+	defer f.Close()
+
+	data, err := io.ReadAll(f)
+	if err != nil {
+		t.Fatalf("Should be able to read the %s part : %s.", name, err)
+	}
+
+	return data
+}
+
+// =============================================================================
+
+func parts(t *testing.T) {
+	zr := write(t, `Users & "Admins"`, nil)
+
+	exp := []string{
+		"[Content_Types].xml",
+		"_rels/.rels",
+		"xl/workbook.xml",
+		"xl/_rels/workbook.xml.rels",
+		"xl/worksheets/sheet1.xml",
+	}
+
+	var got []string
+	for _, f := range zr.File {
+		got = append(got, f.Name)
+	}
+
+	if !reflect.DeepEqual(got, exp) {
+		t.Fatalf("Should get the parts of the document : got %v, exp %v.", got, exp)
+	}
+
+	for _, name := range exp {
+		if err := xml.Unmarshal(readPart(t, zr, name), new(any)); err != nil {
+			t.Errorf("Should get well formed xml for %s : %s.", name, err)
+		}
+	}
+
+	var wb struct {
+		Sheets []struct {
+			Name string `xml:"name,attr"`
+		} `xml:"sheets>sheet"`
+	}
+	if err := xml.Unmarshal(readPart(t, zr, "xl/workbook.xml"), &wb); err != nil {
+		t.Fatalf("Should be able to unmarshal the workbook : %s.", err)
+	}
+
+	if len(wb.Sheets) != 1 || wb.Sheets[0].Name != `Users & "Admins"` {
+		t.Errorf("Should get the escaped sheet name : %+v.", wb.Sheets)
+	}
+}
+
+func rows(t *testing.T) {
+	records := [][]string{
+		{"id", "name", "email"},
+		{"1", "Bill <Kennedy>", "bill@example.com"},
+		{"2", "  Ed & Co  ", "=HYPERLINK(\"x\")"},
+		{},
+	}
+
+	zr := write(t, "Users", records)
+
+	var sh sheet
+	if err := xml.Unmarshal(readPart(t, zr, "xl/worksheets/sheet1.xml"), &sh); err != nil {
+		t.Fatalf("Should be able to unmarshal the sheet : %s.", err)
+	}
+
+	if len(sh.Rows) != len(records) {
+		t.Fatalf("Should get a row for every record : got %d, exp %d.", len(sh.Rows), len(records))
+	}
+
+	for i, record := range records {
+		cells := sh.Rows[i].Cells
+		if len(cells) != len(record) {
+			t.Errorf("Should get a cell for every value of row %d : got %d, exp %d.", i, len(cells), len(record))
+			continue
+		}
+
+		for j, value := range record {
+			if cells[j].Type != "inlineStr" {
+				t.Errorf("Should get a text cell at %d,%d : got %q.", i, j, cells[j].Type)
+			}
+
+			if cells[j].Text != value {
+				t.Errorf("Should get the value back at %d,%d : got %q, exp %q.", i, j, cells[j].Text, value)
+			}
+		}
+	}
+}
+
+func closed(t *testing.T) {
+	var buf bytes.Buffer
+
+	w, err := xlsx.NewWriter(&buf, "Users")
+	if err != nil {
+		t.Fatalf("Should be able to construct the writer : %s.", err)
+	}
+
+	if err := w.Close(); err != nil {
+		t.Fatalf("Should be able to close the writer : %s.", err)
+	}
+
+	if err := w.Write([]string{"1"}); !errors.Is(err, xlsx.ErrClosed) {
+		t.Errorf("Should not be able to write after close : %v.", err)
+	}
+
+	if err := w.Flush(); !errors.Is(err, xlsx.ErrClosed) {
+		t.Errorf("Should not be able to flush after close : %v.", err)
+	}
+
+	if err := w.Close(); !errors.Is(err, xlsx.ErrClosed) {
+		t.Errorf("Should not be able to close twice : %v.", err)
+	}
+
+	if !strings.HasPrefix(buf.String(), "PK") {
+		t.Errorf("Should get a zip archive.")
+	}
+}